@@ -18,30 +18,30 @@ type EVN struct {
 type PID struct {
 	SetId                  SI
 	ExternalPatientId      CX
-	InternalPatientId      CX  `hl7:"opt=R,rep=Y"`
-	AlternatePatientId     CX  `hl7:"rep=Y"`
-	PatientName            XPN `hl7:"opt=R,rep=Y"`
+	InternalPatientId      []CX  `hl7:"opt=R,rep=Y"`
+	AlternatePatientId     []CX  `hl7:"rep=Y"`
+	PatientName            []XPN `hl7:"opt=R,rep=Y"`
 	MotherMaidenName       XPN
 	DOB                    TS
-	Sex                    IS
-	PatientAlias           XPN `hl7:"rep=Y"`
+	Sex                    IS    `hl7:"tbl=0001"`
+	PatientAlias           []XPN `hl7:"rep=Y"`
 	Race                   IS
-	PatientAddress         XAD `hl7:"rep=Y"`
-	CountyCode             IS  `hl7:"opt=B"`
-	HomePhoneNumber        XTN `hl7:"rep=Y"`
-	WorkPhoneNumber        XTN `hl7:"rep=Y"`
+	PatientAddress         []XAD `hl7:"rep=Y"`
+	CountyCode             IS    `hl7:"opt=B"`
+	HomePhoneNumber        []XTN `hl7:"rep=Y"`
+	WorkPhoneNumber        []XTN `hl7:"rep=Y"`
 	PrimaryLanguage        CE
 	MaritalStatus          IS
 	Religion               IS
 	PatientAccountNumber   CX
 	SSN                    ST
 	DriversLicenseNumber   DLN
-	MotherIdentifier       CX `hl7:"rep=Y"`
+	MotherIdentifier       []CX `hl7:"rep=Y"`
 	EthnicGroup            IS
 	BirthPlace             ST
 	MultipleBirthIndicator ID
 	BirthOrder             NM
-	Citizenship            IS `hl7:"rep=Y"`
+	Citizenship            []IS `hl7:"rep=Y"`
 	VeteranStatus          CE
 	Nationality            CE
 	PatientDeathDateTime   TS
@@ -51,32 +51,32 @@ type PID struct {
 // The standard PV1 segment
 type PV1 struct {
 	SetId                   SI
-	PatientClass            IS `hl7:"opt=R"`
+	PatientClass            IS `hl7:"opt=R,tbl=0004"`
 	AssignedPatientLocation PL
 	AdmissionType           IS
 	PreadmitNumber          CX
 	PriorPatientLocation    PL
-	AttendingDoctor         XCN `hl7:"rep=Y"`
-	ReferringDoctor         XCN `hl7:"rep=Y"`
-	ConsultingDoctor        XCN `hl7:"rep=Y"`
+	AttendingDoctor         []XCN `hl7:"rep=Y"`
+	ReferringDoctor         []XCN `hl7:"rep=Y"`
+	ConsultingDoctor        []XCN `hl7:"rep=Y"`
 	HospitalService         IS
 	TemporaryLocation       PL
 	PreadmitTestIndicator   IS
 	ReadmissionIndicator    IS
-	AdmitSource             IS
-	AmbulatoryStatus        IS `hl7:"rep=Y"`
+	AdmitSource             IS   `hl7:"tbl=0023"`
+	AmbulatoryStatus        []IS `hl7:"rep=Y"`
 	VipIndicator            IS
-	AdmittingDoctor         XCN `hl7:"rep=Y"`
+	AdmittingDoctor         []XCN `hl7:"rep=Y"`
 	PatientType             IS
 	VisitNumber             CX
-	FinancialClass          FC `hl7:"rep=Y"`
+	FinancialClass          []FC `hl7:"rep=Y"`
 	ChargePriceIndicator    IS
 	CourtesyCode            IS
 	CreditRating            IS
-	ContractCode            IS `hl7:"rep=Y"`
-	ContractEffectiveDate   DT `hl7:"rep=Y"`
-	ContractAmount          NM `hl7:"rep=Y"`
-	ContractPeriod          NM `hl7:"rep=Y"`
+	ContractCode            []IS `hl7:"rep=Y"`
+	ContractEffectiveDate   []DT `hl7:"rep=Y"`
+	ContractAmount          []NM `hl7:"rep=Y"`
+	ContractPeriod          []NM `hl7:"rep=Y"`
 	InterestCode            IS
 	TransferBadDebtCode     IS
 	TransferBadDebtDate     DT
@@ -101,7 +101,7 @@ type PV1 struct {
 	TotalPayments           NM
 	AlternateVisitId        CX
 	VisitIndicator          IS
-	OtherHealthcareProvider XCN `hl7:"rep=Y"`
+	OtherHealthcareProvider []XCN `hl7:"rep=Y"`
 }
 
 // The standard PV2 segment
@@ -110,7 +110,7 @@ type PV2 struct {
 	AccomodationCode                  CE
 	AdmitReason                       CE
 	TransferReason                    CE
-	PatientValuables                  ST `hl7:"rep=Y"`
+	PatientValuables                  []ST `hl7:"rep=Y"`
 	PatientValuablesLocation          ST
 	VisitUserCode                     IS
 	ExpectedAdmitDateTime             TS
@@ -128,7 +128,7 @@ type PV2 struct {
 	ExpectedCountInsurancePlans       NM
 	VisitPublicityCode                IS
 	VisitProtectionIndicator          ID
-	ClinicOrganizationName            XON `hl7:"rep=Y"`
+	ClinicOrganizationName            []XON `hl7:"rep=Y"`
 	PatientStatusCode                 IS
 	VisitPriorityCode                 IS
 	PreviousTreatmentDAte             DT
@@ -147,25 +147,25 @@ type PV2 struct {
 
 // The standard NK1 segment
 type NK1 struct {
-	SetId                   SI  `hl7:"opt=R"`
-	Name                    XPN `hl7:"rep=Y"`
+	SetId                   SI    `hl7:"opt=R"`
+	Name                    []XPN `hl7:"rep=Y"`
 	Relationship            CE
-	Address                 XAD `hl7:"rep=Y"`
-	PhoneNumber             XTN `hl7:"rep=Y"`
-	WorkPhoneNumber         XTN `hl7:"rep=Y"`
+	Address                 []XAD `hl7:"rep=Y"`
+	PhoneNumber             []XTN `hl7:"rep=Y"`
+	WorkPhoneNumber         []XTN `hl7:"rep=Y"`
 	ContactRole             CE
 	StartDate               DT
 	EndDate                 DT
 	NextOfKinJobTitle       ST
 	NextOfKinJobCode        JCC
 	NextOfKinEmployeeNumber CX
-	OrganizationName        XON `hl7:"rep=Y"`
+	OrganizationName        []XON `hl7:"rep=Y"`
 	MaritalStatus           IS
 	Sex                     IS
 	DOB                     TS
-	LivingDependency        IS `hl7:"rep=Y"`
-	AmbulatoryStatus        IS `hl7:"rep=Y"`
-	Citizenship             IS `hl7:"rep=Y"`
+	LivingDependency        []IS `hl7:"rep=Y"`
+	AmbulatoryStatus        []IS `hl7:"rep=Y"`
+	Citizenship             []IS `hl7:"rep=Y"`
 	PrimaryLanguage         CE
 	LivingArrangement       IS
 	PublicityIndicator      CE
@@ -175,11 +175,11 @@ type NK1 struct {
 	MotherMaidenName        XPN
 	Nationality             CE
 	EthnicGroup             IS
-	ContactReason           CE  `hl7:"rep=Y"`
-	ContactName             XPN `hl7:"rep=Y"`
-	ContactTelephoneNumber  XTN `hl7:"rep=Y"`
-	ContactAddress          XAD `hl7:"rep=Y"`
-	NextOfKinIdentifiers    CX  `hl7:"rep=Y"`
+	ContactReason           []CE  `hl7:"rep=Y"`
+	ContactName             []XPN `hl7:"rep=Y"`
+	ContactTelephoneNumber  []XTN `hl7:"rep=Y"`
+	ContactAddress          []XAD `hl7:"rep=Y"`
+	NextOfKinIdentifiers    []CX  `hl7:"rep=Y"`
 	JobStatus               IS
 	Race                    IS
 	Handicap                IS
@@ -189,9 +189,9 @@ type NK1 struct {
 // The standard AL1 segment
 type AL1 struct {
 	SetId              SI `hl7:"opt=R"`
-	AllergyType        IS
+	AllergyType        IS `hl7:"tbl=0127"`
 	AllergyCode        CE `hl7:"opt=R"`
-	AllergySeverity    IS
+	AllergySeverity    IS `hl7:"tbl=0128"`
 	AllergyReaction    ST
 	IdentificationDate DT
 }
@@ -204,8 +204,8 @@ type NPU struct {
 
 // The standard MRG segment
 type MRG struct {
-	PriorInternalPatientId    CX `hl7:"opt=R,rep=Y"`
-	PriorAlternatePatientId   CX `hl7:"rep=Y"`
+	PriorInternalPatientId    []CX `hl7:"opt=R,rep=Y"`
+	PriorAlternatePatientId   []CX `hl7:"rep=Y"`
 	PriorPatientAccountNumber CX
 	PriorExternalPatientId    CX
 	PriorVisitNumber          CX
@@ -213,18 +213,30 @@ type MRG struct {
 	PriorPatientName          XPN
 }
 
+// The standard ROL segment
+type ROL struct {
+	RoleInstanceId   EI    `hl7:"opt=R"`
+	ActionCode       ID    `hl7:"opt=R,tbl=0287"`
+	Role             CE    `hl7:"opt=R"`
+	RolePerson       []XCN `hl7:"opt=R,rep=Y"`
+	RoleBeginDate    TS
+	RoleEndDate      TS
+	RoleDuration     CE
+	RoleActionReason CE
+}
+
 // The standard PD1 segment
 type PD1 struct {
-	LivingDependency       IS `hl7:"rep=Y"`
+	LivingDependency       []IS `hl7:"rep=Y"`
 	LivingArrangement      IS
-	PatientPrimaryFacility XON `hl7:"rep=Y"`
-	PatientPCPName         XCN `hl7:"rep=Y"`
+	PatientPrimaryFacility []XON `hl7:"rep=Y"`
+	PatientPCPName         []XCN `hl7:"rep=Y"`
 	StudentIndicator       IS
 	Handicap               IS
 	LivingWill             IS
 	OrganDonor             IS
 	SeparateBill           ID
-	DuplicatePatient       CX `hl7:"rep=Y"`
+	DuplicatePatient       []CX `hl7:"rep=Y"`
 	PublicityIndicator     CE
 	ProtectionIndicator    ID
 }
@@ -233,7 +245,7 @@ type PD1 struct {
 type DB1 struct {
 	SetId            SI `hl7:"opt=R"`
 	PersonCode       IS
-	PersonIdentifier CX `hl7:"rep=Y"`
+	PersonIdentifier []CX `hl7:"rep=Y"`
 	Indicator        ID
 	StartDate        DT
 	EndDate          DT
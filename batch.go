@@ -0,0 +1,251 @@
+package faraday
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+/*
+Batches wrap any number of messages between a BHS header and a BTS trailer,
+and files wrap any number of batches between an FHS header and an FTS
+trailer:
+
+	FHS			file header (optional)
+	  BHS		batch header (optional)
+	    MSH...	messages
+	  BTS		batch trailer (optional)
+	FTS			file trailer (optional)
+
+The Decoder reads through these transparently, so a batch file decodes like
+any other stream of messages. The headers and trailers it went past are
+available from FileHeader, BatchHeader, BatchTrailer and FileTrailer, and the
+message and batch counts in the trailers are checked against what was read.
+*/
+
+// batchState tracks the batch and file a Decoder is reading.
+type batchState struct {
+	fileHeader   *FHS
+	fileTrailer  *FTS
+	batchHeader  *BHS
+	batchTrailer *BTS
+	batches      int   // batches read in the current file
+	messages     int   // messages read in the current batch
+	err          error // count mismatch found by More, for Decode to return
+}
+
+func isEnvelopeSegment(seg []byte) bool {
+	switch string(seg[:3]) {
+	case "FHS", "BHS", "BTS", "FTS":
+		return true
+	}
+	return false
+}
+
+// FileHeader returns the FHS of the file being read, or nil if there is none.
+func (dec *Decoder) FileHeader() *FHS {
+	return dec.batch.fileHeader
+}
+
+// BatchHeader returns the BHS of the batch the last decoded message belongs
+// to, or nil if there is none.
+func (dec *Decoder) BatchHeader() *BHS {
+	return dec.batch.batchHeader
+}
+
+// BatchTrailer returns the BTS of the last batch read to its end, or nil.
+func (dec *Decoder) BatchTrailer() *BTS {
+	return dec.batch.batchTrailer
+}
+
+// FileTrailer returns the FTS of the file read to its end, or nil.
+func (dec *Decoder) FileTrailer() *FTS {
+	return dec.batch.fileTrailer
+}
+
+// decodeEnvelope decodes a batch or file header or trailer, checking the
+// message count of a BTS and the batch count of an FTS.
+func (dec *Decoder) decodeEnvelope(seg []byte) error {
+	b := &dec.batch
+	switch name := string(seg[:3]); name {
+	case "FHS":
+		var fhs FHS
+		if err := fhs.decodeHeader(seg[3:], dec.keepEscapes); err != nil {
+			return fmt.Errorf("decode FHS: %w", err)
+		}
+		dec.delims = newDelimiters(seg[3], encodingCharacters(seg[3:]))
+		*b = batchState{fileHeader: &fhs}
+	case "BHS":
+		var bhs BHS
+		if err := bhs.decodeHeader(seg[3:], dec.keepEscapes); err != nil {
+			return fmt.Errorf("decode BHS: %w", err)
+		}
+		dec.delims = newDelimiters(seg[3], encodingCharacters(seg[3:]))
+		b.batchHeader, b.batchTrailer = &bhs, nil
+		b.batches++
+		b.messages = 0
+	case "BTS":
+		var bts BTS
+		if err := dec.decodeTrailer(&bts, seg); err != nil {
+			return err
+		}
+		b.batchTrailer = &bts
+		if b.batchHeader == nil {
+			// a lone trailer still closes the one batch of the stream
+			b.batches++
+		}
+		if err := checkCount("BTS-1", string(bts.BatchMessageCount), b.messages, "messages"); err != nil {
+			return err
+		}
+	case "FTS":
+		var fts FTS
+		if err := dec.decodeTrailer(&fts, seg); err != nil {
+			return err
+		}
+		b.fileTrailer = &fts
+		if err := checkCount("FTS-1", string(fts.FileBatchCount), b.batches, "batches"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dec *Decoder) decodeTrailer(val any, seg []byte) error {
+	delims := dec.delims
+	if delims.field == 0 {
+		delims = newDelimiters(defaultFieldSeparator[0], []byte(defaultEncodingCharacters))
+	}
+	if err := decodeSegmentInto(reflect.ValueOf(val).Elem(), segmentFieldsData(seg), delims, dec.keepEscapes); err != nil {
+		return fmt.Errorf("decode %s: %w", seg[:3], err)
+	}
+	return nil
+}
+
+func checkCount(field, count string, got int, what string) error {
+	if count == "" {
+		return nil
+	}
+	want, err := strconv.Atoi(count)
+	if err != nil {
+		return fmt.Errorf("%s: invalid count '%s'", field, count)
+	}
+	if want != got {
+		return fmt.Errorf("%s: expected %d %s, read %d", field, want, what, got)
+	}
+	return nil
+}
+
+// BeginFile writes the header of a batch file. Delimiters which are not set
+// in fhs default to the HL7 ones.
+func (enc *Encoder) BeginFile(fhs FHS) error {
+	if enc.batch.inFile {
+		return fmt.Errorf("BeginFile: file already begun")
+	}
+	enc.batch = encoderBatch{}
+	if err := enc.encodeHeader(reflect.ValueOf(fhs), "FHS"); err != nil {
+		return fmt.Errorf("BeginFile: %w", err)
+	}
+	enc.batch.inFile = true
+	return nil
+}
+
+// BeginBatch writes the header of a batch, which holds every message encoded
+// until EndBatch.
+func (enc *Encoder) BeginBatch(bhs BHS) error {
+	if enc.batch.inBatch {
+		return fmt.Errorf("BeginBatch: batch already begun")
+	}
+	if err := enc.encodeHeader(reflect.ValueOf(bhs), "BHS"); err != nil {
+		return fmt.Errorf("BeginBatch: %w", err)
+	}
+	enc.batch.inBatch = true
+	enc.batch.batches++
+	enc.batch.messages = 0
+	return nil
+}
+
+// EndBatch writes the trailer of the current batch, with BTS-1 set to the
+// number of messages encoded since BeginBatch.
+func (enc *Encoder) EndBatch(bts BTS) error {
+	if !enc.batch.inBatch {
+		return fmt.Errorf("EndBatch: no batch begun")
+	}
+	bts.BatchMessageCount = ST(strconv.Itoa(enc.batch.messages))
+	if err := enc.encodeTrailer(reflect.ValueOf(bts), "BTS"); err != nil {
+		return fmt.Errorf("EndBatch: %w", err)
+	}
+	enc.batch.inBatch = false
+	return nil
+}
+
+// EndFile writes the trailer of the file, with FTS-1 set to the number of
+// batches written since BeginFile.
+func (enc *Encoder) EndFile(fts FTS) error {
+	if !enc.batch.inFile {
+		return fmt.Errorf("EndFile: no file begun")
+	}
+	if enc.batch.inBatch {
+		return fmt.Errorf("EndFile: batch not ended")
+	}
+	fts.FileBatchCount = NM(strconv.Itoa(enc.batch.batches))
+	if err := enc.encodeTrailer(reflect.ValueOf(fts), "FTS"); err != nil {
+		return fmt.Errorf("EndFile: %w", err)
+	}
+	enc.batch = encoderBatch{}
+	return nil
+}
+
+// encoderBatch tracks the batch and file an Encoder is writing.
+type encoderBatch struct {
+	inFile   bool
+	inBatch  bool
+	batches  int
+	messages int
+	delims   delimiters // of the latest header
+}
+
+func (enc *Encoder) encodeHeader(v reflect.Value, name string) error {
+	delims, err := segmentDelimiters(v)
+	if err != nil {
+		return err
+	}
+	enc.batch.delims = delims
+	return enc.encodeTrailer(v, name)
+}
+
+// encodeTrailer writes a segment with the delimiters of the latest header.
+func (enc *Encoder) encodeTrailer(v reflect.Value, name string) error {
+	enc.delims = enc.batch.delims
+	if enc.delims.field == 0 {
+		enc.delims = newDelimiters(defaultFieldSeparator[0], []byte(defaultEncodingCharacters))
+	}
+	var buf bytes.Buffer
+	if err := enc.encodeSegment(&buf, name, v); err != nil {
+		return err
+	}
+	_, err := enc.w.Write(buf.Bytes())
+	return err
+}
+
+/*
+NewBatchACK builds the header of a batch acknowledging the batch with header
+bhs: the sending and receiving application and facility are swapped and
+BHS-12 refers to the original BHS-11. Write it with Encoder.BeginBatch,
+followed by an ACK per message (see NewACK) and Encoder.EndBatch.
+*/
+func NewBatchACK(bhs BHS) BHS {
+	now := time.Now()
+	return BHS{
+		FieldSeparator:          bhs.FieldSeparator,
+		EncodingCharacters:      bhs.EncodingCharacters,
+		SendingApplication:      bhs.ReceivingApplication,
+		SendingFacility:         bhs.ReceivingFacility,
+		ReceivingApplication:    bhs.SendingApplication,
+		ReceivingFacility:       bhs.SendingFacility,
+		DateTime:                NewTS(now, PrecisionSecond),
+		BatchControlId:          newControlId(now),
+		ReferenceBatchControlId: bhs.BatchControlId,
+	}
+}
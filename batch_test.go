@@ -0,0 +1,153 @@
+package faraday
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const batchTestFile = "FHS|^~\\&|LIS|Lab|EHR|Hosp|20250724000000||results.hl7||F001\r\n" +
+	"BHS|^~\\&|LIS|Lab|EHR|Hosp|20250724000000||||B001\r\n" +
+	"MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724000001||ORU^R01|MSG1|P|2.3\r\n" +
+	"PID|1||123^^^MRN||DOE^JANE\r\n" +
+	"MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724000002||ORU^R01|MSG2|P|2.3\r\n" +
+	"PID|1||456^^^MRN||DOE^JOHN\r\n" +
+	"BTS|2\r\n" +
+	"BHS|^~\\&|LIS|Lab|EHR|Hosp|20250724000000||||B002\r\n" +
+	"MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724000003||ORU^R01|MSG3|P|2.3\r\n" +
+	"PID|1||789^^^MRN||ROE^RICHARD\r\n" +
+	"BTS|1|end of batch|10~20\r\n" +
+	"FTS|2\r\n"
+
+func TestDecoder_Batch(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(batchTestFile))
+	require.Nil(t, dec.FileHeader())
+
+	var ids, batches []string
+	for dec.More() {
+		var msg ORU_R01
+		require.NoError(t, dec.Decode(&msg))
+		ids = append(ids, string(msg.MSH.MessageControlId))
+		batches = append(batches, string(dec.BatchHeader().BatchControlId))
+		require.Len(t, msg.Results, 1)
+	}
+	require.Equal(t, []string{"MSG1", "MSG2", "MSG3"}, ids)
+	require.Equal(t, []string{"B001", "B001", "B002"}, batches)
+
+	require.Equal(t, FHS{
+		FieldSeparator:       "|",
+		EncodingCharacters:   "^~\\&",
+		SendingApplication:   HD{NamespaceId: "LIS"},
+		SendingFacility:      HD{NamespaceId: "Lab"},
+		ReceivingApplication: HD{NamespaceId: "EHR"},
+		ReceivingFacility:    HD{NamespaceId: "Hosp"},
+		DateTime:             "20250724000000",
+		FileNameId:           "results.hl7",
+		FileControlId:        "F001",
+	}, *dec.FileHeader())
+	require.Equal(t, BTS{BatchMessageCount: "1", BatchComment: "end of batch", BatchTotals: []NM{"10", "20"}}, *dec.BatchTrailer())
+	require.Equal(t, FTS{FileBatchCount: "2"}, *dec.FileTrailer())
+
+	var msg ORU_R01
+	require.Equal(t, io.EOF, dec.Decode(&msg))
+}
+
+func TestDecoder_BatchCounts(t *testing.T) {
+	raw := strings.Replace(batchTestFile, "BTS|2", "BTS|3", 1)
+	raw = strings.Replace(raw, "FTS|2", "FTS|1", 1)
+
+	var errs []string
+	var n int
+	for _, err := range Messages[Message](NewDecoder(strings.NewReader(raw))) {
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		n++
+	}
+	require.Equal(t, 3, n)
+	require.Equal(t, []string{
+		"Decode: BTS-1: expected 3 messages, read 2",
+		"Decode: FTS-1: expected 1 batches, read 2",
+	}, errs)
+}
+
+func TestEncoder_Batch(t *testing.T) {
+	var msgs []Message
+	for msg, err := range Messages[Message](NewDecoder(strings.NewReader(batchTestFile))) {
+		require.NoError(t, err)
+		msgs = append(msgs, msg)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.BeginFile(FHS{
+		SendingApplication:   HD{NamespaceId: "LIS"},
+		SendingFacility:      HD{NamespaceId: "Lab"},
+		ReceivingApplication: HD{NamespaceId: "EHR"},
+		ReceivingFacility:    HD{NamespaceId: "Hosp"},
+		DateTime:             "20250724000000",
+		FileNameId:           "results.hl7",
+		FileControlId:        "F001",
+	}))
+	require.NoError(t, enc.BeginBatch(BHS{
+		SendingApplication:   HD{NamespaceId: "LIS"},
+		SendingFacility:      HD{NamespaceId: "Lab"},
+		ReceivingApplication: HD{NamespaceId: "EHR"},
+		ReceivingFacility:    HD{NamespaceId: "Hosp"},
+		DateTime:             "20250724000000",
+		BatchControlId:       "B001",
+	}))
+	require.Error(t, enc.BeginBatch(BHS{}))
+	require.Error(t, enc.EndFile(FTS{}))
+	require.NoError(t, enc.Encode(msgs[0]))
+	require.NoError(t, enc.Encode(msgs[1]))
+	require.NoError(t, enc.EndBatch(BTS{BatchMessageCount: "99"}))
+	require.NoError(t, enc.BeginBatch(BHS{
+		SendingApplication:   HD{NamespaceId: "LIS"},
+		SendingFacility:      HD{NamespaceId: "Lab"},
+		ReceivingApplication: HD{NamespaceId: "EHR"},
+		ReceivingFacility:    HD{NamespaceId: "Hosp"},
+		DateTime:             "20250724000000",
+		BatchControlId:       "B002",
+	}))
+	require.NoError(t, enc.Encode(msgs[2]))
+	require.NoError(t, enc.EndBatch(BTS{BatchComment: "end of batch", BatchTotals: []NM{"10", "20"}}))
+	require.NoError(t, enc.EndFile(FTS{}))
+	require.Error(t, enc.EndFile(FTS{}))
+
+	require.Equal(t, strings.ReplaceAll(batchTestFile, "\r\n", "\r"), buf.String())
+}
+
+func TestNewBatchACK(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(batchTestFile))
+	var msg ORU_R01
+	require.NoError(t, dec.Decode(&msg))
+	bhs := *dec.BatchHeader()
+
+	header := NewBatchACK(bhs)
+	require.Equal(t, HD{NamespaceId: "EHR"}, header.SendingApplication)
+	require.Equal(t, HD{NamespaceId: "LIS"}, header.ReceivingApplication)
+	require.Equal(t, ST("B001"), header.ReferenceBatchControlId)
+	require.NotEmpty(t, header.BatchControlId)
+
+	ack, err := NewACK(msg.MSH, ApplicationAccept, "")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.BeginBatch(header))
+	require.NoError(t, enc.Encode(ack))
+	require.NoError(t, enc.EndBatch(BTS{}))
+	require.True(t, strings.HasPrefix(buf.String(), "BHS|^~\\&|EHR|Hosp|LIS|Lab|"))
+	require.Contains(t, buf.String(), "\rMSA|AA|MSG1\rBTS|1\r")
+
+	dec = NewDecoder(&buf)
+	var got ACK
+	require.NoError(t, dec.Decode(&got))
+	require.Equal(t, ST("B001"), dec.BatchHeader().ReferenceBatchControlId)
+	require.Equal(t, io.EOF, dec.Decode(&got))
+}
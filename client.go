@@ -0,0 +1,317 @@
+package faraday
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/s-hammon/p"
+)
+
+// ErrAckTimeout is returned by Client.Send when no ACK arrived in time.
+var ErrAckTimeout = errors.New("mllp: timed out waiting for ACK")
+
+// AckStatus classifies the outcome of sending a message.
+type AckStatus uint8
+
+const (
+	AckAccepted         AckStatus = iota + 1 // AA or CA
+	AckApplicationError                      // AE or CE
+	AckRejected                              // AR or CR
+	AckTimeout                               // no ACK in time
+	AckNotRequested                          // MSH-15/16 ask for no ACK, so none was awaited
+	AckNotReceived                           // MSH-15/16 ask for an ACK only on error (ER) or success (SU), and none came
+)
+
+func (s AckStatus) String() string {
+	switch s {
+	case AckAccepted:
+		return "accepted"
+	case AckApplicationError:
+		return "application error"
+	case AckRejected:
+		return "rejected"
+	case AckTimeout:
+		return "timeout"
+	case AckNotRequested:
+		return "not requested"
+	case AckNotReceived:
+		return "not received"
+	default:
+		return fmt.Sprintf("AckStatus(%d)", uint8(s))
+	}
+}
+
+// ackStatus classifies an acknowledgment code (HL7 0008).
+func ackStatus(code ID) (AckStatus, bool) {
+	switch code {
+	case ApplicationAccept, CommitAccept:
+		return AckAccepted, true
+	case ApplicationError, CommitError:
+		return AckApplicationError, true
+	case ApplicationReject, CommitReject:
+		return AckRejected, true
+	}
+	return 0, false
+}
+
+// Response is the outcome of sending a message with a Client.
+type Response struct {
+	Status   AckStatus
+	ACK      ACK    // the decoded ACK, if one was received
+	Raw      []byte // the ACK as received, without MLLP framing
+	Attempts int
+}
+
+/*
+Client sends messages to MLLP servers and waits for their ACKs. An ACK is
+matched to the message by MSA-2, so ACKs left over from earlier attempts are
+skipped. Connections are kept in a pool per destination address and reused
+across calls; a Client is safe for concurrent use.
+
+Which ACK is awaited follows MSH-15 and MSH-16 (HL7 0155) of the message. In
+original mode, i.e. both are empty, it is the application ACK (AA/AE/AR). In
+enhanced mode it is the accept ACK (CA/CE/CR) unless MSH-15 is NE, and then the
+application ACK unless MSH-16 is NE as well, in which case none is awaited
+(AckNotRequested). An empty MSH-15 or MSH-16 counts as AL in enhanced mode.
+When the awaited ACK is only sent on errors (ER) or on success (SU), waiting
+for it to time out is not an error, but its status is AckNotReceived rather
+than a guess at the outcome.
+*/
+type Client struct {
+	// DialTimeout limits connecting to a destination, AckTimeout waiting for
+	// the ACK of a message. Both default to 30 seconds.
+	DialTimeout time.Duration
+	AckTimeout  time.Duration
+
+	// MaxAttempts is the number of times a message is sent when its ACK times
+	// out, it is rejected (AR/CR) or the connection breaks, 3 if zero.
+	// Attempts are spaced by RetryBackoff (1 second if zero), doubling every
+	// time.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+
+	// MaxIdleConns is the number of idle connections kept per destination,
+	// 2 if zero.
+	MaxIdleConns int
+
+	// MaxMessageSize is the maximum size of an ACK in bytes, 16MB if zero.
+	MaxMessageSize int
+
+	mu    sync.Mutex
+	pools map[string][]*clientConn
+}
+
+type clientConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Send sends the encoded message msg to addr and waits for its ACK, retrying
+// as configured. An error is returned if the message could not be delivered
+// or the ACK timed out (ErrAckTimeout); application errors and rejections are
+// reported through the Response's Status.
+func (c *Client) Send(ctx context.Context, addr string, msg []byte) (*Response, error) {
+	var header struct {
+		MSH MSH `hl7:"opt=R"`
+	}
+	if err := NewDecoder(bytes.NewReader(msg)).Decode(&header); err != nil {
+		return nil, fmt.Errorf("Send: %w", err)
+	}
+	msh := header.MSH
+	if msh.MessageControlId == "" {
+		return nil, fmt.Errorf("Send: MSH-10 is missing")
+	}
+
+	resp := &Response{}
+	backoff := orDefault(c.RetryBackoff, time.Second)
+	for {
+		resp.Attempts++
+		err := c.attempt(ctx, addr, msg, msh, resp)
+		retry := err != nil || resp.Status == AckRejected
+		if !retry || resp.Attempts >= orDefault(c.MaxAttempts, 3) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// SendMessage encodes val (see Marshal) and sends it to addr.
+func (c *Client) SendMessage(ctx context.Context, addr string, val any) (*Response, error) {
+	msg, err := Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("SendMessage: %w", err)
+	}
+	return c.Send(ctx, addr, msg)
+}
+
+// attempt sends msg once and fills in resp. A pooled connection found to be
+// broken is replaced by a new one straight away.
+func (c *Client) attempt(ctx context.Context, addr string, msg []byte, msh MSH, resp *Response) error {
+	resp.Status, resp.ACK, resp.Raw = 0, ACK{}, nil
+
+	conn, reused, err := c.conn(ctx, addr)
+	if err != nil {
+		return err
+	}
+	err = c.exchange(ctx, conn, msg, msh, resp)
+	if reused && isBrokenConn(err) {
+		conn.Close()
+		if conn, err = c.dial(ctx, addr); err != nil {
+			return err
+		}
+		err = c.exchange(ctx, conn, msg, msh, resp)
+	}
+	if err != nil {
+		conn.Close()
+		if errors.Is(err, ErrAckTimeout) {
+			if ackType := awaitedAckType(msh); ackType == "ER" || ackType == "SU" {
+				resp.Status = AckNotReceived
+				return nil
+			}
+			resp.Status = AckTimeout
+		}
+		return err
+	}
+	c.release(addr, conn)
+	return nil
+}
+
+// exchange writes msg to conn and reads ACKs until the one for msg.
+func (c *Client) exchange(ctx context.Context, conn *clientConn, msg []byte, msh MSH, resp *Response) error {
+	deadline := time.Now().Add(orDefault(c.AckTimeout, 30*time.Second))
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := writeFrame(conn, msg); err != nil {
+		return c.wrapErr(ctx, err)
+	}
+	if awaitedAckType(msh) == "NE" {
+		resp.Status = AckNotRequested
+		return nil
+	}
+
+	for {
+		raw, err := readFrame(conn.r, orDefault(c.MaxMessageSize, maxSegmentSize))
+		if err != nil {
+			return c.wrapErr(ctx, err)
+		}
+		var ack ACK
+		if err := NewDecoder(bytes.NewReader(raw)).Decode(&ack); err != nil {
+			continue
+		}
+		if ack.MSA.MessageControlId != msh.MessageControlId {
+			continue
+		}
+		status, ok := ackStatus(ack.MSA.AcknowledgmentCode)
+		if !ok {
+			return fmt.Errorf("mllp: invalid acknowledgment code '%s'", ack.MSA.AcknowledgmentCode)
+		}
+		resp.Status, resp.ACK, resp.Raw = status, ack, raw
+		return nil
+	}
+}
+
+// awaitedAckType returns the acknowledgment type (HL7 0155) of the ACK awaited
+// for a message with header msh, see Client.
+func awaitedAckType(msh MSH) ID {
+	if !msh.EnhancedMode() {
+		return "AL"
+	}
+	if accept := p.Coalesce(msh.AcceptAcknowledgmentType, "AL"); accept != "NE" {
+		return accept
+	}
+	return p.Coalesce(msh.ApplicationAcknowledgmentType, "AL")
+}
+
+func (c *Client) wrapErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return ErrAckTimeout
+	}
+	return fmt.Errorf("mllp: %w", err)
+}
+
+// isBrokenConn reports whether err means the peer closed the connection.
+func isBrokenConn(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// conn takes an idle connection to addr from the pool, or dials a new one.
+func (c *Client) conn(ctx context.Context, addr string) (*clientConn, bool, error) {
+	c.mu.Lock()
+	if idle := c.pools[addr]; len(idle) > 0 {
+		conn := idle[len(idle)-1]
+		c.pools[addr] = idle[:len(idle)-1]
+		c.mu.Unlock()
+		return conn, true, nil
+	}
+	c.mu.Unlock()
+	conn, err := c.dial(ctx, addr)
+	return conn, false, err
+}
+
+func (c *Client) dial(ctx context.Context, addr string) (*clientConn, error) {
+	d := net.Dialer{Timeout: orDefault(c.DialTimeout, 30*time.Second)}
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("mllp: %w", err)
+	}
+	return &clientConn{Conn: nc, r: bufio.NewReader(nc)}, nil
+}
+
+// release returns a connection to the pool, or closes it if the pool is full.
+func (c *Client) release(addr string, conn *clientConn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pools == nil {
+		c.pools = make(map[string][]*clientConn)
+	}
+	if len(c.pools[addr]) >= orDefault(c.MaxIdleConns, 2) {
+		conn.Close()
+		return
+	}
+	c.pools[addr] = append(c.pools[addr], conn)
+}
+
+// Close closes all idle connections.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for addr, idle := range c.pools {
+		for _, conn := range idle {
+			errs = append(errs, conn.Close())
+		}
+		delete(c.pools, addr)
+	}
+	return errors.Join(errs...)
+}
+
+func orDefault[T time.Duration | int](v, def T) T {
+	if v > 0 {
+		return v
+	}
+	return def
+}
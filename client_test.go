@@ -0,0 +1,204 @@
+package faraday
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	addr := startServer(t, &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		if strings.Contains(string(req.Raw), "ERROR") {
+			return nil, errors.New("no such patient")
+		}
+		return nil, nil
+	})})
+
+	c := &Client{}
+	defer c.Close()
+
+	resp, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckAccepted, resp.Status)
+	require.Equal(t, 1, resp.Attempts)
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationAccept, MessageControlId: "MSG1"}, resp.ACK.MSA)
+	require.Contains(t, string(resp.Raw), "MSA|AA|MSG1")
+
+	// the connection is reused
+	resp, err = c.Send(context.Background(), addr, []byte(strings.Replace(serverTestMessage, "JANE", "ERROR", 1)))
+	require.NoError(t, err)
+	require.Equal(t, AckApplicationError, resp.Status)
+	require.Equal(t, ST("no such patient"), resp.ACK.MSA.TextMessage)
+	require.Len(t, c.pools[addr], 1)
+
+	resp, err = c.SendMessage(context.Background(), addr, ACK{MSH: MSH{MessageControlId: "MSG2", AcceptAcknowledgmentType: "AL"}})
+	require.NoError(t, err)
+	require.Equal(t, AckAccepted, resp.Status)
+	require.Equal(t, CommitAccept, resp.ACK.MSA.AcknowledgmentCode)
+
+	_, err = c.Send(context.Background(), addr, []byte("PID|1\r"))
+	require.Error(t, err)
+}
+
+func TestClient_RetryRejected(t *testing.T) {
+	var calls atomic.Int32
+	addr := startServer(t, &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		if calls.Add(1) < 3 {
+			ack, err := NewACK(req.MSH, ApplicationReject, "busy")
+			return &ack, err
+		}
+		return nil, nil
+	})})
+
+	c := &Client{RetryBackoff: time.Millisecond}
+	defer c.Close()
+	resp, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckAccepted, resp.Status)
+	require.Equal(t, 3, resp.Attempts)
+
+	calls.Store(0)
+	c.MaxAttempts = 2
+	resp, err = c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckRejected, resp.Status)
+	require.Equal(t, 2, resp.Attempts)
+}
+
+// fakeServer answers every message on a loopback listener with reply.
+func fakeServer(t *testing.T, reply func(n int, msg []byte) []string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	var n atomic.Int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					msg, err := readFrame(r, maxSegmentSize)
+					if err != nil {
+						return
+					}
+					for _, ack := range reply(int(n.Add(1)), msg) {
+						writeFrame(conn, []byte(ack))
+					}
+				}
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestClient_Correlation(t *testing.T) {
+	addr := fakeServer(t, func(n int, msg []byte) []string {
+		return []string{
+			"MSH|^~\\&|||||||ACK|1|P|2.3\rMSA|AA|STALE\r",
+			"not an ACK",
+			"MSH|^~\\&|||||||ACK|2|P|2.3\rMSA|AE|MSG1|oops\r",
+		}
+	})
+
+	c := &Client{}
+	defer c.Close()
+	resp, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckApplicationError, resp.Status)
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationError, MessageControlId: "MSG1", TextMessage: "oops"}, resp.ACK.MSA)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	addr := fakeServer(t, func(n int, msg []byte) []string {
+		if n < 2 {
+			return nil
+		}
+		return []string{"MSH|^~\\&|||||||ACK|1|P|2.3\rMSA|AA|MSG1\r"}
+	})
+
+	c := &Client{AckTimeout: 50 * time.Millisecond, RetryBackoff: time.Millisecond}
+	defer c.Close()
+	resp, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckAccepted, resp.Status)
+	require.Equal(t, 2, resp.Attempts)
+
+	addr = fakeServer(t, func(n int, msg []byte) []string { return nil })
+	c.MaxAttempts = 2
+	resp, err = c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.ErrorIs(t, err, ErrAckTimeout)
+	require.Equal(t, AckTimeout, resp.Status)
+	require.Equal(t, 2, resp.Attempts)
+
+	// an ACK only sent on errors or success may not come, which is not taken
+	// for either
+	for _, ackTypes := range []string{"ER", "SU", "NE|ER", "NE|SU"} {
+		resp, err = c.Send(context.Background(), addr, []byte(strings.Replace(serverTestMessage, "P|2.3", "P|2.3|||"+ackTypes, 1)))
+		require.NoError(t, err, ackTypes)
+		require.Equal(t, AckNotReceived, resp.Status, ackTypes)
+		require.Equal(t, 1, resp.Attempts, ackTypes)
+	}
+
+	resp, err = c.Send(context.Background(), addr, []byte(strings.Replace(serverTestMessage, "P|2.3", "P|2.3|||NE|NE", 1)))
+	require.NoError(t, err)
+	require.Equal(t, AckNotRequested, resp.Status)
+
+	// with no accept ACK, the application ACK asked for by MSH-16 is awaited
+	resp, err = c.Send(context.Background(), addr, []byte(strings.Replace(serverTestMessage, "P|2.3", "P|2.3|||NE|AL", 1)))
+	require.ErrorIs(t, err, ErrAckTimeout)
+	require.Equal(t, AckTimeout, resp.Status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = (&Client{}).Send(ctx, addr, []byte(serverTestMessage))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestAwaitedAckType(t *testing.T) {
+	for fields, want := range map[[2]ID]ID{
+		{"", ""}:     "AL",
+		{"AL", "NE"}: "AL",
+		{"ER", "AL"}: "ER",
+		{"", "NE"}:   "AL",
+		{"NE", ""}:   "AL",
+		{"NE", "SU"}: "SU",
+		{"NE", "NE"}: "NE",
+	} {
+		msh := MSH{AcceptAcknowledgmentType: fields[0], ApplicationAcknowledgmentType: fields[1]}
+		require.Equal(t, want, awaitedAckType(msh), fields)
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	addr := startServer(t, &Server{
+		IdleTimeout: 20 * time.Millisecond,
+		Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+			return nil, nil
+		}),
+	})
+
+	c := &Client{RetryBackoff: time.Millisecond}
+	defer c.Close()
+	_, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+
+	// the server has closed the pooled connection by now
+	time.Sleep(50 * time.Millisecond)
+	resp, err := c.Send(context.Background(), addr, []byte(serverTestMessage))
+	require.NoError(t, err)
+	require.Equal(t, AckAccepted, resp.Status)
+	require.Equal(t, 1, resp.Attempts)
+}
@@ -7,6 +7,10 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/s-hammon/p"
 )
 
 // The standard MSH segment
@@ -43,90 +47,210 @@ type MSH struct {
 	AcceptAcknowledgmentType      ID `hl7:"tbl=0155"`
 	ApplicationAcknowledgmentType ID `hl7:"tbl=0155"`
 	CountryCode                   ID
-	CharacterSet                  ID `hl7:"rep=Y3,tbl=0211"`
+	CharacterSet                  []ID `hl7:"rep=Y3,tbl=0211"`
 	PrincipalLanguage             CE
 }
 
 func (seg *MSH) UnmarshalHeader(b []byte) error {
+	return seg.decodeHeader(b, false)
+}
+
+func (seg *MSH) decodeHeader(b []byte, keepEscapes bool) error {
+	return unmarshalHeader(reflect.ValueOf(seg).Elem(), "MSH", b, keepEscapes)
+}
+
+// headerDecoder is implemented by the header segments of this package, which
+// can leave escape sequences as-is for a Decoder told to keep them.
+type headerDecoder interface {
+	decodeHeader(b []byte, keepEscapes bool) error
+}
+
+// unmarshalHeader decodes a header segment (MSH, BHS or FHS) without its name
+// into seg, whose first two fields take the field separator and encoding
+// characters.
+func unmarshalHeader(seg reflect.Value, name string, b []byte, keepEscapes bool) error {
 	if len(b) < 6 {
 		return fmt.Errorf("input '%s' too short--must be at least 6 bytes", string(b))
 	}
-	seg.FieldSeparator = ST(b[:1])
-	seg.EncodingCharacters = ST(b[1:5])
-
-	v := reflect.ValueOf(seg).Elem()
-	t := v.Type()
-
-	fields := bytes.Split(b[6:], seg.fieldSeparator())
-	for i, j := 0, 2; i < len(fields) && j < v.NumField(); i, j = i+1, j+1 {
-		fVal := v.Field(j)
-		fTyp := t.Field(j).Type
-
-		raw := fields[i]
-		switch fTyp {
-		case reflect.TypeOf(ST("")):
-			fVal.SetString(string(raw))
-		case reflect.TypeOf(HD{}):
-			parts := bytes.Split(raw, seg.componentSeparator())
-			fVal.Set(reflect.ValueOf(HD{
-				NamespaceId:     IS(partsSafe(parts, 0)),
-				UniversalId:     ST(partsSafe(parts, 1)),
-				UniversalIdType: ID(partsSafe(parts, 2)),
-			}))
-		case reflect.TypeOf(TS("")):
-			fVal.Set(reflect.ValueOf(TS(raw)))
-		case reflect.TypeOf(ID("")):
-			fVal.Set(reflect.ValueOf(ID(raw)))
-		case reflect.TypeOf(NM("")):
-			fVal.Set(reflect.ValueOf(NM(raw)))
-		case reflect.TypeOf(CE{}):
-			parts := bytes.Split(raw, seg.componentSeparator())
-			fVal.Set(reflect.ValueOf(CE{
-				Identifier:            ST(partsSafe(parts, 0)),
-				Text:                  ST(partsSafe(parts, 1)),
-				CodingSystem:          ST(partsSafe(parts, 2)),
-				AlternateIdentifier:   ST(partsSafe(parts, 3)),
-				AlternateText:         ST(partsSafe(parts, 4)),
-				AlternateCodingSystem: ST(partsSafe(parts, 5)),
-			}))
-		case reflect.TypeOf(CM_MSG{}):
-			parts := bytes.Split(raw, seg.componentSeparator())
-			fVal.Set(reflect.ValueOf(CM_MSG{
-				Type:  ID(partsSafe(parts, 0)),
-				Event: ID(partsSafe(parts, 1)),
-			}))
-		case reflect.TypeOf(PT{}):
-			parts := bytes.Split(raw, seg.componentSeparator())
-			fVal.Set(reflect.ValueOf(PT{
-				ProcessingId:   ID(partsSafe(parts, 0)),
-				ProcessingMode: ID(partsSafe(parts, 1)),
-			}))
-		default:
+	encChars := encodingCharacters(b)
+	seg.Field(0).SetString(string(b[:1]))
+	seg.Field(1).SetString(string(encChars))
+
+	t := seg.Type()
+	delims := newDelimiters(b[0], encChars)
+	fields := bytes.Split(b[min(len(b), len(encChars)+2):], b[:1])
+	for i, j := 0, 2; i < len(fields) && j < seg.NumField(); i, j = i+1, j+1 {
+		spec := NewFieldSpec(uint8(j+1), seg.Field(j))
+		spec.ParseTag(t.Field(j).Tag.Get("hl7"))
+		if err := spec.parse(fields[i], delims, keepEscapes); err != nil {
+			return fmt.Errorf("%s-%d: %w", name, j+1, err)
 		}
 	}
 	return nil
 }
 
-func (seg *MSH) fieldSeparator() []byte {
-	return []byte(seg.FieldSeparator)
+// EnhancedMode reports whether the sender asked for enhanced mode
+// acknowledgments by filling in MSH-15 or MSH-16.
+func (seg *MSH) EnhancedMode() bool {
+	return seg.AcceptAcknowledgmentType != "" || seg.ApplicationAcknowledgmentType != ""
 }
 
-func (seg *MSH) componentSeparator() []byte {
-	return []byte(seg.EncodingCharacters[:1])
+// The standard MSA segment
+type MSA struct {
+	AcknowledgmentCode        ID `hl7:"opt=R,tbl=0008"`
+	MessageControlId          ST `hl7:"opt=R"`
+	TextMessage               ST
+	ExpectedSequenceNumber    NM
+	DelayedAcknowledgmentType ID
+	ErrorCondition            CE
+}
+
+// The standard ERR segment
+type ERR struct {
+	ErrorCodeAndLocation []CM_ELD `hl7:"opt=R,rep=Y"`
+}
+
+type ACK struct {
+	MSH MSH `hl7:"opt=R"`
+	MSA MSA `hl7:"opt=R"`
+	ERR ERR
+}
+
+// Acknowledgment codes (HL7 0008). The commit codes are only used in enhanced
+// mode, to acknowledge the message was safely received.
+const (
+	ApplicationAccept ID = "AA"
+	ApplicationError  ID = "AE"
+	ApplicationReject ID = "AR"
+	CommitAccept      ID = "CA"
+	CommitError       ID = "CE"
+	CommitReject      ID = "CR"
+)
+
+var controlSequence atomic.Uint32
+
+// newControlId returns a control ID for a generated message or batch, unique
+// to this process for up to 10000 IDs per second.
+func newControlId(now time.Time) ST {
+	return ST(fmt.Sprintf("%s%04d", now.Format("20060102150405"), controlSequence.Add(1)%10000))
+}
+
+/*
+NewACK builds the acknowledgment of the message with header msh: the sending
+and receiving application and facility are swapped, the processing ID,
+version and character sets are kept, and MSH-10 is echoed in MSA-2. The ACK
+gets its own control ID and timestamp.
+
+Commit codes (CA/CE/CR) are only accepted if the sender asked for enhanced
+mode acknowledgments, see MSH.EnhancedMode.
+*/
+func NewACK(msh MSH, code ID, text string) (ACK, error) {
+	if !AcknowledgmentCodes.Valid(code) {
+		return ACK{}, fmt.Errorf("invalid acknowledgment code '%s'", code)
+	}
+	if (code == CommitAccept || code == CommitError || code == CommitReject) && !msh.EnhancedMode() {
+		return ACK{}, fmt.Errorf("cannot send %s to a message asking for original mode acknowledgments", code)
+	}
+	if msh.MessageControlId == "" {
+		return ACK{}, fmt.Errorf("cannot acknowledge a message without MSH-10")
+	}
+
+	return newACK(msh, code, text), nil
 }
 
-func partsSafe(parts [][]byte, i int) string {
-	if i < len(parts) {
-		return string(parts[i])
+// newACK builds an acknowledgment without checking code or msh, e.g. for
+// rejecting a message whose header could not be read.
+func newACK(msh MSH, code ID, text string) ACK {
+	now := time.Now()
+	return ACK{
+		MSH: MSH{
+			FieldSeparator:       p.Coalesce(msh.FieldSeparator, defaultFieldSeparator),
+			EncodingCharacters:   p.Coalesce(msh.EncodingCharacters, defaultEncodingCharacters),
+			SendingApplication:   msh.ReceivingApplication,
+			SendingFacility:      msh.ReceivingFacility,
+			ReceivingApplication: msh.SendingApplication,
+			ReceivingFacility:    msh.SendingFacility,
+			DateTime:             NewTS(now, PrecisionSecond),
+			MessageType:          CM_MSG{Type: "ACK", Event: msh.MessageType.Event},
+			MessageControlId:     newControlId(now),
+			ProcessingId:         msh.ProcessingId,
+			VersionId:            msh.VersionId,
+			CharacterSet:         msh.CharacterSet,
+		},
+		MSA: MSA{
+			AcknowledgmentCode: code,
+			MessageControlId:   msh.MessageControlId,
+			TextMessage:        ST(text),
+		},
 	}
-	return ""
+}
+
+// The standard FHS segment
+type FHS struct {
+	FieldSeparator         ST `hl7:"opt=R"`
+	EncodingCharacters     ST `hl7:"opt=R"`
+	SendingApplication     HD
+	SendingFacility        HD
+	ReceivingApplication   HD
+	ReceivingFacility      HD
+	DateTime               TS
+	Security               ST
+	FileNameId             ST
+	FileHeaderComment      ST
+	FileControlId          ST
+	ReferenceFileControlId ST
+}
+
+func (seg *FHS) UnmarshalHeader(b []byte) error {
+	return seg.decodeHeader(b, false)
+}
+
+func (seg *FHS) decodeHeader(b []byte, keepEscapes bool) error {
+	return unmarshalHeader(reflect.ValueOf(seg).Elem(), "FHS", b, keepEscapes)
+}
+
+// The standard FTS segment
+type FTS struct {
+	FileBatchCount     NM
+	FileTrailerComment ST
+}
+
+// The standard BHS segment
+type BHS struct {
+	FieldSeparator          ST `hl7:"opt=R"`
+	EncodingCharacters      ST `hl7:"opt=R"`
+	SendingApplication      HD
+	SendingFacility         HD
+	ReceivingApplication    HD
+	ReceivingFacility       HD
+	DateTime                TS
+	Security                ST
+	BatchNameIdType         ST
+	BatchComment            ST
+	BatchControlId          ST
+	ReferenceBatchControlId ST
+}
+
+func (seg *BHS) UnmarshalHeader(b []byte) error {
+	return seg.decodeHeader(b, false)
+}
+
+func (seg *BHS) decodeHeader(b []byte, keepEscapes bool) error {
+	return unmarshalHeader(reflect.ValueOf(seg).Elem(), "BHS", b, keepEscapes)
+}
+
+// The standard BTS segment
+type BTS struct {
+	BatchMessageCount ST
+	BatchComment      ST
+	BatchTotals       []NM `hl7:"rep=Y"`
 }
 
 // The standard NTE segment
 type NTE struct {
 	SetId           SI
 	SourceOfComment ID
-	Comment         FT `hl7:"rep=Y"`
+	Comment         []FT `hl7:"rep=Y"`
 }
 
 // The standard DSC segment
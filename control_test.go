@@ -30,5 +30,52 @@ func TestMSHUnmarshalHL7_Basic(t *testing.T) {
 	require.Equal(t, "", string(msh.AcceptAcknowledgmentType))
 	require.Equal(t, "", string(msh.ApplicationAcknowledgmentType))
 	require.Equal(t, "USA", string(msh.CountryCode))
-	require.Equal(t, "ASCII", string(msh.CharacterSet))
+	require.Equal(t, []ID{"ASCII"}, msh.CharacterSet)
+}
+
+func TestNewACK(t *testing.T) {
+	raw := []byte("MSH|^~\\&|SendingApp|SendingFac|ReceivingApp|ReceivingFac|202507231230||ADT^A01|MSG00001|P|2.3||||||ASCII")
+	var msh MSH
+	require.NoError(t, msh.UnmarshalHeader(raw[3:]))
+
+	ack, err := NewACK(msh, ApplicationError, "PID-3 is missing")
+	require.NoError(t, err)
+	require.Equal(t, HD{NamespaceId: "ReceivingApp"}, ack.MSH.SendingApplication)
+	require.Equal(t, HD{NamespaceId: "ReceivingFac"}, ack.MSH.SendingFacility)
+	require.Equal(t, HD{NamespaceId: "SendingApp"}, ack.MSH.ReceivingApplication)
+	require.Equal(t, HD{NamespaceId: "SendingFac"}, ack.MSH.ReceivingFacility)
+	require.Equal(t, CM_MSG{Type: "ACK", Event: "A01"}, ack.MSH.MessageType)
+	require.NotEmpty(t, ack.MSH.MessageControlId)
+	require.NotEqual(t, msh.MessageControlId, ack.MSH.MessageControlId)
+	require.Len(t, ack.MSH.DateTime, 19)
+	require.Equal(t, PT{ProcessingId: "P"}, ack.MSH.ProcessingId)
+	require.Equal(t, ID("2.3"), ack.MSH.VersionId)
+	require.Equal(t, MSA{AcknowledgmentCode: "AE", MessageControlId: "MSG00001", TextMessage: "PID-3 is missing"}, ack.MSA)
+	require.True(t, Validate(ack).Valid())
+
+	out, err := Marshal(ack)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "\rMSA|AE|MSG00001|PID-3 is missing\r")
+
+	other, err := NewACK(msh, ApplicationAccept, "")
+	require.NoError(t, err)
+	require.NotEqual(t, ack.MSH.MessageControlId, other.MSH.MessageControlId)
+}
+
+func TestNewACK_EnhancedMode(t *testing.T) {
+	msh := MSH{MessageControlId: "1", MessageType: CM_MSG{Type: "ORU", Event: "R01"}}
+	_, err := NewACK(msh, CommitAccept, "")
+	require.Error(t, err)
+
+	msh.AcceptAcknowledgmentType = "AL"
+	ack, err := NewACK(msh, CommitAccept, "")
+	require.NoError(t, err)
+	require.Equal(t, ST("|"), ack.MSH.FieldSeparator)
+	require.Equal(t, ST("^~\\&"), ack.MSH.EncodingCharacters)
+	require.Equal(t, CommitAccept, ack.MSA.AcknowledgmentCode)
+
+	_, err = NewACK(msh, "XX", "")
+	require.Error(t, err)
+	_, err = NewACK(MSH{}, ApplicationAccept, "")
+	require.Error(t, err)
 }
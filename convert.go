@@ -0,0 +1,391 @@
+package faraday
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+Convert converts a decoded message struct to another version, e.g. from v2.3
+to v2.5.1 and back. Since the data types of this package hold the components
+of all versions (see Version), converting mostly amounts to setting MSH-12,
+with the exceptions below. Every value which is moved or dropped is reported
+as a warning in the returned report, with its location.
+
+Upgrading to v2.5 or later moves patient identifiers which v2.5 only keeps
+for backward compatibility into PID-3 (patient identifier list):
+
+	PID-2	external ID, appended to PID-3
+	PID-4	alternate IDs, appended to PID-3
+	PID-12	county code, moved to the home address in PID-11, or the first
+		address if there is no home address
+	PID-19	SSN, appended to PID-3 with identifier type SS
+	PID-20	driver's license, appended to PID-3 with identifier type DL
+
+Downgrading below v2.5 moves the first SSN and driver's license in PID-3 back
+to PID-19 and PID-20. The other moves are not undone, since v2.3 allows their
+result as well: identifiers from PID-2 and PID-4 stay in PID-3, and the county
+code in the address, so a round trip does not restore PID-2, PID-4 and PID-12.
+
+CE values hold those of CWE, which replaces CE as of v2.6, so upgrading keeps
+them as they are; downgrading drops the CWE components CE does not have. In
+the same way, upgrading to v2.6 or later drops the degree of precision of TS
+values, which DTM does not have.
+
+Downgrading also drops the other components a data type does not have in the
+target version, e.g. XPN-9 to XPN-14 in v2.3, and flags codes which are not
+in the target version's tables.
+*/
+func Convert[T any](msg T, version string) (T, ValidationReport, error) {
+	var report ValidationReport
+	v := reflect.ValueOf(&msg).Elem()
+	v.Set(deepCopy(v))
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return msg, report, fmt.Errorf("Convert: expected message struct, got %T", msg)
+	}
+
+	cv := converter{to: version, report: &report, occurrences: map[string]int{}}
+	cv.convertGroup(v, schemaOf(v.Type()))
+	if !cv.header {
+		return msg, report, fmt.Errorf("Convert: %s has no MSH segment", v.Type())
+	}
+	return msg, report, nil
+}
+
+// deepCopy copies v without sharing any slice or pointer with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return out
+		}
+		out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return out
+		}
+		out.Set(reflect.New(v.Type().Elem()))
+		out.Elem().Set(deepCopy(v.Elem()))
+	default:
+		out.Set(v)
+	}
+	return out
+}
+
+type converter struct {
+	from, to    string
+	header      bool // whether MSH was converted
+	delims      delimiters
+	report      *ValidationReport
+	occurrences map[string]int
+}
+
+func (cv *converter) convertGroup(v reflect.Value, schema *structSchema) {
+	for _, member := range schema.members {
+		field := v.Field(member.index)
+		if !member.repeats {
+			cv.convertMember(member, field)
+			continue
+		}
+		for i := range field.Len() {
+			cv.convertMember(member, field.Index(i))
+		}
+	}
+}
+
+func (cv *converter) convertMember(member schemaMember, v reflect.Value) {
+	if member.group {
+		cv.convertGroup(v, member.schema)
+		return
+	}
+	if member.name == "MSH" {
+		cv.convertHeader(v)
+		return
+	}
+	if v.IsZero() {
+		return
+	}
+	cv.occurrences[member.name]++
+	loc := Location{Segment: member.name, Occurrence: cv.occurrences[member.name]}
+
+	if member.name == "PID" && v.Type() == reflect.TypeFor[PID]() {
+		switch {
+		case cv.upgrades("2.5"):
+			cv.upgradePID(loc, v.Addr().Interface().(*PID))
+		case cv.downgrades("2.5"):
+			cv.downgradePID(loc, v.Addr().Interface().(*PID))
+		}
+	}
+	cv.convertSegment(loc, v)
+}
+
+// convertHeader takes the version the message is converted from and its
+// delimiters, and sets the version it is converted to.
+func (cv *converter) convertHeader(v reflect.Value) {
+	version := v.FieldByName("VersionId")
+	if !version.IsValid() || version.Kind() != reflect.String {
+		return
+	}
+	cv.header = true
+	cv.from = version.String()
+	version.SetString(cv.to)
+
+	cv.delims = newDelimiters(defaultFieldSeparator[0], []byte(defaultEncodingCharacters))
+	if delims, err := segmentDelimiters(v); err == nil {
+		cv.delims = delims
+	}
+}
+
+// upgrades reports whether the message is converted from before version to
+// version or later.
+func (cv *converter) upgrades(version string) bool {
+	return compareVersions(cv.from, version) < 0 && compareVersions(cv.to, version) >= 0
+}
+
+// downgrades reports whether the message is converted from version or later
+// to before version.
+func (cv *converter) downgrades(version string) bool {
+	return compareVersions(cv.from, version) >= 0 && compareVersions(cv.to, version) < 0
+}
+
+// upgradePID moves the identifiers which v2.5 deprecates into PID-3 and the
+// county code into the patient's address.
+func (cv *converter) upgradePID(loc Location, pid *PID) {
+	field := func(n, rep int) Location {
+		return Location{Segment: "PID", Occurrence: loc.Occurrence, Field: n, Repetition: rep}
+	}
+	moveId := func(from Location, id CX) {
+		for i, existing := range pid.InternalPatientId {
+			if existing == id {
+				cv.report.add(from, SeverityWarning, "deprecated in v%s, dropped as PID-3[%d] holds it", cv.to, i+1)
+				return
+			}
+		}
+		pid.InternalPatientId = append(pid.InternalPatientId, id)
+		cv.report.add(from, SeverityWarning, "deprecated in v%s, moved to PID-3[%d]", cv.to, len(pid.InternalPatientId))
+	}
+
+	if pid.ExternalPatientId != (CX{}) {
+		moveId(field(2, 0), pid.ExternalPatientId)
+		pid.ExternalPatientId = CX{}
+	}
+	for i, id := range pid.AlternatePatientId {
+		moveId(field(4, i+1), id)
+	}
+	pid.AlternatePatientId = nil
+	if pid.SSN != "" {
+		moveId(field(19, 0), CX{IdNumber: pid.SSN, IdentifierTypeCode: "SS"})
+		pid.SSN = ""
+	}
+	if dln := pid.DriversLicenseNumber; dln != (DLN{}) {
+		moveId(field(20, 0), CX{
+			IdNumber:              dln.LicenseNumber,
+			IdentifierTypeCode:    "DL",
+			ExpirationDate:        dln.ExpirationDate,
+			AssigningJurisdiction: CWE{Identifier: ST(dln.IssuingState)},
+		})
+		pid.DriversLicenseNumber = DLN{}
+	}
+
+	if pid.CountyCode == "" {
+		return
+	}
+	i := slices.IndexFunc(pid.PatientAddress, func(a XAD) bool { return a.AddressType == "H" })
+	if i < 0 && len(pid.PatientAddress) > 0 {
+		i = 0
+	}
+	switch {
+	case i < 0:
+		cv.report.add(field(12, 0), SeverityWarning, "deprecated in v%s, kept as there is no address to move it to", cv.to)
+	case pid.PatientAddress[i].CountyCode == "":
+		pid.PatientAddress[i].CountyCode = pid.CountyCode
+		pid.CountyCode = ""
+		cv.report.add(field(12, 0), SeverityWarning, "deprecated in v%s, moved to PID-11[%d].9", cv.to, i+1)
+	case pid.PatientAddress[i].CountyCode == pid.CountyCode:
+		pid.CountyCode = ""
+		cv.report.add(field(12, 0), SeverityWarning, "deprecated in v%s, dropped as PID-11[%d].9 holds it", cv.to, i+1)
+	default:
+		cv.report.add(field(12, 0), SeverityWarning, "deprecated in v%s, kept as PID-11[%d].9 holds another county", cv.to, i+1)
+	}
+}
+
+// downgradePID moves an SSN and a driver's license from PID-3 back to PID-19
+// and PID-20, which is where versions before v2.5 keep them.
+func (cv *converter) downgradePID(loc Location, pid *PID) {
+	moves := []struct {
+		typeCode IS
+		field    int
+		empty    bool
+		restore  func(CX) CX // sets the field, returning what it keeps of the CX
+	}{
+		{"SS", 19, pid.SSN == "", func(id CX) CX {
+			pid.SSN = id.IdNumber
+			return CX{IdNumber: id.IdNumber, IdentifierTypeCode: id.IdentifierTypeCode}
+		}},
+		{"DL", 20, pid.DriversLicenseNumber == (DLN{}), func(id CX) CX {
+			pid.DriversLicenseNumber = DLN{
+				LicenseNumber:  id.IdNumber,
+				IssuingState:   IS(id.AssigningJurisdiction.Identifier),
+				ExpirationDate: id.ExpirationDate,
+			}
+			return CX{
+				IdNumber:              id.IdNumber,
+				IdentifierTypeCode:    id.IdentifierTypeCode,
+				ExpirationDate:        id.ExpirationDate,
+				AssigningJurisdiction: CWE{Identifier: id.AssigningJurisdiction.Identifier},
+			}
+		}},
+	}
+
+	var moved []int
+	for _, move := range moves {
+		i := slices.IndexFunc(pid.InternalPatientId, func(id CX) bool { return id.IdentifierTypeCode == move.typeCode })
+		if i < 0 || !move.empty {
+			continue
+		}
+		from := Location{Segment: "PID", Occurrence: loc.Occurrence, Field: 3, Repetition: i + 1}
+		if id := pid.InternalPatientId[i]; move.restore(id) != id {
+			cv.report.add(from, SeverityWarning, "moved to PID-%d, dropping the components it does not have", move.field)
+		} else {
+			cv.report.add(from, SeverityWarning, "moved to PID-%d", move.field)
+		}
+		moved = append(moved, i)
+	}
+	slices.Sort(moved)
+	for _, i := range slices.Backward(moved) {
+		pid.InternalPatientId = slices.Delete(pid.InternalPatientId, i, i+1)
+	}
+}
+
+func (cv *converter) convertSegment(loc Location, seg reflect.Value) {
+	for i := range seg.NumField() {
+		sf := seg.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		spec := NewFieldSpec(uint8(i+1), seg.Field(i)).ParseTag(sf.Tag.Get("hl7"))
+		floc := loc
+		floc.Field = int(spec.Position)
+
+		if spec.Val.Kind() != reflect.Slice {
+			cv.convertValue(floc, spec, spec.Val)
+			continue
+		}
+		for j := range spec.Val.Len() {
+			rloc := floc
+			rloc.Repetition = j + 1
+			cv.convertValue(rloc, spec, spec.Val.Index(j))
+		}
+	}
+}
+
+func (cv *converter) convertValue(loc Location, spec *FieldSpec, v reflect.Value) {
+	if v.IsZero() {
+		return
+	}
+	if spec.TableId != "" {
+		cv.convertCode(loc, spec.TableId, v)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		cv.trimComponents(loc, v)
+	case reflect.String:
+		cv.trimText(loc, v, cv.delims.component)
+	}
+}
+
+// convertCode flags a code which is in its table in the version converted from
+// but not in the one converted to.
+func (cv *converter) convertCode(loc Location, id string, v reflect.Value) {
+	if v.Kind() == reflect.Struct && v.NumField() > 0 {
+		v = v.Field(0)
+		loc.Component = 1
+	}
+	if v.Kind() != reflect.String {
+		return
+	}
+	from, ok := versionTable(cv.from, id)
+	if !ok || !from.Valid(ID(v.String())) {
+		return
+	}
+	if to, ok := versionTable(cv.to, id); ok && !to.Valid(ID(v.String())) {
+		cv.report.add(loc, SeverityWarning, "value '%s' is not in table %s in v%s", v.String(), id, cv.to)
+	}
+}
+
+// trimText drops the components of a value of a data type held as text, e.g.
+// the degree of precision of a TS, which it does not have in the version
+// converted to. Its components are separated by sep.
+func (cv *converter) trimText(loc Location, v reflect.Value, sep byte) {
+	n, ok := definedComponentCount(cv.to, v.Type())
+	if !ok {
+		return
+	}
+	parts := strings.Split(v.String(), string(sep))
+	if len(parts) <= n {
+		return
+	}
+	for i := n; i < len(parts); i++ {
+		if parts[i] == "" {
+			continue
+		}
+		cloc := loc
+		if loc.Component == 0 {
+			cloc.Component = i + 1
+		} else {
+			cloc.Subcomponent = i + 1
+		}
+		cv.report.add(cloc, SeverityWarning, "%s.%s cannot be represented in v%s, dropped",
+			v.Type().Name(), strconv.Itoa(i+1), cv.to)
+	}
+	v.SetString(strings.Join(parts[:n], string(sep)))
+}
+
+// trimComponents drops the components of a value, and of its components, which
+// its data type does not have in the version converted to.
+func (cv *converter) trimComponents(loc Location, v reflect.Value) {
+	n := componentCount(cv.to, v.Type())
+	for i := range v.NumField() {
+		component := v.Field(i)
+		if component.IsZero() {
+			continue
+		}
+		cloc := loc
+		if loc.Component == 0 {
+			cloc.Component = i + 1
+		} else {
+			cloc.Subcomponent = i + 1
+		}
+		if i >= n {
+			cv.report.add(cloc, SeverityWarning, "%s.%s cannot be represented in v%s, dropped",
+				v.Type().Name(), strconv.Itoa(i+1), cv.to)
+			component.SetZero()
+			continue
+		}
+		if loc.Component > 0 {
+			continue
+		}
+		switch component.Kind() {
+		case reflect.Struct:
+			cv.trimComponents(cloc, component)
+		case reflect.String:
+			cv.trimText(cloc, component, cv.delims.subcomponent)
+		}
+	}
+}
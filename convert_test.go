@@ -0,0 +1,235 @@
+package faraday
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const convertTestMessage = "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A01|MSG1|P|2.3\r" +
+	"EVN|A01|20250724000000\r" +
+	"PID|1|E1^^^Ext|123^^^MRN~E1^^^Ext|A1^^^Alt|DOE^JANE||19800101|F|||1 Main St^^Springfield||||||||123-45-6789\r" +
+	"PV1|1|I|W1^101^A||||1234^SMITH^JOHN\r"
+
+func TestConvert_Upgrade(t *testing.T) {
+	var msg ADT_A01
+	require.NoError(t, NewDecoder(strings.NewReader(convertTestMessage)).Decode(&msg))
+	msg.PID.CountyCode = "SPR"
+
+	out, report, err := Convert(msg, "2.5.1")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"PID[1]-2: warning: deprecated in v2.5.1, dropped as PID-3[2] holds it",
+		"PID[1]-4[1]: warning: deprecated in v2.5.1, moved to PID-3[3]",
+		"PID[1]-19: warning: deprecated in v2.5.1, moved to PID-3[4]",
+		"PID[1]-12: warning: deprecated in v2.5.1, moved to PID-11[1].9",
+	}, issueStrings(report))
+	require.Equal(t, ID("2.5.1"), out.MSH.VersionId)
+	require.Equal(t, []CX{
+		{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN"}},
+		{IdNumber: "E1", AssigningAuthority: HD{NamespaceId: "Ext"}},
+		{IdNumber: "A1", AssigningAuthority: HD{NamespaceId: "Alt"}},
+		{IdNumber: "123-45-6789", IdentifierTypeCode: "SS"},
+	}, out.PID.InternalPatientId)
+	require.Equal(t, CX{}, out.PID.ExternalPatientId)
+	require.Empty(t, out.PID.AlternatePatientId)
+	require.Empty(t, out.PID.SSN)
+	require.Empty(t, out.PID.CountyCode)
+	require.Equal(t, IS("SPR"), out.PID.PatientAddress[0].CountyCode)
+
+	// the original is left as it was
+	require.Equal(t, ID("2.3"), msg.MSH.VersionId)
+	require.Len(t, msg.PID.InternalPatientId, 2)
+	require.Equal(t, ST("123-45-6789"), msg.PID.SSN)
+
+	report = ValidateWith(out, NewTables())
+	require.True(t, report.Valid(), report.Issues)
+}
+
+func TestConvert_Downgrade(t *testing.T) {
+	var msg ADT_A01
+	raw := strings.Replace(convertTestMessage, "|2.3\r", "|2.5.1\r", 1)
+	raw = strings.Replace(raw, "DOE^JANE|", "DOE^JANE^^^^^L^A^^^G|", 1)
+	raw = strings.Replace(raw, "19800101|F|", "19800101|N|", 1)
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	msg.PV1.AttendingDoctor[0].AssigningJurisdiction = CWE{Identifier: "IL"}
+
+	out, report, err := Convert(&msg, "2.3")
+	require.NoError(t, err)
+	require.Equal(t, ID("2.3"), out.MSH.VersionId)
+
+	require.Equal(t, []string{
+		"PID[1]-5[1].11: warning: XPN.11 cannot be represented in v2.3, dropped",
+		"PID[1]-8: warning: value 'N' is not in table 0001 in v2.3",
+		"PV1[1]-7[1].22: warning: XCN.22 cannot be represented in v2.3, dropped",
+	}, issueStrings(report))
+	require.Equal(t, XPN{FamilyName: "DOE", GivenName: "JANE", NameTypeCode: "L", NameRepresentationCode: "A"}, out.PID.PatientName[0])
+	require.Equal(t, CWE{}, out.PV1.AttendingDoctor[0].AssigningJurisdiction)
+	require.Equal(t, ID("G"), msg.PID.PatientName[0].NameAssemblyOrder)
+
+	encoded, err := Marshal(out)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), "|DOE^JANE^^^^^L^A|")
+
+	_, _, err = Convert(PID{}, "2.5")
+	require.Error(t, err)
+	_, _, err = Convert("MSH", "2.5")
+	require.Error(t, err)
+}
+
+func TestConvert_CountyCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		addresses []XAD
+		county    IS   // PID-12 after converting
+		want      []IS // PID-11 county codes after converting
+		issue     string
+	}{
+		{
+			name:   "no address",
+			county: "SPR",
+			issue:  "PID[1]-12: warning: deprecated in v2.5, kept as there is no address to move it to",
+		},
+		{
+			name:      "home address",
+			addresses: []XAD{{AddressType: "M"}, {AddressType: "H"}},
+			want:      []IS{"", "SPR"},
+			issue:     "PID[1]-12: warning: deprecated in v2.5, moved to PID-11[2].9",
+		},
+		{
+			name:      "first address",
+			addresses: []XAD{{AddressType: "M"}, {AddressType: "B"}},
+			want:      []IS{"SPR", ""},
+			issue:     "PID[1]-12: warning: deprecated in v2.5, moved to PID-11[1].9",
+		},
+		{
+			name:      "same county",
+			addresses: []XAD{{CountyCode: "SPR"}},
+			want:      []IS{"SPR"},
+			issue:     "PID[1]-12: warning: deprecated in v2.5, dropped as PID-11[1].9 holds it",
+		},
+		{
+			name:      "other county",
+			addresses: []XAD{{CountyCode: "CHI"}},
+			county:    "SPR",
+			want:      []IS{"CHI"},
+			issue:     "PID[1]-12: warning: deprecated in v2.5, kept as PID-11[1].9 holds another county",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := ADT_A01{MSH: MSH{VersionId: "2.3"}}
+			msg.PID.PatientAddress = tt.addresses
+			msg.PID.CountyCode = "SPR"
+
+			out, report, err := Convert(msg, "2.5")
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.issue}, issueStrings(report))
+			require.Equal(t, tt.county, out.PID.CountyCode)
+			var got []IS
+			for _, address := range out.PID.PatientAddress {
+				got = append(got, address.CountyCode)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvert_RoundTrip(t *testing.T) {
+	var msg ADT_A01
+	raw := strings.Replace(convertTestMessage, "|123-45-6789\r", "|123-45-6789|D1234^IL^20300101\r", 1)
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	msg.PID.CountyCode = "SPR"
+
+	upgraded, report, err := Convert(msg, "2.5.1")
+	require.NoError(t, err)
+	require.Contains(t, issueStrings(report), "PID[1]-20: warning: deprecated in v2.5.1, moved to PID-3[5]")
+	require.Equal(t, CX{
+		IdNumber:              "D1234",
+		IdentifierTypeCode:    "DL",
+		ExpirationDate:        "20300101",
+		AssigningJurisdiction: CWE{Identifier: "IL"},
+	}, upgraded.PID.InternalPatientId[4])
+	require.Equal(t, DLN{}, upgraded.PID.DriversLicenseNumber)
+
+	out, report, err := Convert(upgraded, "2.3")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"PID[1]-3[4]: warning: moved to PID-19",
+		"PID[1]-3[5]: warning: moved to PID-20",
+	}, issueStrings(report))
+
+	require.Equal(t, ID("2.3"), out.MSH.VersionId)
+	require.Equal(t, msg.PID.SSN, out.PID.SSN)
+	require.Equal(t, msg.PID.DriversLicenseNumber, out.PID.DriversLicenseNumber)
+	// PID-2, PID-4 and PID-12 stay where the upgrade moved them
+	require.Equal(t, []CX{
+		{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN"}},
+		{IdNumber: "E1", AssigningAuthority: HD{NamespaceId: "Ext"}},
+		{IdNumber: "A1", AssigningAuthority: HD{NamespaceId: "Alt"}},
+	}, out.PID.InternalPatientId)
+	require.Equal(t, CX{}, out.PID.ExternalPatientId)
+	require.Empty(t, out.PID.AlternatePatientId)
+	require.Empty(t, out.PID.CountyCode)
+	require.Equal(t, IS("SPR"), out.PID.PatientAddress[0].CountyCode)
+
+	encoded, err := Marshal(out)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), "|123^^^MRN~E1^^^Ext~A1^^^Alt|")
+	require.Contains(t, string(encoded), "|123-45-6789|D1234^IL^20300101\r")
+}
+
+func TestConvert_DowngradeLossy(t *testing.T) {
+	msg := ADT_A01{MSH: MSH{VersionId: "2.5"}}
+	msg.PID.InternalPatientId = []CX{
+		{IdNumber: "123", IdentifierTypeCode: "MR"},
+		{IdNumber: "D1234", IdentifierTypeCode: "DL", AssigningAuthority: HD{NamespaceId: "DMV"}},
+		{IdNumber: "123-45-6789", IdentifierTypeCode: "SS"},
+		{IdNumber: "987-65-4321", IdentifierTypeCode: "SS"},
+	}
+
+	out, report, err := Convert(msg, "2.4")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"PID[1]-3[3]: warning: moved to PID-19",
+		"PID[1]-3[2]: warning: moved to PID-20, dropping the components it does not have",
+	}, issueStrings(report))
+	require.Equal(t, ST("123-45-6789"), out.PID.SSN)
+	require.Equal(t, DLN{LicenseNumber: "D1234"}, out.PID.DriversLicenseNumber)
+	// only the first SSN fits PID-19
+	require.Equal(t, []CX{
+		{IdNumber: "123", IdentifierTypeCode: "MR"},
+		{IdNumber: "987-65-4321", IdentifierTypeCode: "SS"},
+	}, out.PID.InternalPatientId)
+}
+
+func TestConvert_CodedAndTimeTypes(t *testing.T) {
+	var msg ADT_A01
+	raw := strings.Replace(convertTestMessage, "19800101|F|", "19800101^D|F|", 1)
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	msg.PID.PrimaryLanguage = CE{Identifier: "en", Text: "English", CodingSystem: "ISO639", OriginalText: "english"}
+
+	// CE holds CWE's components, so they are kept when upgrading
+	out, report, err := Convert(msg, "2.7")
+	require.NoError(t, err)
+	require.Contains(t, issueStrings(report), "PID[1]-7.2: warning: TS.2 cannot be represented in v2.7, dropped")
+	require.Equal(t, TS("19800101"), out.PID.DOB)
+	require.Equal(t, msg.PID.PrimaryLanguage, out.PID.PrimaryLanguage)
+
+	// and those CE does not have are dropped when downgrading
+	out, report, err = Convert(out, "2.5")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"PID[1]-15.9: warning: CE.9 cannot be represented in v2.5, dropped",
+	}, issueStrings(report))
+	require.Equal(t, CE{Identifier: "en", Text: "English", CodingSystem: "ISO639"}, out.PID.PrimaryLanguage)
+}
+
+func issueStrings(report ValidationReport) []string {
+	var issues []string
+	for _, issue := range report.Issues {
+		issues = append(issues, issue.String())
+	}
+	return issues
+}
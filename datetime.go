@@ -0,0 +1,242 @@
+package faraday
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocation is the location of DT, TM and TS values sent without a
+// +/-ZZZZ offset, unless one is passed explicitly to their TimeIn methods.
+var DefaultLocation = time.Local
+
+// Precision is the degree of precision a date or time was sent with, from a
+// year (YYYY) down to a ten thousandth of a second (SS.SSSS).
+type Precision uint8
+
+const (
+	PrecisionYear Precision = iota + 1
+	PrecisionMonth
+	PrecisionDay
+	PrecisionHour
+	PrecisionMinute
+	PrecisionSecond
+	PrecisionTenthSecond
+	PrecisionHundredthSecond
+	PrecisionThousandthSecond
+	PrecisionTenThousandthSecond
+)
+
+func (p Precision) String() string {
+	switch p {
+	case PrecisionYear:
+		return "year"
+	case PrecisionMonth:
+		return "month"
+	case PrecisionDay:
+		return "day"
+	case PrecisionHour:
+		return "hour"
+	case PrecisionMinute:
+		return "minute"
+	case PrecisionSecond:
+		return "second"
+	case PrecisionTenthSecond:
+		return "tenth of a second"
+	case PrecisionHundredthSecond:
+		return "hundredth of a second"
+	case PrecisionThousandthSecond:
+		return "thousandth of a second"
+	case PrecisionTenThousandthSecond:
+		return "ten thousandth of a second"
+	default:
+		return fmt.Sprintf("Precision(%d)", uint8(p))
+	}
+}
+
+// Time parses the date in DefaultLocation, e.g. 2025, 202507 or 20250724.
+func (dt DT) Time() (time.Time, Precision, error) {
+	return dt.TimeIn(DefaultLocation)
+}
+
+// TimeIn parses the date in loc. Months and days which were not sent are 1.
+func (dt DT) TimeIn(loc *time.Location) (time.Time, Precision, error) {
+	if strings.ContainsAny(string(dt), ".+-") {
+		return time.Time{}, 0, fmt.Errorf("invalid DT '%s'", dt)
+	}
+	t, prec, err := parseDateTime(string(dt), loc)
+	if err != nil || prec > PrecisionDay {
+		return time.Time{}, 0, fmt.Errorf("invalid DT '%s'", dt)
+	}
+	return t, prec, nil
+}
+
+// NewDT formats t as a date of precision p, which is at most PrecisionDay.
+func NewDT(t time.Time, p Precision) DT {
+	return DT(formatDateTime(t, min(p, PrecisionDay), false))
+}
+
+// Time parses the time of day in DefaultLocation, unless it has an offset.
+// The date of the returned time is January 1st of year 0.
+func (tm TM) Time() (time.Time, Precision, error) {
+	return tm.TimeIn(DefaultLocation)
+}
+
+// TimeIn parses the time of day in loc, unless it has an offset.
+func (tm TM) TimeIn(loc *time.Location) (time.Time, Precision, error) {
+	t, prec, err := parseDateTime("00000101"+string(tm), loc)
+	if err != nil || prec < PrecisionHour {
+		return time.Time{}, 0, fmt.Errorf("invalid TM '%s'", tm)
+	}
+	return t, prec, nil
+}
+
+// NewTM formats the time of day of t with precision p (at least PrecisionHour)
+// followed by its offset.
+func NewTM(t time.Time, p Precision) TM {
+	return TM(formatDateTime(t, max(p, PrecisionHour), true)[8:])
+}
+
+// Time parses the timestamp in DefaultLocation, unless it has an offset.
+func (ts TS) Time() (time.Time, Precision, error) {
+	return ts.TimeIn(DefaultLocation)
+}
+
+// TimeIn parses the timestamp in loc, unless it has an offset, e.g.
+// 20250724121200-0500 or 20250724121200.1234+0100.
+func (ts TS) TimeIn(loc *time.Location) (time.Time, Precision, error) {
+	t, prec, err := parseDateTime(string(ts), loc)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid TS '%s'", ts)
+	}
+	return t, prec, nil
+}
+
+// NewTS formats t as a timestamp of precision p. Timestamps precise to the
+// hour or better are followed by the offset of t.
+func NewTS(t time.Time, p Precision) TS {
+	return TS(formatDateTime(t, p, p >= PrecisionHour))
+}
+
+// Time parses the date and time in DefaultLocation, unless it has an offset.
+func (dtm DTM) Time() (time.Time, Precision, error) {
+	return dtm.TimeIn(DefaultLocation)
+}
+
+// TimeIn parses the date and time in loc, unless it has an offset.
+func (dtm DTM) TimeIn(loc *time.Location) (time.Time, Precision, error) {
+	t, prec, err := parseDateTime(string(dtm), loc)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid DTM '%s'", dtm)
+	}
+	return t, prec, nil
+}
+
+// NewDTM formats t as a date and time of precision p, followed by the offset
+// of t when precise to the hour or better.
+func NewDTM(t time.Time, p Precision) DTM {
+	return DTM(formatDateTime(t, p, p >= PrecisionHour))
+}
+
+// digitPrecision maps the number of date and time digits to their precision.
+var digitPrecision = map[int]Precision{
+	4:  PrecisionYear,
+	6:  PrecisionMonth,
+	8:  PrecisionDay,
+	10: PrecisionHour,
+	12: PrecisionMinute,
+	14: PrecisionSecond,
+}
+
+// parseDateTime parses YYYY[MM[DD[HH[MM[SS[.S[S[S[S]]]]]]]]][+/-ZZZZ].
+func parseDateTime(s string, loc *time.Location) (time.Time, Precision, error) {
+	if i := strings.LastIndexAny(s, "+-"); i >= 0 {
+		zone, err := parseOffset(s[i:])
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		s, loc = s[:i], zone
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	digits, frac, hasFrac := strings.Cut(s, ".")
+	prec, ok := digitPrecision[len(digits)]
+	if !ok || !isDigits(digits) {
+		return time.Time{}, 0, fmt.Errorf("invalid date/time '%s'", s)
+	}
+	nsec := 0
+	if hasFrac {
+		if prec != PrecisionSecond || len(frac) == 0 || len(frac) > 4 || !isDigits(frac) {
+			return time.Time{}, 0, fmt.Errorf("invalid fractional seconds '%s'", frac)
+		}
+		n, _ := strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+		nsec = n
+		prec += Precision(len(frac))
+	}
+
+	part := func(from, to, def int) int {
+		if len(digits) < to {
+			return def
+		}
+		n, _ := strconv.Atoi(digits[from:to])
+		return n
+	}
+	year, month, day := part(0, 4, 0), part(4, 6, 1), part(6, 8, 1)
+	hour, minute, sec := part(8, 10, 0), part(10, 12, 0), part(12, 14, 0)
+	if month < 1 || month > 12 || hour > 23 || minute > 59 || sec > 59 {
+		return time.Time{}, 0, fmt.Errorf("invalid date/time '%s'", s)
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, sec, nsec, loc)
+	if t.Day() != day {
+		return time.Time{}, 0, fmt.Errorf("invalid date '%s'", s)
+	}
+	return t, prec, nil
+}
+
+// parseOffset parses a +/-ZZZZ offset into a fixed zone.
+func parseOffset(s string) (*time.Location, error) {
+	if len(s) != 5 || !isDigits(s[1:]) {
+		return nil, fmt.Errorf("invalid offset '%s'", s)
+	}
+	hours, _ := strconv.Atoi(s[1:3])
+	minutes, _ := strconv.Atoi(s[3:5])
+	if hours > 14 || minutes > 59 {
+		return nil, fmt.Errorf("invalid offset '%s'", s)
+	}
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset), nil
+}
+
+func isDigits(s string) bool {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatDateTime formats t as YYYYMMDDHHMMSS.SSSS cut down to precision p.
+func formatDateTime(t time.Time, p Precision, withOffset bool) string {
+	p = max(p, PrecisionYear)
+	s := t.Format("20060102150405.0000")
+	if p <= PrecisionSecond {
+		for digits, prec := range digitPrecision {
+			if prec == p {
+				s = s[:digits]
+			}
+		}
+	} else {
+		s = s[:15+int(min(p, PrecisionTenThousandthSecond)-PrecisionSecond)]
+	}
+	if withOffset {
+		s += t.Format("-0700")
+	}
+	return s
+}
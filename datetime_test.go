@@ -0,0 +1,99 @@
+package faraday
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTS_Time(t *testing.T) {
+	est := time.FixedZone("", -5*3600)
+	tests := []struct {
+		in   TS
+		want time.Time
+		prec Precision
+	}{
+		{"2025", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), PrecisionYear},
+		{"202507", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), PrecisionMonth},
+		{"20250724", time.Date(2025, 7, 24, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		{"2025072412", time.Date(2025, 7, 24, 12, 0, 0, 0, time.UTC), PrecisionHour},
+		{"202507241215", time.Date(2025, 7, 24, 12, 15, 0, 0, time.UTC), PrecisionMinute},
+		{"20250724121530", time.Date(2025, 7, 24, 12, 15, 30, 0, time.UTC), PrecisionSecond},
+		{"20250724121530.1", time.Date(2025, 7, 24, 12, 15, 30, 100_000_000, time.UTC), PrecisionTenthSecond},
+		{"20250724121530.1234", time.Date(2025, 7, 24, 12, 15, 30, 123_400_000, time.UTC), PrecisionTenThousandthSecond},
+		{"20250724121530-0500", time.Date(2025, 7, 24, 12, 15, 30, 0, est), PrecisionSecond},
+		{"20250724121530.12+0130", time.Date(2025, 7, 24, 10, 45, 30, 120_000_000, time.UTC), PrecisionHundredthSecond},
+		{"20240229", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), PrecisionDay},
+	}
+	for _, tt := range tests {
+		got, prec, err := tt.in.TimeIn(time.UTC)
+		require.NoError(t, err, tt.in)
+		require.True(t, tt.want.Equal(got), "%s: want %s, got %s", tt.in, tt.want, got)
+		require.Equal(t, tt.prec, prec, tt.in)
+	}
+
+	for _, in := range []TS{"", "202", "2025072", "20250230", "20251301", "2025072425", "20250724121560", "202507241215.1", "20250724121530.12345", "20250724121530+05", "2025-07-24"} {
+		_, _, err := in.TimeIn(time.UTC)
+		require.Error(t, err, in)
+	}
+}
+
+func TestTS_DefaultLocation(t *testing.T) {
+	defer func(loc *time.Location) { DefaultLocation = loc }(DefaultLocation)
+	DefaultLocation = time.FixedZone("", 3600)
+
+	got, _, err := TS("20250724121530").Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 7, 24, 11, 15, 30, 0, time.UTC), got.UTC())
+
+	got, _, err = TS("20250724121530+0000").Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 7, 24, 12, 15, 30, 0, time.UTC), got.UTC())
+}
+
+func TestDT_TM(t *testing.T) {
+	got, prec, err := DT("20250724").TimeIn(time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 7, 24, 0, 0, 0, 0, time.UTC), got)
+	require.Equal(t, PrecisionDay, prec)
+	for _, in := range []DT{"2025072412", "20250724-0500", "2025.1"} {
+		_, _, err := in.TimeIn(time.UTC)
+		require.Error(t, err, in)
+	}
+
+	got, prec, err = TM("1215-0500").TimeIn(time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, 17, got.UTC().Hour())
+	require.Equal(t, 15, got.Minute())
+	require.Equal(t, PrecisionMinute, prec)
+
+	_, prec, err = TM("121530.5").TimeIn(time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, PrecisionTenthSecond, prec)
+	for _, in := range []TM{"", "1", "2400", "121", "20250724"} {
+		_, _, err := in.TimeIn(time.UTC)
+		require.Error(t, err, in)
+	}
+}
+
+func TestNewTS(t *testing.T) {
+	at := time.Date(2025, 7, 24, 12, 15, 30, 123_456_789, time.FixedZone("", -5*3600))
+
+	require.Equal(t, TS("2025"), NewTS(at, PrecisionYear))
+	require.Equal(t, TS("20250724"), NewTS(at, PrecisionDay))
+	require.Equal(t, TS("2025072412-0500"), NewTS(at, PrecisionHour))
+	require.Equal(t, TS("20250724121530-0500"), NewTS(at, PrecisionSecond))
+	require.Equal(t, TS("20250724121530.123-0500"), NewTS(at, PrecisionThousandthSecond))
+	require.Equal(t, TS("20250724121530.1234-0500"), NewTS(at, PrecisionTenThousandthSecond))
+	require.Equal(t, DT("202507"), NewDT(at, PrecisionMonth))
+	require.Equal(t, DT("20250724"), NewDT(at, PrecisionSecond))
+	require.Equal(t, TM("1215-0500"), NewTM(at, PrecisionMinute))
+	require.Equal(t, TM("12-0500"), NewTM(at, PrecisionYear))
+
+	ts := NewTS(at, PrecisionTenThousandthSecond)
+	got, prec, err := ts.Time()
+	require.NoError(t, err)
+	require.True(t, at.Truncate(100*time.Microsecond).Equal(got))
+	require.Equal(t, PrecisionTenThousandthSecond, prec)
+}
@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"iter"
 	"reflect"
+	"strings"
 
 	"github.com/s-hammon/p"
 )
@@ -15,33 +17,78 @@ type MSHUnmarshaller interface {
 }
 
 type Decoder struct {
-	r io.Reader
+	r       io.Reader
+	scanner *bufio.Scanner
 
-	parsedMSH     bool
-	segmentSchema map[string]int // map of segment name to struct field index
-	delims        delimiters
+	pending     []byte // header of the next message, read past the current one
+	err         error  // sticky read error
+	parsedMSH   bool
+	delims      delimiters
+	keepEscapes bool
+	version     string // MSH-12 of the current message
 
-	hasGroup      bool
-	groupFieldIdx int
-	groupType     reflect.Type
-	groupSchema   map[string]int
+	strict      bool
+	tables      *Tables // to validate against, DefaultTables if nil
+	report      ValidationReport
+	occurrences map[string]int
+
+	batch batchState
+
+	registry *segmentRegistry // segments registered with this Decoder, if any
+
+	maxSegment int // largest segment to read, maxSegmentSize if zero
 }
 
+// maxSegmentSize is the largest segment the Decoder will read; segments such
+// as OBX may carry whole encoded documents.
+const maxSegmentSize = 16 << 20
+
 type delimiters struct {
 	field        byte
 	component    byte
 	repeat       byte
 	escape       byte
 	subcomponent byte
+	truncation   byte // as of v2.7, 0 if the message has none
+}
+
+// newDelimiters takes the field separator and the 4 encoding characters of
+// MSH-2, or 5 with the truncation character of v2.7.
+func newDelimiters(fieldSep byte, encChars []byte) delimiters {
+	d := delimiters{
+		field:        fieldSep,
+		component:    encChars[0],
+		repeat:       encChars[1],
+		escape:       encChars[2],
+		subcomponent: encChars[3],
+	}
+	if len(encChars) > 4 {
+		d.truncation = encChars[4]
+	}
+	return d
 }
 
 func (d delimiters) toSlice() []byte {
-	return []byte{
+	chars := []byte{
 		d.component,    // [0:1]
 		d.repeat,       // [1:2]
 		d.escape,       // [2:3]
 		d.subcomponent, // [3:4]
 	}
+	if d.truncation != 0 {
+		chars = append(chars, d.truncation) // [4:5]
+	}
+	return chars
+}
+
+// encodingCharacters returns MSH-2 from a header segment (MSH, FHS or BHS)
+// following its name, which starts with the field separator: the 4 encoding
+// characters, and the truncation character if there is one.
+func encodingCharacters(header []byte) []byte {
+	if len(header) > 5 && header[5] != header[0] && header[5] != '\r' {
+		return header[1:6]
+	}
+	return header[1:5]
 }
 
 func NewDecoder(r io.Reader) *Decoder {
@@ -51,6 +98,26 @@ func NewDecoder(r io.Reader) *Decoder {
 	}
 }
 
+// KeepEscapes makes the Decoder leave escape sequences (e.g. \F\ or \.br\)
+// in decoded values as-is instead of replacing them with the text they stand
+// for.
+func (dec *Decoder) KeepEscapes() {
+	dec.keepEscapes = true
+}
+
+// Strict makes the Decoder validate every message it decodes (see Validate).
+// Decode then returns a *ValidationError if the message has errors, which
+// additionally covers fields holding more components than their type allows.
+func (dec *Decoder) Strict() {
+	dec.strict = true
+}
+
+// UseTables makes a strict Decoder check coded values against tables instead
+// of DefaultTables.
+func (dec *Decoder) UseTables(tables *Tables) {
+	dec.tables = tables
+}
+
 func (dec *Decoder) Decode(val any) error {
 	v := reflect.ValueOf(val)
 	if v.Kind() != reflect.Pointer || v.IsNil() {
@@ -65,114 +132,300 @@ func (dec *Decoder) Decode(val any) error {
 
 		elem = elem.Elem()
 	}
-	// TODO: also implement support for map[string]any
-	if elem.Kind() != reflect.Struct {
-		return fmt.Errorf("Decoder: not a pointer to struct (got %T)", val)
+	isMap := elem.Type() == reflect.TypeFor[map[string]any]()
+	if elem.Kind() != reflect.Struct && !isMap {
+		return fmt.Errorf("Decoder: not a pointer to struct or map[string]any (got %T)", val)
+	}
+	if !elem.CanSet() {
+		return fmt.Errorf("Decode: cannot set value of type %s", elem.Type())
 	}
 
-	dec.defineSegmentSchema(&elem)
-
-	scanner := bufio.NewScanner(dec.r)
-	scanner.Split(SegmentSplitter('\r'))
-
-	if !scanner.Scan() {
-		return io.EOF
+	segments, err := dec.readMessage()
+	if err == io.EOF {
+		return err
 	}
-	header := scanner.Bytes()
+	if err != nil {
+		return fmt.Errorf("Decode: %w", err)
+	}
+	if err := dec.checkHeader(segments); err != nil {
+		return fmt.Errorf("Decode: %w", err)
+	}
+	return dec.decodeMessage(elem, segments)
+}
 
+// checkHeader makes sure a message starts with MSH and takes its delimiters.
+func (dec *Decoder) checkHeader(segments [][]byte) error {
+	header := segments[0]
 	if len(header) < 8 || string(header[:3]) != "MSH" {
-		return fmt.Errorf("Decode: expected first segment to be MSH")
+		return fmt.Errorf("expected first segment to be MSH")
 	}
+	dec.delims = newDelimiters(header[3], encodingCharacters(header[3:]))
+	_, _, _, dec.version = dec.peekHeader(header)
+	return nil
+}
 
-	fieldSep := header[3]
-	encChars := header[4:8]
-
-	dec.delims = delimiters{
-		field:        fieldSep,
-		component:    encChars[0],
-		repeat:       encChars[1],
-		escape:       encChars[2],
-		subcomponent: encChars[3],
+// decodeMessage decodes the segments of a message into elem, which is a
+// struct, a Message or a map[string]any.
+func (dec *Decoder) decodeMessage(elem reflect.Value, segments [][]byte) error {
+	isMap := elem.Type() == reflect.TypeFor[map[string]any]()
+	if isMap || elem.Type() == reflect.TypeFor[Message]() {
+		msg := dec.decodeTree(segments)
+		if isMap {
+			elem.Set(reflect.ValueOf(msg.Map()))
+		} else {
+			elem.Set(reflect.ValueOf(msg))
+		}
+		return nil
 	}
 
-	if idx, ok := dec.segmentSchema["MSH"]; ok {
-		field := elem.Field(idx)
-		ptr := reflect.New(field.Type())
+	dec.report = ValidationReport{}
+	dec.occurrences = map[string]int{}
+	elem.SetZero()
 
-		u, ok := ptr.Interface().(MSHUnmarshaller)
-		if !ok {
-			return fmt.Errorf("MSH field type %s does not implement UnmarshalHeader([]byte)", field)
-		}
-		if err := u.UnmarshalHeader(header[3:]); err != nil {
-			return fmt.Errorf("MSH.UnmarshalHeader: %w", err)
+	state := &groupState{schema: dec.segments().schemaOf(elem.Type()), val: elem}
+	if _, err := dec.decodeGroup(state, segments, 0, nil); err != nil {
+		return fmt.Errorf("Decode: %w", err)
+	}
+
+	if dec.strict {
+		report := validate(elem, dec.segments(), p.Coalesce(dec.tables, DefaultTables))
+		report.Issues = append(dec.report.Issues, report.Issues...)
+		if !report.Valid() {
+			return &ValidationError{Report: report}
 		}
-		field.Set(ptr.Elem())
 	}
+	return nil
+}
 
-	var (
-		groupSlice, activeGroup reflect.Value
-	)
-	if dec.hasGroup {
-		groupSlice = reflect.MakeSlice(elem.Field(dec.groupFieldIdx).Type(), 0, 0)
+// Messages returns an iterator over the remaining messages of the Decoder's
+// stream, each decoded into a new T. A message which fails to decode is
+// yielded along with its error; iteration ends after the last message or on a
+// read error.
+func Messages[T any](dec *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			var msg T
+			err := dec.Decode(&msg)
+			if err == io.EOF {
+				return
+			}
+			if !yield(msg, err) || (err != nil && dec.err != nil) {
+				return
+			}
+		}
 	}
+}
 
-	for scanner.Scan() {
-		data := scanner.Bytes()
-		if len(data) < 3 {
+// More reports whether there is another message to decode.
+func (dec *Decoder) More() bool {
+	for {
+		if dec.pending != nil {
+			if !isEnvelopeSegment(dec.pending) || dec.batch.err != nil {
+				return true
+			}
+			seg := dec.pending
+			dec.pending = nil
+			// a count mismatch is reported by the next call to Decode
+			dec.batch.err = dec.decodeEnvelope(seg)
 			continue
 		}
-		name := string(data[:3])
+		if dec.err != nil || !dec.scan() {
+			return dec.batch.err != nil
+		}
+		dec.pending = dec.segment()
+	}
+}
+
+// readMessage reads the segments of the next message in the stream. A message
+// ends where the next one's MSH segment begins, which is kept for the next
+// call, or at a batch header or trailer segment (see batch.go).
+func (dec *Decoder) readMessage() ([][]byte, error) {
+	if err := dec.batch.err; err != nil {
+		dec.batch.err = nil
+		return nil, err
+	}
 
-		if idx, ok := dec.groupSchema[name]; ok {
-			if name == exportSegmentName(dec.groupType.Field(0)) && activeGroup.IsValid() {
-				groupSlice = reflect.Append(groupSlice, activeGroup)
-				activeGroup = reflect.Value{}
+	var segments [][]byte
+	for {
+		seg := dec.pending
+		dec.pending = nil
+		if seg == nil {
+			if !dec.scan() {
+				break
 			}
-			if !activeGroup.IsValid() {
-				activeGroup = reflect.New(dec.groupType).Elem()
+			if seg = dec.segment(); seg == nil {
+				continue
 			}
-			if err := decodeSegmentInto(activeGroup.Field(idx), data[4:], dec.delims); err != nil {
-				return fmt.Errorf("decode group %s: %w", name, err)
+		}
+		if isEnvelopeSegment(seg) {
+			if len(segments) > 0 {
+				dec.pending = seg
+				break
 			}
-		} else if idx, ok := dec.segmentSchema[name]; ok {
-			field := elem.Field(idx)
-			if err := decodeSegmentInto(field, data[4:], dec.delims); err != nil {
-				return fmt.Errorf("decode top-level %s: %w", name, err)
+			if err := dec.decodeEnvelope(seg); err != nil {
+				return nil, err
 			}
+			continue
 		}
-	}
-	if dec.hasGroup {
-		if activeGroup.IsValid() {
-			groupSlice = reflect.Append(groupSlice, activeGroup)
+		if len(segments) > 0 && string(seg[:3]) == "MSH" {
+			dec.pending = seg
+			break
 		}
-		elem.Field(dec.groupFieldIdx).Set(groupSlice)
+		segments = append(segments, seg)
 	}
-	return nil
+	if len(segments) > 0 {
+		dec.batch.messages++
+		return segments, nil
+	}
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	return nil, io.EOF
+}
+
+func (dec *Decoder) scan() bool {
+	if dec.err != nil {
+		return false
+	}
+	if dec.scanner == nil {
+		dec.scanner = bufio.NewScanner(dec.r)
+		dec.scanner.Buffer(nil, orDefault(dec.maxSegment, maxSegmentSize))
+		dec.scanner.Split(SegmentSplitter('\r'))
+	}
+	if dec.scanner.Scan() {
+		return true
+	}
+	dec.err = dec.scanner.Err()
+	return false
 }
 
-func (dec *Decoder) defineSegmentSchema(val *reflect.Value) {
-	dec.segmentSchema = make(map[string]int)
-	for i := range val.NumField() {
-		field := val.Type().Field(i)
-		if name := exportSegmentName(field); name != "" {
-			dec.segmentSchema[name] = i
-			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
-				dec.groupFieldIdx = i
-				dec.groupType = field.Type.Elem()
-				dec.groupSchema = make(map[string]int)
-				for j := range dec.groupType.NumField() {
-					sf := dec.groupType.Field(j)
-					if seg := exportSegmentName(sf); seg != "" {
-						dec.groupSchema[seg] = j
-					}
-				}
-				dec.hasGroup = true
+// segment returns a copy of the current segment, with any leading line feeds
+// (from CR LF terminated files) removed, or nil if it is too short to hold a
+// segment name.
+func (dec *Decoder) segment() []byte {
+	seg := bytes.TrimLeft(dec.scanner.Bytes(), "\n")
+	if len(seg) < 3 {
+		return nil
+	}
+	return bytes.Clone(seg)
+}
+
+func (dec *Decoder) decodeTree(segments [][]byte) Message {
+	msg := Message{Segments: make([]Segment, len(segments))}
+	for i, seg := range segments {
+		msg.Segments[i] = parseSegmentTree(seg, dec.delims, dec.keepEscapes)
+	}
+	return msg
+}
+
+// groupState tracks where we are in the grammar of a message or group while
+// decoding it.
+type groupState struct {
+	schema *structSchema
+	val    reflect.Value
+	cursor int // index of the member the next segment may start at
+}
+
+// decodeGroup decodes segments into the message or group held by state,
+// starting at segments[pos], until it meets a segment which belongs to one of
+// its parents. Segments which fit nowhere in the grammar are skipped. It
+// returns the position of the first segment it did not consume.
+func (dec *Decoder) decodeGroup(state *groupState, segments [][]byte, pos int, parents []*groupState) (int, error) {
+	for pos < len(segments) {
+		name := string(segments[pos][:3])
+
+		j, ok := state.schema.accepts(name, state.cursor)
+		if !ok {
+			if anyAccepts(parents, name) {
+				return pos, nil
+			}
+			pos++
+			continue
+		}
+
+		member := state.schema.members[j]
+		field := state.val.Field(member.index)
+		state.cursor = j
+		if !member.repeats {
+			state.cursor++
+		}
+
+		if !member.group {
+			if err := dec.decodeSegment(field, member.name, segments[pos]); err != nil {
+				return pos, fmt.Errorf("decode %s: %w", name, err)
 			}
+			pos++
+			continue
+		}
+
+		child := &groupState{schema: member.schema, val: field}
+		if member.repeats {
+			child.val = reflect.New(member.typ).Elem()
+		}
+		var err error
+		if pos, err = dec.decodeGroup(child, segments, pos, append(parents, state)); err != nil {
+			return pos, err
+		}
+		if member.repeats {
+			field.Set(reflect.Append(field, child.val))
 		}
 	}
+	return pos, nil
 }
 
-func decodeSegmentInto(field reflect.Value, raw []byte, delims delimiters) error {
+func anyAccepts(states []*groupState, name string) bool {
+	for _, state := range states {
+		if _, ok := state.schema.accepts(name, state.cursor); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (dec *Decoder) decodeSegment(field reflect.Value, name string, segment []byte) error {
+	typ := field.Type()
+	if field.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	if name != "MSH" {
+		if dec.strict {
+			dec.occurrences[name]++
+			loc := Location{Segment: name, Occurrence: dec.occurrences[name]}
+			validateRawSegment(&dec.report, loc, typ, segmentFieldsData(segment), dec.delims, dec.version)
+		}
+		return decodeSegmentInto(field, segmentFieldsData(segment), dec.delims, dec.keepEscapes)
+	}
+
+	ptr := reflect.New(typ)
+	if h, ok := ptr.Interface().(headerDecoder); ok {
+		if err := h.decodeHeader(segment[3:], dec.keepEscapes); err != nil {
+			return fmt.Errorf("MSH.UnmarshalHeader: %w", err)
+		}
+	} else if u, ok := ptr.Interface().(MSHUnmarshaller); ok {
+		if err := u.UnmarshalHeader(segment[3:]); err != nil {
+			return fmt.Errorf("MSH.UnmarshalHeader: %w", err)
+		}
+	} else {
+		return fmt.Errorf("MSH field type %s does not implement UnmarshalHeader([]byte)", typ)
+	}
+	if field.Kind() == reflect.Slice {
+		field.Set(reflect.Append(field, ptr.Elem()))
+	} else {
+		field.Set(ptr.Elem())
+	}
+	return nil
+}
+
+// segmentFieldsData strips the segment name and the first field separator.
+func segmentFieldsData(segment []byte) []byte {
+	if len(segment) <= 4 {
+		return nil
+	}
+	return segment[4:]
+}
+
+func decodeSegmentInto(field reflect.Value, raw []byte, delims delimiters, keepEscapes bool) error {
 	isSlice := field.Kind() == reflect.Slice
 	typ := field.Type()
 	if isSlice {
@@ -186,7 +439,7 @@ func decodeSegmentInto(field reflect.Value, raw []byte, delims delimiters) error
 		fVal := segVal.Field(i)
 		spec := NewFieldSpec(uint8(i+1), fVal)
 		spec.ParseTag(segVal.Type().Field(i).Tag.Get("hl7"))
-		if err := spec.parse(rawFields[i], delims.toSlice()); err != nil {
+		if err := spec.parse(rawFields[i], delims, keepEscapes); err != nil {
 			return err
 		}
 		fVal.Set(spec.Val)
@@ -200,14 +453,197 @@ func decodeSegmentInto(field reflect.Value, raw []byte, delims delimiters) error
 	return nil
 }
 
-func exportSegmentName(field reflect.StructField) string {
-	name := p.Coalesce(field.Tag.Get("hl7"), field.Name)
-	if _, ok := SegmentTypes[name]; ok {
+// exportSegmentName returns the name of the segment a struct field maps to,
+// taken from a bare name in its hl7 tag, its (element) type or its field name.
+func (r *segmentRegistry) exportSegmentName(field reflect.StructField) string {
+	typ := field.Type
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	tagName := parseSegmentTag(field.Tag.Get("hl7")).name
+	if r.known(tagName) {
+		return tagName
+	}
+	if name, ok := r.nameOf(typ); ok {
 		return name
 	}
+	for _, name := range []string{typ.Name(), field.Name} {
+		if r.known(name) {
+			return name
+		}
+	}
 	return ""
 }
 
+// segmentTag holds the options of an hl7 tag placed on a segment or group
+// field, e.g. `hl7:"ORC,opt=R"`.
+type segmentTag struct {
+	name        string
+	optionality optionality
+}
+
+func parseSegmentTag(tag string) segmentTag {
+	st := segmentTag{optionality: Optional}
+	for part := range strings.SplitSeq(tag, ",") {
+		part = strings.TrimSpace(part)
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			st.name = p.Coalesce(st.name, part)
+			continue
+		}
+		if strings.TrimSpace(k) == "opt" {
+			st.optionality = fromString(strings.TrimSpace(v))
+		}
+	}
+	return st
+}
+
+// segmentField describes a message (or group) struct field holding either a
+// segment or a segment group, as well as slices of either.
+type segmentField struct {
+	index       int
+	name        string       // segment name, empty for groups
+	typ         reflect.Type // element type if the field is a slice
+	repeats     bool
+	group       bool
+	optionality optionality
+}
+
+// segmentFields lists the segment and group fields of a message or group
+// struct type in declaration order. Fields that are neither are skipped.
+func (r *segmentRegistry) segmentFields(t reflect.Type) []segmentField {
+	var fields []segmentField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		field := segmentField{
+			index:       i,
+			typ:         sf.Type,
+			optionality: parseSegmentTag(sf.Tag.Get("hl7")).optionality,
+		}
+		if field.typ.Kind() == reflect.Slice {
+			field.typ = field.typ.Elem()
+			field.repeats = true
+		}
+		if field.typ.Kind() != reflect.Struct {
+			continue
+		}
+		if r.isGroupType(field.typ, map[reflect.Type]bool{}) {
+			field.group = true
+		} else if field.name = r.exportSegmentName(sf); field.name == "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// isGroupType reports whether t is a segment group, i.e. a struct which is not
+// itself a segment but contains segment (or nested group) fields.
+func (r *segmentRegistry) isGroupType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t.Kind() != reflect.Struct || seen[t] || r.isSegmentType(t) {
+		return false
+	}
+	seen[t] = true
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		typ := sf.Type
+		if typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+		if r.exportSegmentName(sf) != "" || r.isGroupType(typ, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// structSchema is the grammar of a message or group struct: its segment and
+// group members in the order they may appear.
+type structSchema struct {
+	typ     reflect.Type
+	members []schemaMember
+}
+
+type schemaMember struct {
+	segmentField
+	schema *structSchema // nil for segments
+}
+
+// schemaOf returns the schema of a message or group struct type with the
+// standard and globally registered segments.
+func schemaOf(t reflect.Type) *structSchema {
+	return defaultSegments.schemaOf(t)
+}
+
+func (r *segmentRegistry) schemaOf(t reflect.Type) *structSchema {
+	// registering a segment changes the version, leaving older schemas behind
+	key := schemaKey{t, r.version()}
+	if s, ok := r.schemas.Load(key); ok {
+		return s.(*structSchema)
+	}
+	s := r.newStructSchema(t, map[reflect.Type]*structSchema{})
+	r.schemas.Store(key, s)
+	return s
+}
+
+func (r *segmentRegistry) newStructSchema(t reflect.Type, seen map[reflect.Type]*structSchema) *structSchema {
+	if s, ok := seen[t]; ok {
+		return s
+	}
+	s := &structSchema{typ: t}
+	seen[t] = s
+	for _, field := range r.segmentFields(t) {
+		member := schemaMember{segmentField: field}
+		if field.group {
+			member.schema = r.newStructSchema(field.typ, seen)
+		}
+		s.members = append(s.members, member)
+	}
+	return s
+}
+
+// starts reports whether a segment can open a new instance of the group.
+// That is the case for its members up to (and including) the first required
+// one.
+func (s *structSchema) starts(name string) bool {
+	for _, member := range s.members {
+		if member.matches(name) {
+			return true
+		}
+		if member.optionality == Required {
+			break
+		}
+	}
+	return false
+}
+
+// accepts returns the index of the first member at or after from which the
+// segment can be decoded into.
+func (s *structSchema) accepts(name string, from int) (int, bool) {
+	for j := from; j < len(s.members); j++ {
+		if s.members[j].matches(name) {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func (m schemaMember) matches(name string) bool {
+	if m.group {
+		return m.schema.starts(name)
+	}
+	return m.name == name
+}
+
 func SegmentSplitter(delim byte) bufio.SplitFunc {
 	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		if i := bytes.IndexByte(data, delim); i >= 0 {
@@ -2,7 +2,11 @@ package faraday
 
 import (
 	"bytes"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -32,10 +36,10 @@ func TestDecoder_MultipleOBX(t *testing.T) {
 
 	require.Len(t, order.OBX, 2)
 	require.Equal(t, CE{Identifier: "WBC", Text: "White Blood Cells"}, order.OBX[0].ObservationIdentifier)
-	require.Equal(t, FT("5.4"), order.OBX[0].ObservationValue)
+	require.Equal(t, []FT{"5.4"}, order.OBX[0].ObservationValue)
 
 	require.Equal(t, CE{Identifier: "HGB", Text: "Hemoglobin"}, order.OBX[1].ObservationIdentifier)
-	require.Equal(t, FT("13.7"), order.OBX[1].ObservationValue)
+	require.Equal(t, []FT{"13.7"}, order.OBX[1].ObservationValue)
 }
 
 func TestDecoder_MultipleORC(t *testing.T) {
@@ -59,6 +63,18 @@ func TestDecoder_MultipleORC(t *testing.T) {
 	require.Equal(t, SI("2"), msg.Orders[1].OBR.SetId)
 }
 
+func TestDecoder_RepetitionOverflow(t *testing.T) {
+	raw := []byte("MSH|^~\\&|SendingApp|SendingFac|ReceivingApp||20250724000008||ORM^O01|MSG00005|T|2.3\r" +
+		"ORC|NW|125||||||||||||(555)111-1111~(555)222-2222~(555)333-3333\r")
+
+	var msg struct {
+		MSH MSH
+		ORC ORC
+	}
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+	require.Equal(t, []XTN{{Number: "(555)111-1111"}, {Number: "(555)222-2222"}, {Number: "(555)333-3333"}}, msg.ORC.CallbackPhoneNumber)
+}
+
 func TestDecoder_RepeatPID(t *testing.T) {
 	raw := []byte("MSH|^~\\&|SendingApp|SendingFac|ReceivingApp|ReceivingFac|20250724000001||ADT^A02|MSG00002|P|2.3\r" +
 		"EVN|A02|20250724000001\r" +
@@ -72,9 +88,15 @@ func TestDecoder_RepeatPID(t *testing.T) {
 	err := NewDecoder(bytes.NewReader(raw)).Decode(&adt)
 	require.NoError(t, err)
 	require.Equal(t,
-		CX{
-			IdNumber:          "W02257226",
-			AssigningFacility: HD{NamespaceId: "SendingFac"},
+		[]CX{
+			{
+				IdNumber:          "W02257226",
+				AssigningFacility: HD{NamespaceId: "SendingFac"},
+			},
+			{
+				IdNumber:          "987654321",
+				AssigningFacility: HD{NamespaceId: "PN"},
+			},
 		},
 		adt.PID.InternalPatientId,
 	)
@@ -156,31 +178,31 @@ func TestDecoder_ADT(t *testing.T) {
 		PID{
 			SetId:             SI("1"),
 			ExternalPatientId: CX{IdNumber: ST("W01222379")},
-			InternalPatientId: CX{
+			InternalPatientId: []CX{{
 				IdNumber:          ST("W02257226"),
 				AssigningFacility: HD{NamespaceId: IS("SendingFac")},
-			},
-			PatientName: XPN{
+			}},
+			PatientName: []XPN{{
 				FamilyName: ST("DOE"),
 				GivenName:  ST("JANE"),
-			},
+			}},
 			DOB: TS("19910101"),
 			Sex: IS("F"),
-			PatientAddress: XAD{
+			PatientAddress: []XAD{{
 				StreetAddress: ST("123 MAIN ST"),
 				City:          ST("ANYWHERE"),
 				State:         ST("TX"),
 				Zip:           ST("12345"),
 				Country:       ID("USA"),
-			},
-			HomePhoneNumber: XTN{
+			}},
+			HomePhoneNumber: []XTN{{
 				Number:                   TN("(999)123-4567"),
 				TelecommunicationUseCode: ID("PRN"),
-			},
-			WorkPhoneNumber: XTN{
+			}},
+			WorkPhoneNumber: []XTN{{
 				Number:                   TN("(123)456-7890"),
 				TelecommunicationUseCode: ID("WPN"),
-			},
+			}},
 			Religion:              IS("CHR^Christian"),
 			PatientAccountNumber:  CX{IdNumber: ST("W182254551")},
 			SSN:                   ST("987-65-4321"),
@@ -196,12 +218,12 @@ func TestDecoder_ADT(t *testing.T) {
 				PointOfCare: IS("AER"),
 				Room:        IS("Acme ER"),
 			},
-			AttendingDoctor: XCN{
+			AttendingDoctor: []XCN{{
 				IdNumber:   ST("GRAJOS"),
 				FamilyName: ST("Graham"),
 				GivenName:  ST("Joshua"),
 				Degree:     ST("M.D."),
-			},
+			}},
 			VisitNumber:   CX{IdNumber: ST("W182254551")},
 			AdmitDateTime: TS("20250723234200"),
 		},
@@ -247,3 +269,171 @@ func BenchmarkDecoder_LargeORU(b *testing.B) {
 		}
 	}
 }
+
+func TestDecoder_NestedGroupsORM(t *testing.T) {
+	raw := []byte("MSH|^~\\&|SendingApp|SendingFac|ReceivingApp||20250724000008||ORM^O01|MSG00005|P|2.3\r" +
+		"NTE|1||message note\r" +
+		"PID|1||123456^^^MRN||DOE^JOHN\r" +
+		"PV1|1|E\r" +
+		"PV2|||^chest pain\r" +
+		"IN1|1|PLAN1|INS1\r" +
+		"AL1|1||PCN^Penicillin\r" +
+		"AL1|2||LTX^Latex\r" +
+		"ORC|NW|1001\r" +
+		"OBR|1|1001||CBC^Complete Blood Count\r" +
+		"NTE|1||fasting\r" +
+		"DG1|1||R07.9\r" +
+		"OBX|1|ST|TEMP^Temperature||98.6\r" +
+		"NTE|1||oral\r" +
+		"OBX|2|ST|PULSE^Pulse||72\r" +
+		"ORC|NW|1002\r" +
+		"OBR|2|1002||BMP^Basic Metabolic Panel\r")
+
+	var msg ORM_O01
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+
+	require.Equal(t, ID("O01"), msg.MSH.MessageType.Event)
+	require.Len(t, msg.NTE, 1)
+	require.Equal(t, []CX{{IdNumber: "123456", AssigningAuthority: HD{NamespaceId: "MRN"}}}, msg.Patient.PID.InternalPatientId)
+	require.Equal(t, IS("E"), msg.Patient.Visit.PV1.PatientClass)
+	require.Equal(t, CE{Text: "chest pain"}, msg.Patient.Visit.PV2.AdmitReason)
+	require.Len(t, msg.Patient.Insurance, 1)
+	require.Equal(t, CE{Identifier: "PLAN1"}, msg.Patient.Insurance[0].IN1.PlanId)
+	require.Len(t, msg.Patient.AL1, 2)
+
+	require.Len(t, msg.Order, 2)
+	first := msg.Order[0]
+	require.Equal(t, ST("1001"), first.ORC.PlacerOrderNumber.EntityIdentifier)
+	require.Equal(t, CE{Identifier: "CBC", Text: "Complete Blood Count"}, first.Details.OBR.UniversalServiceID)
+	require.Equal(t, []FT{"fasting"}, first.Details.NTE[0].Comment)
+	require.Len(t, first.Details.DG1, 1)
+	require.Len(t, first.Details.Results, 2)
+	require.Equal(t, []FT{"98.6"}, first.Details.Results[0].OBX.ObservationValue)
+	require.Equal(t, []FT{"oral"}, first.Details.Results[0].NTE[0].Comment)
+	require.Empty(t, first.Details.Results[1].NTE)
+
+	second := msg.Order[1]
+	require.Equal(t, ID("NW"), second.ORC.OrderControl)
+	require.Equal(t, CE{Identifier: "BMP", Text: "Basic Metabolic Panel"}, second.Details.OBR.UniversalServiceID)
+	require.Empty(t, second.Details.Results)
+}
+
+func TestDecoder_NestedGroupsORU(t *testing.T) {
+	raw := []byte("MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|MSG00006|P|2.3\r" +
+		"PID|1||111^^^MRN||DOE^JANE\r" +
+		"PV1|1|O\r" +
+		"OBR|1|A1||GLU^Glucose\r" +
+		"OBX|1|NM|GLU^Glucose||98|mg/dL\r" +
+		"ORC|RE|A2\r" +
+		"OBR|2|A2||K^Potassium\r" +
+		"OBX|1|NM|K^Potassium||4.1|mmol/L\r" +
+		"ZXY|unknown|segment\r" +
+		"NTE|1||hemolyzed\r" +
+		"PID|2||222^^^MRN||DOE^JOHN\r" +
+		"OBR|1|B1||NA^Sodium\r" +
+		"OBX|1|NM|NA^Sodium||139|mmol/L\r")
+
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+
+	require.Len(t, msg.Results, 2)
+	jane := msg.Results[0]
+	require.Equal(t, []XPN{{FamilyName: "DOE", GivenName: "JANE"}}, jane.Patient.PID.PatientName)
+	require.Equal(t, IS("O"), jane.Patient.Visit.PV1.PatientClass)
+	require.Len(t, jane.Order, 2)
+	require.Equal(t, ORC{}, jane.Order[0].ORC)
+	require.Equal(t, CE{Identifier: "GLU", Text: "Glucose"}, jane.Order[0].OBR.UniversalServiceID)
+	require.Len(t, jane.Order[0].Results, 1)
+	require.Equal(t, ID("RE"), jane.Order[1].ORC.OrderControl)
+	require.Len(t, jane.Order[1].Results, 1)
+	require.Equal(t, []FT{"hemolyzed"}, jane.Order[1].Results[0].NTE[0].Comment)
+
+	john := msg.Results[1]
+	require.Equal(t, SI("2"), john.Patient.PID.SetId)
+	require.Len(t, john.Order, 1)
+	require.Equal(t, []FT{"139"}, john.Order[0].Results[0].OBX.ObservationValue)
+}
+
+func TestDecoder_Stream(t *testing.T) {
+	raw := "MSH|^~\\&|App|Fac|||20250724000001||ADT^A01|MSG1|P|2.3\r\n" +
+		"PID|1||111||DOE^JANE\r\n" +
+		"MSH|^~\\&|App|Fac|||20250724000002||ADT^A08|MSG2|P|2.3\r\n" +
+		"PID|1||222||DOE^JOHN\r\n" +
+		"MSH|^~\\&|App|Fac|||20250724000003||ADT^A03|MSG3|P|2.3\r\n" +
+		"PID|1||333||ROE^RICHARD\r\n"
+
+	type message struct {
+		MSH MSH
+		PID PID
+	}
+
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(raw)))
+	var ids []ST
+	for dec.More() {
+		var msg message
+		require.NoError(t, dec.Decode(&msg))
+		require.Len(t, msg.PID.InternalPatientId, 1)
+		ids = append(ids, msg.MSH.MessageControlId)
+	}
+	require.Equal(t, []ST{"MSG1", "MSG2", "MSG3"}, ids)
+
+	var msg message
+	require.ErrorIs(t, dec.Decode(&msg), io.EOF)
+	require.ErrorIs(t, dec.Decode(&msg), io.EOF)
+}
+
+func TestMessages(t *testing.T) {
+	raw := "MSH|^~\\&|App|Fac|||20250724000001||ADT^A01|MSG1|P|2.3\r" +
+		"PID|1||111||DOE^JANE\r" +
+		"MSH|^~\\&|App|Fac|||20250724000002||ADT^A08|MSG2|P|2.3\r" +
+		"PID|1||222\r" +
+		"MSH|^~\\&|App|Fac|||20250724000003||ADT^A03|MSG3|P|2.3\r" +
+		"PID|1||333||ROE^RICHARD\r"
+
+	type message struct {
+		MSH MSH
+		PID PID
+	}
+
+	var (
+		names []ST
+		errs  int
+	)
+	dec := NewDecoder(strings.NewReader(raw))
+	dec.Strict()
+	for msg, err := range Messages[message](dec) {
+		if err != nil {
+			errs++
+			continue
+		}
+		names = append(names, msg.PID.PatientName[0].GivenName)
+	}
+	require.Equal(t, []ST{"JANE", "RICHARD"}, names)
+	require.Equal(t, 1, errs)
+
+	var count int
+	for range Messages[message](NewDecoder(strings.NewReader(raw))) {
+		count++
+		break
+	}
+	require.Equal(t, 1, count)
+}
+
+func TestMessages_ReadError(t *testing.T) {
+	raw := "MSH|^~\\&|App|Fac|||20250724000001||ADT^A01|MSG1|P|2.3\rPID|1||111||DOE^JANE\r"
+	r := io.MultiReader(strings.NewReader(raw), iotest.ErrReader(errors.New("connection reset")))
+
+	var (
+		ids []ST
+		err error
+	)
+	for msg, e := range Messages[struct{ MSH MSH }](NewDecoder(r)) {
+		if e != nil {
+			err = e
+			continue
+		}
+		ids = append(ids, msg.MSH.MessageControlId)
+	}
+	require.Equal(t, []ST{"MSG1"}, ids)
+	require.ErrorContains(t, err, "connection reset")
+}
@@ -0,0 +1,116 @@
+/*
+This module contains the standard for segments found in medical records
+messages. Primarily, MDMs.
+*/
+package faraday
+
+import (
+	"slices"
+	"strings"
+)
+
+// The standard TXA segment
+type TXA struct {
+	SetId                         SI `hl7:"opt=R"`
+	DocumentType                  IS `hl7:"opt=R"`
+	DocumentContentPresentation   ID `hl7:"opt=C,tbl=0191"`
+	ActivityDateTime              TS
+	PrimaryActivityProvider       []XCN `hl7:"opt=C,rep=Y"`
+	OriginationDateTime           TS
+	TranscriptionDateTime         TS    `hl7:"opt=C"`
+	EditDateTime                  []TS  `hl7:"rep=Y"`
+	Originator                    []XCN `hl7:"rep=Y"`
+	AssignedDocumentAuthenticator []XCN `hl7:"rep=Y"`
+	Transcriptionist              XCN   `hl7:"opt=C"`
+	UniqueDocumentNumber          EI    `hl7:"opt=R"`
+	ParentDocumentNumber          ST    `hl7:"opt=C"`
+	PlacerOrderNumber             []EI  `hl7:"rep=Y"`
+	FillerOrderNumber             EI
+	UniqueDocumentFileName        ST
+	DocumentCompletionStatus      ID    `hl7:"opt=R,tbl=0271"`
+	DocumentConfidentialityStatus ID    `hl7:"tbl=0272"`
+	DocumentAvailabilityStatus    ID    `hl7:"tbl=0273"`
+	DocumentStorageStatus         ID    `hl7:"tbl=0275"`
+	DocumentChangeReason          ST    `hl7:"opt=C"`
+	AuthenticationPerson          []PPN `hl7:"rep=Y"`
+	DistributedCopies             []XCN `hl7:"rep=Y"`
+}
+
+// Document is a document of an MDM message: the status and identity of the
+// document from its TXA, and its text.
+type Document struct {
+	Type                  IS
+	UniqueDocumentNumber  EI
+	ParentDocumentNumber  ST
+	CompletionStatus      ID // HL7 0271
+	ConfidentialityStatus ID // HL7 0272
+	AvailabilityStatus    ID // HL7 0273
+	StorageStatus         ID // HL7 0275
+	Authenticators        []XCN
+	Authentication        []PPN // who authenticated the document, and when
+	Text                  string
+}
+
+// Document returns the document the message notifies of, which has no text.
+func (msg MDM_T01) Document() Document {
+	return newDocument(msg.TXA, nil)
+}
+
+// Document returns the document of the message, with its text assembled from
+// the OBX segments (see DocumentText).
+func (msg MDM_T02) Document() Document {
+	return newDocument(msg.TXA, msg.OBX)
+}
+
+func newDocument(txa TXA, obx []OBX) Document {
+	return Document{
+		Type:                  txa.DocumentType,
+		UniqueDocumentNumber:  txa.UniqueDocumentNumber,
+		ParentDocumentNumber:  txa.ParentDocumentNumber,
+		CompletionStatus:      txa.DocumentCompletionStatus,
+		ConfidentialityStatus: txa.DocumentConfidentialityStatus,
+		AvailabilityStatus:    txa.DocumentAvailabilityStatus,
+		StorageStatus:         txa.DocumentStorageStatus,
+		Authenticators:        txa.AssignedDocumentAuthenticator,
+		Authentication:        txa.AuthenticationPerson,
+		Text:                  DocumentText(obx),
+	}
+}
+
+/*
+DocumentText assembles the text spread across the values of OBX segments into
+a single document. Each OBX value is a line, as is each repetition of a value.
+Lines are put in the order of their sub IDs (OBX.4), e.g. 1, 1.2, 2, 10, and
+lines sharing a sub ID (or without one) stay in the order they were sent in.
+
+Only the values of text observations (TX, FT and ST, or no value type) are
+part of the document. Escape and formatting sequences, e.g. \.br\, have been
+replaced by the Decoder, unless it was told to keep them (see
+Decoder.KeepEscapes).
+*/
+func DocumentText(obx []OBX) string {
+	lines := make([]OBX, 0, len(obx))
+	for _, o := range obx {
+		switch o.ValueType {
+		case "", "TX", "FT", "ST":
+			lines = append(lines, o)
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b OBX) int {
+		return compareVersions(string(a.ObservationSubId), string(b.ObservationSubId))
+	})
+
+	var text strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			text.WriteByte('\n')
+		}
+		for j, value := range line.ObservationValue {
+			if j > 0 {
+				text.WriteByte('\n')
+			}
+			text.WriteString(string(value))
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,87 @@
+package faraday
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mdmTestMessage = "MSH|^~\\&|TRANS|Hosp|EHR|Hosp|20250724120000||MDM^T02|MSG1|P|2.3\r" +
+	"EVN|T02|20250724120000\r" +
+	"PID|1||123^^^MRN||DOE^JANE||19800101|F\r" +
+	"PV1|1|I|W1^101^A\r" +
+	"TXA|1|DS|TX|20250724110000|1234^SMITH^JOHN||20250724113000||1234^SMITH^JOHN|1234^SMITH^JOHN|T01^TYPIST^TOM|DOC123^TRANS||P100||doc123.txt|LA|U|AV|AC||1234^SMITH^JOHN^^^^^^^^^^^20250724115500\r" +
+	"OBX|1|TX|DS^Discharge summary||HOSPITAL COURSE:||||||F\r" +
+	"OBX|2|TX|DS^Discharge summary||Admitted with chest pain\\.br\\Troponin negative \\T\\ ECG normal.||||||F\r" +
+	"OBX|3|TX|DS^Discharge summary||Discharged home.~Follow up in 2 weeks.||||||F\r"
+
+func TestMDM_Document(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(mdmTestMessage))
+	dec.Strict()
+	val, err := dec.DecodeAny()
+	require.NoError(t, err)
+	msg, ok := val.(*MDM_T02)
+	require.True(t, ok, "got %T", val)
+
+	doc := msg.Document()
+	require.Equal(t, IS("DS"), doc.Type)
+	require.Equal(t, EI{EntityIdentifier: "DOC123", NamespaceId: "TRANS"}, doc.UniqueDocumentNumber)
+	require.Equal(t, ID("LA"), doc.CompletionStatus)
+	require.Equal(t, ID("U"), doc.ConfidentialityStatus)
+	require.Equal(t, ID("AV"), doc.AvailabilityStatus)
+	require.Equal(t, ID("AC"), doc.StorageStatus)
+	require.Equal(t, ST("SMITH"), doc.Authenticators[0].FamilyName)
+	require.Equal(t, TS("20250724115500"), doc.Authentication[0].DateTimeActionPerformed)
+	require.Equal(t, "HOSPITAL COURSE:\n"+
+		"Admitted with chest pain\nTroponin negative & ECG normal.\n"+
+		"Discharged home.\nFollow up in 2 weeks.", doc.Text)
+
+	raw := strings.NewReplacer("MDM^T02", "MDM^T01", "EVN|T02", "EVN|T01").Replace(mdmTestMessage)
+	raw = raw[:strings.Index(raw, "OBX")]
+	val, err = NewDecoder(strings.NewReader(raw)).DecodeAny()
+	require.NoError(t, err)
+	notification, ok := val.(*MDM_T01)
+	require.True(t, ok, "got %T", val)
+	doc = notification.Document()
+	require.Equal(t, ID("LA"), doc.CompletionStatus)
+	require.Empty(t, doc.Text)
+
+	// the content is required in MDM_T02
+	dec = NewDecoder(strings.NewReader(raw))
+	dec.Strict()
+	require.Error(t, dec.Decode(&MDM_T02{}))
+}
+
+func TestDocumentText(t *testing.T) {
+	text := func(values ...string) OBX {
+		obx := OBX{ValueType: "TX"}
+		for _, v := range values {
+			obx.ObservationValue = append(obx.ObservationValue, FT(v))
+		}
+		return obx
+	}
+	withSubId := func(subId ST, obx OBX) OBX {
+		obx.ObservationSubId = subId
+		return obx
+	}
+
+	require.Empty(t, DocumentText(nil))
+	require.Equal(t, "a\n\nb\nc", DocumentText([]OBX{text("a"), text(""), text("b", "c")}))
+
+	// lines are ordered by sub ID, keeping the order of lines sharing one
+	require.Equal(t, "intro\nfindings\nmore findings\ndetail\nimpression", DocumentText([]OBX{
+		withSubId("10", text("impression")),
+		withSubId("2", text("findings")),
+		withSubId("2.1", text("detail")),
+		withSubId("1", text("intro")),
+		withSubId("2", text("more findings")),
+	}))
+
+	// only text observations belong to the document
+	require.Equal(t, "a\nb", DocumentText([]OBX{
+		text("a"),
+		{ValueType: "NM", ObservationValue: []FT{"5.5"}},
+		{ValueType: "FT", ObservationValue: []FT{"b"}},
+	}))
+}
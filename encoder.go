@@ -0,0 +1,313 @@
+package faraday
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/s-hammon/p"
+)
+
+// default delimiters, used when a message does not provide its own MSH-1/MSH-2
+const (
+	defaultFieldSeparator     = "|"
+	defaultEncodingCharacters = "^~\\&"
+)
+
+type Encoder struct {
+	w io.Writer
+
+	delims      delimiters
+	keepEscapes bool
+	registry    *segmentRegistry // segments registered with this Encoder, if any
+
+	batch encoderBatch
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// KeepEscapes makes the Encoder write values as-is, for values which already
+// contain escape sequences (e.g. when decoded with Decoder.KeepEscapes).
+func (enc *Encoder) KeepEscapes() {
+	enc.keepEscapes = true
+}
+
+// Marshal returns the ER7 encoding of val, which must be a message struct (or
+// a pointer to one) as accepted by Encoder.Encode.
+func Marshal(val any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the ER7 encoding of val to the underlying writer. Segments
+// and groups are emitted in struct field order, each segment terminated by a
+// carriage return. The delimiters are taken from the message's MSH segment.
+func (enc *Encoder) Encode(val any) error {
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return fmt.Errorf("Encode: got nil %T", val)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("Encode: not a struct (got %T)", val)
+	}
+
+	var buf bytes.Buffer
+	if msg, ok := v.Interface().(Message); ok {
+		if err := enc.encodeTree(&buf, msg); err != nil {
+			return fmt.Errorf("Encode: %w", err)
+		}
+		return enc.write(buf.Bytes())
+	}
+
+	delims, err := headerDelimiters(enc.segments(), v)
+	if err != nil {
+		return fmt.Errorf("Encode: %w", err)
+	}
+	enc.delims = delims
+
+	if err := enc.encodeStruct(&buf, v); err != nil {
+		return fmt.Errorf("Encode: %w", err)
+	}
+	return enc.write(buf.Bytes())
+}
+
+// write writes an encoded message, counting it towards the current batch.
+func (enc *Encoder) write(msg []byte) error {
+	if _, err := enc.w.Write(msg); err != nil {
+		return err
+	}
+	enc.batch.messages++
+	return nil
+}
+
+func (enc *Encoder) encodeTree(buf *bytes.Buffer, msg Message) error {
+	fieldSep, encChars := defaultFieldSeparator, defaultEncodingCharacters
+	if len(msg.Segments) > 0 && msg.Segments[0].Name == "MSH" {
+		header := msg.Segments[0]
+		if len(header.Fields) > 0 && header.Fields[0].value() != "" {
+			fieldSep = header.Fields[0].value()
+		}
+		if len(header.Fields) > 1 && header.Fields[1].value() != "" {
+			encChars = header.Fields[1].value()
+		}
+	}
+	if len(fieldSep) != 1 || (len(encChars) != 4 && len(encChars) != 5) {
+		return fmt.Errorf("invalid delimiters '%s%s'", fieldSep, encChars)
+	}
+	enc.delims = newDelimiters(fieldSep[0], []byte(encChars))
+
+	for _, seg := range msg.Segments {
+		if len(seg.Name) != 3 {
+			return fmt.Errorf("invalid segment name '%s'", seg.Name)
+		}
+		buf.WriteString(seg.Name)
+		fields := seg.Fields
+		if isHeaderSegment(seg.Name) {
+			buf.WriteByte(enc.delims.field)
+			buf.Write(enc.delims.toSlice())
+			fields = fields[min(2, len(fields)):]
+		}
+		for _, field := range fields {
+			buf.WriteByte(enc.delims.field)
+			buf.Write(enc.encodeTreeField(field))
+		}
+		buf.WriteByte('\r')
+	}
+	return nil
+}
+
+// encodeTreeField writes a field of a Message as-is; unlike struct values,
+// nothing is trimmed.
+func (enc *Encoder) encodeTreeField(field Field) []byte {
+	var out []byte
+	for i, rep := range field.Repetitions {
+		if i > 0 {
+			out = append(out, enc.delims.repeat)
+		}
+		for j, comp := range rep.Components {
+			if j > 0 {
+				out = append(out, enc.delims.component)
+			}
+			for k, sub := range comp.Subcomponents {
+				if k > 0 {
+					out = append(out, enc.delims.subcomponent)
+				}
+				if enc.keepEscapes {
+					out = append(out, sub...)
+				} else {
+					out = append(out, enc.delims.escapeText([]byte(sub))...)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// headerDelimiters reads MSH-1 and MSH-2 from the message struct's MSH
+// segment, falling back to the HL7 defaults.
+func headerDelimiters(r *segmentRegistry, v reflect.Value) (delimiters, error) {
+	for _, field := range r.segmentFields(v.Type()) {
+		if field.name != "MSH" || field.repeats {
+			continue
+		}
+		return segmentDelimiters(v.Field(field.index))
+	}
+	return newDelimiters(defaultFieldSeparator[0], []byte(defaultEncodingCharacters)), nil
+}
+
+// segmentDelimiters reads the delimiters from the first two fields of a
+// header segment (MSH, FHS or BHS), falling back to the HL7 defaults.
+func segmentDelimiters(seg reflect.Value) (delimiters, error) {
+	fieldSep := p.Coalesce(seg.Field(0).String(), defaultFieldSeparator)
+	encChars := p.Coalesce(seg.Field(1).String(), defaultEncodingCharacters)
+	if len(fieldSep) != 1 || (len(encChars) != 4 && len(encChars) != 5) {
+		return delimiters{}, fmt.Errorf("invalid delimiters '%s%s'", fieldSep, encChars)
+	}
+	return newDelimiters(fieldSep[0], []byte(encChars)), nil
+}
+
+// isHeaderSegment reports whether the first two fields of a segment hold the
+// delimiters.
+func isHeaderSegment(name string) bool {
+	return name == "MSH" || name == "FHS" || name == "BHS"
+}
+
+func (enc *Encoder) encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	for _, field := range enc.segments().segmentFields(v.Type()) {
+		fVal := v.Field(field.index)
+		if !field.repeats {
+			if err := enc.encodeMember(buf, field, fVal); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := range fVal.Len() {
+			if err := enc.encodeMember(buf, field, fVal.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeMember writes a single segment or group. Empty segments are only
+// written if they are required.
+func (enc *Encoder) encodeMember(buf *bytes.Buffer, field segmentField, v reflect.Value) error {
+	if field.group {
+		return enc.encodeStruct(buf, v)
+	}
+	if v.IsZero() && field.optionality != Required && field.name != "MSH" {
+		return nil
+	}
+	if err := enc.encodeSegment(buf, field.name, v); err != nil {
+		return fmt.Errorf("encode %s: %w", field.name, err)
+	}
+	return nil
+}
+
+func (enc *Encoder) encodeSegment(buf *bytes.Buffer, name string, v reflect.Value) error {
+	buf.WriteString(name)
+
+	fields := make([][]byte, 0, v.NumField())
+	start := 0
+	if isHeaderSegment(name) {
+		// MSH-1 is the field separator itself and MSH-2 holds the encoding
+		// characters, neither of which are subject to encoding (likewise for
+		// FHS and BHS)
+		buf.WriteByte(enc.delims.field)
+		buf.Write(enc.delims.toSlice())
+		fields = append(fields, nil)
+		start = 2
+	}
+	for i := start; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		raw, err := enc.encodeValue(v.Field(i), 0)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", i+1, err)
+		}
+		fields = append(fields, raw)
+	}
+	fields = trimEmpty(fields)
+	if isHeaderSegment(name) && len(fields) > 0 {
+		// the leading placeholder stands in for MSH-2, which we already wrote
+		fields = fields[1:]
+	}
+	for _, field := range fields {
+		buf.WriteByte(enc.delims.field)
+		buf.Write(field)
+	}
+	buf.WriteByte('\r')
+	return nil
+}
+
+// encodeValue encodes a field (depth 0) along with its repetitions, a
+// component (depth 1) or a subcomponent (depth 2). Composites nested deeper
+// than subcomponents cannot be represented in ER7, so only their first value
+// is kept.
+func (enc *Encoder) encodeValue(v reflect.Value, depth int) ([]byte, error) {
+	switch v.Kind() {
+	default:
+		return nil, fmt.Errorf("unsupported field type: %s", v.Kind().String())
+	case reflect.String:
+		if enc.keepEscapes {
+			return []byte(v.String()), nil
+		}
+		return enc.delims.escapeText([]byte(v.String())), nil
+	case reflect.Slice:
+		if depth > 0 {
+			return nil, fmt.Errorf("repetitions are only allowed at the field level")
+		}
+		reps := make([][]byte, 0, v.Len())
+		for i := range v.Len() {
+			rep, err := enc.encodeValue(v.Index(i), depth)
+			if err != nil {
+				return nil, err
+			}
+			reps = append(reps, rep)
+		}
+		return bytes.Join(trimEmpty(reps), []byte{enc.delims.repeat}), nil
+	case reflect.Struct:
+		if depth >= 2 {
+			if v.NumField() == 0 {
+				return nil, nil
+			}
+			return enc.encodeValue(v.Field(0), depth)
+		}
+		sep := enc.delims.component
+		if depth == 1 {
+			sep = enc.delims.subcomponent
+		}
+		parts := make([][]byte, 0, v.NumField())
+		for i := range v.NumField() {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			part, err := enc.encodeValue(v.Field(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		return bytes.Join(trimEmpty(parts), []byte{sep}), nil
+	}
+}
+
+// trimEmpty drops trailing empty values.
+func trimEmpty(parts [][]byte) [][]byte {
+	n := len(parts)
+	for n > 0 && len(parts[n-1]) == 0 {
+		n--
+	}
+	return parts[:n]
+}
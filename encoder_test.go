@@ -0,0 +1,136 @@
+package faraday
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder_ADT(t *testing.T) {
+	raw := "MSH|^~\\&|SendingApp|SendingFac|ReceivingApp|ReceivingFac|20250724000001||ADT^A02|MSG00002|P|2.3\r" +
+		"EVN|A02|20250724000001\r" +
+		"PID|1|W01222379|W02257226^^^^^SendingFac~987654321^^^^^PN||DOE^JANE||19910101|F|||123 MAIN ST^^ANYWHERE^TX^12345^USA||(999)123-4567^PRN|(123)456-7890^WPN|||CHR|W182254551|987-65-4321|||||||||||N\r" +
+		"PV1|1|O|AER^Acme ER||||GRAJOS^Graham^Joshua^^^^M.D.||||||||||||W182254551|||||||||||||||||||||||||20250723234200\r"
+
+	adt := struct {
+		MSH MSH
+		EVN EVN
+		PID PID
+		PV1 PV1
+	}{}
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(raw))).Decode(&adt))
+
+	out, err := Marshal(&adt)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(out))
+}
+
+func TestEncoder_CustomDelimiters(t *testing.T) {
+	msg := struct {
+		MSH MSH
+		PID PID
+	}{
+		MSH: MSH{
+			FieldSeparator:     "#",
+			EncodingCharacters: "$*!@",
+			SendingApplication: HD{NamespaceId: "App", UniversalId: "1.2.3", UniversalIdType: "ISO"},
+			MessageType:        CM_MSG{Type: "ADT", Event: "A08"},
+			MessageControlId:   "1",
+			ProcessingId:       PT{ProcessingId: "P"},
+			VersionId:          "2.3",
+		},
+		PID: PID{
+			InternalPatientId: []CX{{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN", UniversalId: "SITE"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).Encode(msg))
+	require.Equal(t,
+		"MSH#$*!@#App$1.2.3$ISO######ADT$A08#1#P#2.3\r"+
+			"PID###123$$$MRN@SITE\r",
+		buf.String(),
+	)
+}
+
+func TestEncoder_TruncationCharacter(t *testing.T) {
+	raw := "MSH|^~\\&#|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A01|MSG1|P|2.7\r" +
+		"EVN|A01\r" +
+		"PID|1||123^^^MRN||DOE\\P\\1^JANE\r" +
+		"PV1|1|I\r"
+
+	var msg ADT_A01
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	require.Equal(t, ST("^~\\&#"), msg.MSH.EncodingCharacters)
+	require.Equal(t, IS("ADT"), msg.MSH.SendingApplication.NamespaceId)
+	require.Equal(t, ST("DOE#1"), msg.PID.PatientName[0].FamilyName)
+
+	encoded, err := Marshal(&msg)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(encoded))
+
+	var tree Message
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&tree))
+	require.Equal(t, Subcomponent("^~\\&#"), tree.Segments[0].Fields[1].Repetitions[0].Components[0].Subcomponents[0])
+	encoded, err = Marshal(tree)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(encoded))
+
+	// without a truncation character, \P\ is no escape sequence
+	msg.MSH.EncodingCharacters = "^~\\&"
+	encoded, err = Marshal(&msg)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), "|DOE#1^JANE\r")
+}
+
+func TestEncoder_DefaultDelimiters(t *testing.T) {
+	msg := struct {
+		MSH MSH
+		NTE NTE
+	}{
+		MSH: MSH{MessageType: CM_MSG{Type: "ACK"}},
+	}
+
+	out, err := Marshal(msg)
+	require.NoError(t, err)
+	require.Equal(t, "MSH|^~\\&|||||||ACK\r", string(out))
+}
+
+func TestEncoder_Groups(t *testing.T) {
+	msg := ORU_R01{
+		MSH: MSH{MessageType: CM_MSG{Type: "ORU", Event: "R01"}, MessageControlId: "1", VersionId: "2.3"},
+		Results: []ResultGroup{{
+			Patient: ObsPatientGroup{
+				PID:   PID{SetId: "1", PatientName: []XPN{{FamilyName: "DOE", GivenName: "JOHN"}}},
+				Visit: PatientVisitGroup{PV1: PV1{SetId: "1", PatientClass: "O"}},
+			},
+			Order: []ObsOrderGroup{{
+				ORC: ORC{OrderControl: "RE"},
+				OBR: OBR{SetId: "1", UniversalServiceID: CE{Identifier: "CBC"}},
+				Results: []ObservationGroup{
+					{OBX: OBX{SetId: "1", ValueType: "NM", ObservationIdentifier: CE{Identifier: "WBC"}, ObservationValue: []FT{"5.4"}}},
+					{
+						OBX: OBX{SetId: "2", ValueType: "NM", ObservationIdentifier: CE{Identifier: "HGB"}, ObservationValue: []FT{"13.7"}},
+						NTE: []NTE{{SetId: "1", Comment: []FT{"low"}}},
+					},
+				},
+			}},
+		}},
+	}
+
+	out, err := Marshal(&msg)
+	require.NoError(t, err)
+	require.Equal(t,
+		"MSH|^~\\&|||||||ORU^R01|1||2.3\r"+
+			"PID|1||||DOE^JOHN\r"+
+			"PV1|1|O\r"+
+			"ORC|RE\r"+
+			"OBR|1|||CBC\r"+
+			"OBX|1|NM|WBC||5.4\r"+
+			"OBX|2|NM|HGB||13.7\r"+
+			"NTE|1||low\r",
+		string(out),
+	)
+}
@@ -0,0 +1,188 @@
+package faraday
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+/*
+HL7 escape sequences are delimited by the escape character defined in MSH-2
+(usually '\'). The following are supported:
+
+	\F\		field separator
+	\S\		component separator
+	\T\		subcomponent separator
+	\R\		repetition separator
+	\E\		escape character
+	\P\		truncation character (v2.7, if MSH-2 has one)
+	\Xhh..\		hexadecimal data
+	\H\, \N\	start/end highlighting (dropped)
+	\.br\		line break
+	\.sp n\		n line breaks
+	\.sk n\		n spaces
+	\.in n\, \.ti n\, \.ce\, \.fi\, \.nf\	formatting hints (dropped)
+
+Formatting commands whose count exceeds a line's width (maxFormattingCount)
+are kept verbatim, so a message cannot expand into an arbitrary amount of
+text. Anything else (e.g. the character set escapes \Cxxyy\ and \Mxxyyzz\, or local
+\Z..\ escapes) is kept verbatim.
+*/
+
+// unescapeText replaces the escape sequences found in raw with the text they
+// stand for.
+func (d delimiters) unescapeText(raw []byte) []byte {
+	if bytes.IndexByte(raw, d.escape) < 0 {
+		return raw
+	}
+
+	var out bytes.Buffer
+	for len(raw) > 0 {
+		i := bytes.IndexByte(raw, d.escape)
+		if i < 0 {
+			out.Write(raw)
+			break
+		}
+		out.Write(raw[:i])
+
+		rest := raw[i+1:]
+		j := bytes.IndexByte(rest, d.escape)
+		if j < 0 {
+			// unterminated escape sequence
+			out.Write(raw[i:])
+			break
+		}
+		if !d.writeUnescaped(&out, string(rest[:j])) {
+			out.Write(raw[i : i+j+2])
+		}
+		raw = rest[j+1:]
+	}
+	return out.Bytes()
+}
+
+func (d delimiters) writeUnescaped(out *bytes.Buffer, seq string) bool {
+	switch seq {
+	case "F":
+		out.WriteByte(d.field)
+		return true
+	case "S":
+		out.WriteByte(d.component)
+		return true
+	case "T":
+		out.WriteByte(d.subcomponent)
+		return true
+	case "R":
+		out.WriteByte(d.repeat)
+		return true
+	case "E":
+		out.WriteByte(d.escape)
+		return true
+	case "P":
+		if d.truncation == 0 {
+			return false
+		}
+		out.WriteByte(d.truncation)
+		return true
+	case "H", "N", ".fi", ".nf", ".ce":
+		return true
+	case ".br":
+		out.WriteByte('\n')
+		return true
+	}
+
+	switch {
+	case strings.HasPrefix(seq, "X"):
+		b, err := hex.DecodeString(seq[1:])
+		if err != nil {
+			return false
+		}
+		out.Write(b)
+		return true
+	case strings.HasPrefix(seq, ".sp"):
+		n, ok := formattingCount(seq[3:])
+		if ok {
+			out.WriteString(strings.Repeat("\n", n))
+		}
+		return ok
+	case strings.HasPrefix(seq, ".sk"):
+		n, ok := formattingCount(seq[3:])
+		if ok {
+			out.WriteString(strings.Repeat(" ", n))
+		}
+		return ok
+	case strings.HasPrefix(seq, ".in"), strings.HasPrefix(seq, ".ti"):
+		_, ok := formattingCount(strings.TrimPrefix(strings.TrimSpace(seq[3:]), "-"))
+		return ok
+	}
+	return false
+}
+
+// maxFormattingCount is the largest count of a formatting command, e.g. the
+// number of spaces of \.sk n\: a line of formatted text is at most this wide.
+const maxFormattingCount = 255
+
+// formattingCount parses the optional count of a formatting command, which
+// defaults to 1 and is at most maxFormattingCount.
+func formattingCount(arg string) (int, bool) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return 1, true
+	}
+	arg = strings.TrimPrefix(arg, "+")
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 || n > maxFormattingCount {
+		return 0, false
+	}
+	return n, true
+}
+
+// escapeText replaces delimiters and line breaks found in raw with their escape
+// sequences.
+func (d delimiters) escapeText(raw []byte) []byte {
+	if bytes.IndexFunc(raw, d.needsEscape) < 0 {
+		return raw
+	}
+
+	var out bytes.Buffer
+	for _, b := range raw {
+		if d.truncation != 0 && b == d.truncation {
+			out.Write([]byte{d.escape, 'P', d.escape})
+			continue
+		}
+		switch b {
+		case d.escape:
+			out.Write([]byte{d.escape, 'E', d.escape})
+		case d.field:
+			out.Write([]byte{d.escape, 'F', d.escape})
+		case d.component:
+			out.Write([]byte{d.escape, 'S', d.escape})
+		case d.subcomponent:
+			out.Write([]byte{d.escape, 'T', d.escape})
+		case d.repeat:
+			out.Write([]byte{d.escape, 'R', d.escape})
+		case '\n':
+			out.WriteByte(d.escape)
+			out.WriteString(".br")
+			out.WriteByte(d.escape)
+		case '\r':
+			out.WriteByte(d.escape)
+			out.WriteString("X0D")
+			out.WriteByte(d.escape)
+		default:
+			out.WriteByte(b)
+		}
+	}
+	return out.Bytes()
+}
+
+func (d delimiters) needsEscape(r rune) bool {
+	if d.truncation != 0 && r == rune(d.truncation) {
+		return true
+	}
+	switch r {
+	case rune(d.escape), rune(d.field), rune(d.component), rune(d.subcomponent), rune(d.repeat), '\n', '\r':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package faraday
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelimitersUnescapeText(t *testing.T) {
+	delims := newDelimiters('|', defaultDelims)
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "no escapes", want: "no escapes"},
+		{raw: `a\F\b\S\c\T\d\R\e\E\f`, want: `a|b^c&d~e\f`},
+		{raw: `line 1\.br\line 2`, want: "line 1\nline 2"},
+		{raw: `\H\IMPORTANT\N\ result`, want: "IMPORTANT result"},
+		{raw: `\X48656C6C6F\`, want: "Hello"},
+		{raw: `a\.sp 2\b`, want: "a\n\nb"},
+		{raw: `a\.sk 3\b`, want: "a   b"},
+		{raw: `a\.sk 255\b`, want: "a" + strings.Repeat(" ", 255) + "b"},
+		{raw: `a\.sk 256\b`, want: `a\.sk 256\b`},
+		{raw: `x\.sp 99999999999999\y`, want: `x\.sp 99999999999999\y`},
+		{raw: `\.in 4\\.ti -2\\.ce\centered`, want: "centered"},
+		{raw: `\C2842\kanji`, want: `\C2842\kanji`},
+		{raw: `\XZZ\`, want: `\XZZ\`},
+		{raw: `unterminated \F`, want: `unterminated \F`},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, string(delims.unescapeText([]byte(tt.raw))), tt.raw)
+	}
+}
+
+func TestDecoder_HugeFormattingCount(t *testing.T) {
+	raw := "MSH|^~\\&|A|B|C|D|20240101||ADT^A01|1|P|2.3\r" +
+		"NTE|1||x\\.sp 99999999999999\\y\r"
+	var msg Message
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	got, err := Get(msg, "NTE-3")
+	require.NoError(t, err)
+	require.Equal(t, `x\.sp 99999999999999\y`, got)
+}
+
+func TestDelimitersEscapeText(t *testing.T) {
+	delims := newDelimiters('|', defaultDelims)
+	require.Equal(t, "plain", string(delims.escapeText([]byte("plain"))))
+	require.Equal(t,
+		`a\F\b\S\c\T\d\R\e\E\f\.br\g\X0D\`,
+		string(delims.escapeText([]byte("a|b^c&d~e\\f\ng\r"))),
+	)
+
+	custom := newDelimiters('#', []byte("$*!@"))
+	require.Equal(t, "!F!!S!|^", string(custom.escapeText([]byte("#$|^"))))
+}
+
+func TestDecoder_Escapes(t *testing.T) {
+	raw := []byte("MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|1|P|2.3\r" +
+		"NTE|1||Glucose \\T\\ A1C: see note\\.br\\Repeat in 3\\S\\6 months\r")
+
+	var msg struct {
+		MSH MSH
+		NTE NTE
+	}
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+	require.Equal(t, []FT{"Glucose & A1C: see note\nRepeat in 3^6 months"}, msg.NTE.Comment)
+
+	out, err := Marshal(&msg)
+	require.NoError(t, err)
+	require.Equal(t, string(raw), string(out))
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.KeepEscapes()
+	require.NoError(t, dec.Decode(&msg))
+	require.Equal(t, []FT{"Glucose \\T\\ A1C: see note\\.br\\Repeat in 3\\S\\6 months"}, msg.NTE.Comment)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.KeepEscapes()
+	require.NoError(t, enc.Encode(&msg))
+	require.Equal(t, string(raw), buf.String())
+}
+
+func TestDecoder_HeaderEscapes(t *testing.T) {
+	raw := []byte("MSH|^~\\&|LIS|Lab \\T\\ Path|EHR|Hosp|20250724121200||ORU^R01|1|P|2.3\r")
+
+	var msg struct{ MSH MSH }
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+	require.Equal(t, HD{NamespaceId: "Lab & Path"}, msg.MSH.SendingFacility)
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.KeepEscapes()
+	require.NoError(t, dec.Decode(&msg))
+	require.Equal(t, HD{NamespaceId: "Lab \\T\\ Path"}, msg.MSH.SendingFacility)
+
+	dec = NewDecoder(bytes.NewReader(append([]byte("BHS|^~\\&|LIS|Lab \\T\\ Path\r"), raw...)))
+	dec.KeepEscapes()
+	require.NoError(t, dec.Decode(&msg))
+	require.Equal(t, HD{NamespaceId: "Lab \\T\\ Path"}, dec.BatchHeader().SendingFacility)
+}
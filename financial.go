@@ -6,13 +6,13 @@ package faraday
 
 // The standard GT1 segment
 type GT1 struct {
-	SetId                    SI  `hl7:"opt=R"`
-	GuarantorNumber          CX  `hl7:"rep=Y"`
-	Name                     XPN `hl7:"opt=R,rep=Y"`
-	SpouseName               XPN `hl7:"rep=Y"`
-	Address                  XAD `hl7:"rep=Y"`
-	HomePhoneNumber          XTN `hl7:"rep=Y"`
-	WorkPhoneNumber          XTN `hl7:"rep=Y"`
+	SetId                    SI    `hl7:"opt=R"`
+	GuarantorNumber          []CX  `hl7:"rep=Y"`
+	Name                     []XPN `hl7:"opt=R,rep=Y"`
+	SpouseName               []XPN `hl7:"rep=Y"`
+	Address                  []XAD `hl7:"rep=Y"`
+	HomePhoneNumber          []XTN `hl7:"rep=Y"`
+	WorkPhoneNumber          []XTN `hl7:"rep=Y"`
 	DOB                      TS
 	Sex                      IS
 	Type                     IS
@@ -21,12 +21,12 @@ type GT1 struct {
 	BeginDate                DT
 	EndDate                  DT
 	Priority                 NM
-	EmployerName             XPN `hl7:"rep=Y"`
-	EmployerAddress          XAD `hl7:"rep=Y"`
-	EmployerPhoneNumber      XTN `hl7:"rep=Y"`
-	EmployeeIdNumber         CX  `hl7:"rep=Y"`
+	EmployerName             []XPN `hl7:"rep=Y"`
+	EmployerAddress          []XAD `hl7:"rep=Y"`
+	EmployerPhoneNumber      []XTN `hl7:"rep=Y"`
+	EmployeeIdNumber         []CX  `hl7:"rep=Y"`
 	EmploymentStatus         IS
-	OrganizationName         XON `hl7:"rep=Y"`
+	OrganizationName         []XON `hl7:"rep=Y"`
 	BillingHoldFlag          ID
 	CreditRatingCode         CE
 	DeathDateTime            TS
@@ -34,7 +34,7 @@ type GT1 struct {
 	ChargeAdjustmentCode     CE
 	HouseholdAnnualIncome    CP
 	HouseholdSize            NM
-	EmployerIdNumber         CX `hl7:"rep=Y"`
+	EmployerIdNumber         []CX `hl7:"rep=Y"`
 	MaritalStatus            IS
 	HireEffectiveDate        DT
 	EmploymentStopDate       DT
@@ -50,13 +50,13 @@ type GT1 struct {
 	MotherMaidenName         XPN
 	Nationality              CE
 	EthnicGroup              IS
-	ContactName              XPN `hl7:"rep=Y"`
-	ContactPhoneNumber       XTN `hl7:"rep=Y"`
+	ContactName              []XPN `hl7:"rep=Y"`
+	ContactPhoneNumber       []XTN `hl7:"rep=Y"`
 	ContactReason            CE
 	ContactRelationship      IS
 	JobTitle                 ST
 	JobCode                  JCC
-	EmployerOrganizationName XON `hl7:"rep=Y"`
+	EmployerOrganizationName []XON `hl7:"rep=Y"`
 	Handicap                 IS
 	JobStatus                IS
 	FinancialClass           FC
@@ -65,25 +65,25 @@ type GT1 struct {
 
 // The standard IN1 segment
 type IN1 struct {
-	SetId                    SI  `hl7:"opt=R"`
-	PlanId                   CE  `hl7:"opt=R"`
-	CompanyId                CX  `hl7:"opt=R,rep=Y"`
-	CompanyName              XON `hl7:"rep=Y"`
-	CompanyAddress           XAD `hl7:"rep=Y"`
-	CompanyContact           XPN `hl7:"rep=Y"`
-	CompanyPhoneNumber       XTN `hl7:"rep=Y"`
+	SetId                    SI    `hl7:"opt=R"`
+	PlanId                   CE    `hl7:"opt=R"`
+	CompanyId                []CX  `hl7:"opt=R,rep=Y"`
+	CompanyName              []XON `hl7:"rep=Y"`
+	CompanyAddress           []XAD `hl7:"rep=Y"`
+	CompanyContact           []XPN `hl7:"rep=Y"`
+	CompanyPhoneNumber       []XTN `hl7:"rep=Y"`
 	GroupNumber              ST
-	GroupName                XON `hl7:"rep=Y"`
-	GroupEmployerId          CX  `hl7:"rep=Y"`
-	GroupEmployerName        XON `hl7:"rep=Y"`
+	GroupName                []XON `hl7:"rep=Y"`
+	GroupEmployerId          []CX  `hl7:"rep=Y"`
+	GroupEmployerName        []XON `hl7:"rep=Y"`
 	PlanEffectiveDate        DT
 	PlanExpirationDate       DT
 	AuthorizationInformation CM_AUI
 	PlanType                 IS
-	InsuredName              XPN `hl7:"rep=Y"`
+	InsuredName              []XPN `hl7:"rep=Y"`
 	RelationshipToPatient    IS
 	InsuredDOB               TS
-	InsuredAddress           XAD `hl7:"rep=Y"`
+	InsuredAddress           []XAD `hl7:"rep=Y"`
 	AOB                      IS
 	COB                      IS
 	COBPriority              ST
@@ -108,25 +108,25 @@ type IN1 struct {
 	RoomRatePrivate          CP `hl7:"opt=B"`
 	InsuredEmploymentStatus  CE
 	InsuredSex               IS
-	InsuredEmployerAddress   XAD `hl7:"rep=Y"`
+	InsuredEmployerAddress   []XAD `hl7:"rep=Y"`
 	VerificationStatus       ST
 	PriorInsturancePlanId    IS
 	CoverageType             IS
 	Handicap                 IS
-	InsuredIdNumber          CX `hl7:"rep=Y"`
+	InsuredIdNumber          []CX `hl7:"rep=Y"`
 }
 
 // The standard IN2 segment
 type IN2 struct {
-	InsuredEmployeeId                  CX `hl7:"rep=Y"`
+	InsuredEmployeeId                  []CX `hl7:"rep=Y"`
 	InsuredSSN                         ST
-	InsuredEmployerName                XCN `hl7:"rep=Y"`
+	InsuredEmployerName                []XCN `hl7:"rep=Y"`
 	EmployerInformationData            IS
-	MailClaimParty                     IS `hl7:"rep=Y"`
+	MailClaimParty                     []IS `hl7:"rep=Y"`
 	MedicareCardNumber                 ST
-	MedicaidCaseName                   XPN `hl7:"rep=Y"`
+	MedicaidCaseName                   []XPN `hl7:"rep=Y"`
 	MedicaidCaseNumber                 ST
-	ChampuSponsorName                  XPN `hl7:"rep=Y"`
+	ChampuSponsorName                  []XPN `hl7:"rep=Y"`
 	ChampusIdNumber                    ST
 	ChampusDependentRecipient          CE
 	ChampusOrganization                ST
@@ -139,14 +139,14 @@ type IN2 struct {
 	BabyCoverage                       ID
 	CombineBabyBill                    ID
 	BloodDeductible                    ST
-	SpecialCoverageApprovalName        XPN `hl7:"rep=Y"`
+	SpecialCoverageApprovalName        []XPN `hl7:"rep=Y"`
 	SpecialCoverageApprovalTitle       ST
-	NoncoveredInsuranceCode            IS `hl7:"rep=Y"`
-	PayorId                            CX `hl7:"rep=Y"`
-	PayorSubscriberId                  CX `hl7:"rep=Y"`
+	NoncoveredInsuranceCode            []IS `hl7:"rep=Y"`
+	PayorId                            []CX `hl7:"rep=Y"`
+	PayorSubscriberId                  []CX `hl7:"rep=Y"`
 	EligibilitySource                  IS
-	RoomCoverageType                   CM_PLT `hl7:"rep=Y"`
-	PolicyType                         CM_PLT `hl7:"rep=Y"`
+	RoomCoverageType                   []CM_PLT `hl7:"rep=Y"`
+	PolicyType                         []CM_PLT `hl7:"rep=Y"`
 	DailyDeductible                    CM_DDE
 	LivingDependency                   IS
 	AmbulatoryStatus                   IS
@@ -160,34 +160,34 @@ type IN2 struct {
 	MotherMaidenName                   XPN
 	Nationality                        CE
 	EthnicGroup                        IS
-	MaritalStatus                      IS `hl7:"rep=Y"`
+	MaritalStatus                      []IS `hl7:"rep=Y"`
 	InsuredEmploymentStartDate         DT
 	InsuredEmploymentStopDate          DT
 	JobTitle                           ST
 	JobCode                            JCC
 	JobStatus                          IS
-	EmployerContactName                XPN `hl7:"rep=Y"`
-	EmployerContactPhoneNumber         XTN `hl7:"rep=Y"`
+	EmployerContactName                []XPN `hl7:"rep=Y"`
+	EmployerContactPhoneNumber         []XTN `hl7:"rep=Y"`
 	EmployerContactReason              IS
-	InsuredContactName                 XPN `hl7:"rep=Y"`
-	InsuredContactPhoneNumbet          XTN `hl7:"rep=Y"`
-	InsuredContactReason               IS  `hl7:"rep=Y"`
+	InsuredContactName                 []XPN `hl7:"rep=Y"`
+	InsuredContactPhoneNumbet          []XTN `hl7:"rep=Y"`
+	InsuredContactReason               []IS  `hl7:"rep=Y"`
 	RelationshipToPatientStartDate     DT
-	RelationshipToPatientStopDate      DT `hl7:"rep=Y"`
+	RelationshipToPatientStopDate      []DT `hl7:"rep=Y"`
 	InsuranceCompanyContactReason      IS
 	InsuranceCompanyContactPhoneNumber XTN
 	PolicyScope                        IS
 	PolicySource                       IS
 	PatientMemberNumber                CX
 	GuarantorRelationship              IS
-	InsuredHomePhoneNumber             XTN `hl7:"rep=Y"`
-	InsuredHomeWorkNumber              XTN `hl7:"rep=Y"`
+	InsuredHomePhoneNumber             []XTN `hl7:"rep=Y"`
+	InsuredHomeWorkNumber              []XTN `hl7:"rep=Y"`
 	MilitaryHandicappedProgram         CE
 	SuspendFlag                        ID
 	CopayLimitFlag                     ID
 	StoplossLimitFlag                  ID
-	InsuredOrganizationName            XON `hl7:"rep=Y"`
-	InsuredEmployerOrganizationName    XON `hl7:"rep=Y"`
+	InsuredOrganizationName            []XON `hl7:"rep=Y"`
+	InsuredEmployerOrganizationName    []XON `hl7:"rep=Y"`
 	Race                               IS
 	HcfaPatientRelationshipToInsured   CE
 }
@@ -196,29 +196,29 @@ type IN2 struct {
 type IN3 struct {
 	SetId                              SI `hl7:"opt=R"`
 	CertificationNumber                CX
-	CertifiedBy                        XCN `hl7:"rep=Y"`
+	CertifiedBy                        []XCN `hl7:"rep=Y"`
 	CertificationRequired              ID
 	Penalty                            CM_VAL
 	CertificationDateTime              TS
 	CertificationModalityDateTime      TS
-	Operator                           XCN `hl7:"rep=Y"`
+	Operator                           []XCN `hl7:"rep=Y"`
 	CertificationBeginDate             DT
 	CertificationEndDate               DT
 	Days                               CM_VAL
 	NonConcurCodeDescription           CE
 	NonConcurEffectiveDateTime         TS
-	PhysicianReviewer                  XCN `hl7:"rep=Y"`
+	PhysicianReviewer                  []XCN `hl7:"rep=Y"`
 	CertificationContact               ST
-	CertificationContactPhoneNumber    XTN `hl7:"rep=Y"`
+	CertificationContactPhoneNumber    []XTN `hl7:"rep=Y"`
 	AppealReason                       CE
 	CertificationAgency                CE
-	CertificationAgencyPhoneNumber     XTN    `hl7:"rep=Y"`
-	PreCertRequirementWindow           CM_PCR `hl7:"rep=Y"`
+	CertificationAgencyPhoneNumber     []XTN    `hl7:"rep=Y"`
+	PreCertRequirementWindow           []CM_PCR `hl7:"rep=Y"`
 	CaseManager                        ST
 	SecondOpinionDate                  DT
 	SecondOpinionStatus                IS
-	SecondOpinionDocumentationReceived IS  `hl7:"rep=Y"`
-	SecondOpinionPhysician             XCN `hl7:"rep=Y"`
+	SecondOpinionDocumentationReceived []IS  `hl7:"rep=Y"`
+	SecondOpinionPhysician             []XCN `hl7:"rep=Y"`
 }
 
 // The standard ACC segment
@@ -239,16 +239,16 @@ type UB1 struct {
 	BluodReplaced        NM
 	BloodNotReplaced     NM
 	CoInsuranceDays      NM
-	ConditionCode        IS `hl7:"rep=Y5"`
+	ConditionCode        []IS `hl7:"rep=Y5"`
 	CoveredDays          NM
 	NonCoveredDays       NM
-	ValueAmount          CM_VAL `hl7:"rep=Y8"`
+	ValueAmount          []CM_VAL `hl7:"rep=Y8"`
 	GraceDays            NM
 	SpecProgramIndicator CE
 	ApprovalIndicator    CE
 	ApprovedStayFrom     DT
 	ApprovedStayTo       DT
-	Occurrence           CE `hl7:"rep=Y5"` // NOTE: defind as CM in spec, but is actually the same structure as a CE
+	Occurrence           []CE `hl7:"rep=Y5"` // NOTE: defind as CM in spec, but is actually the same structure as a CE
 	OccurrenceSpan       CE
 	OccurSpanStartDate   DT
 	OccurSpanEndDate     DT
@@ -262,20 +262,20 @@ type UB1 struct {
 type UB2 struct {
 	SetId                 SI
 	CoInsuranceDays       ST
-	ConditionCode         IS `hl7:"rep=Y7"`
+	ConditionCode         []IS `hl7:"rep=Y7"`
 	CoveredDays           ST
 	NonCoveredDays        ST
-	ValueAmountCode       CM_VAL `hl7:"rep=Y12"`
-	Occurrence            CM_OCD `hl7:"rep=Y8"`
-	OccurrenceSpanCode    ST     `hl7:"rep=Y2"`
-	Locator2              ST     `hl7:"rep=Y2"`
-	Locator11             ST     `hl7:"rep=Y2"`
+	ValueAmountCode       []CM_VAL `hl7:"rep=Y12"`
+	Occurrence            []CM_OCD `hl7:"rep=Y8"`
+	OccurrenceSpanCode    []ST     `hl7:"rep=Y2"`
+	Locator2              []ST     `hl7:"rep=Y2"`
+	Locator11             []ST     `hl7:"rep=Y2"`
 	Locator31             ST
-	DocumentControlNumber ST `hl7:"rep=Y3"`
-	Locator49             ST `hl7:"rep=Y23"`
-	Locator56             ST `hl7:"rep=Y5"`
+	DocumentControlNumber []ST `hl7:"rep=Y3"`
+	Locator49             []ST `hl7:"rep=Y23"`
+	Locator56             []ST `hl7:"rep=Y5"`
 	Locator57             ST
-	Locator78             ST `hl7:"rep=Y2"`
+	Locator78             []ST `hl7:"rep=Y2"`
 	SpecialVisitCount     NM
 }
 
@@ -286,17 +286,17 @@ type DG1 struct {
 	Code                    CE
 	Description             ST `hl7:"opt=B"`
 	DateTime                TS
-	Type                    IS  `hl7:"opt=R"`
-	MajorDiagnosticCategory CE  `hl7:"opt=B"`
-	DiagnosticRelatedGroup  CE  `hl7:"opt=B"`
-	DRGApprovalIndicator    ID  `hl7:"opt=B"`
-	DRGGrouperReviewCode    IS  `hl7:"opt=B"`
-	OutlierType             CE  `hl7:"opt=B"`
-	OutlierDays             NM  `hl7:"opt=B"`
-	OutlierCost             CP  `hl7:"opt=B"`
-	GoruperVersion          ST  `hl7:"opt=B"`
-	Priority                NM  `hl7:"opt=B"`
-	DiagnosingClinician     XCN `hl7:"rep=Y"`
+	Type                    IS    `hl7:"opt=R"`
+	MajorDiagnosticCategory CE    `hl7:"opt=B"`
+	DiagnosticRelatedGroup  CE    `hl7:"opt=B"`
+	DRGApprovalIndicator    ID    `hl7:"opt=B"`
+	DRGGrouperReviewCode    IS    `hl7:"opt=B"`
+	OutlierType             CE    `hl7:"opt=B"`
+	OutlierDays             NM    `hl7:"opt=B"`
+	OutlierCost             CP    `hl7:"opt=B"`
+	GoruperVersion          ST    `hl7:"opt=B"`
+	Priority                NM    `hl7:"opt=B"`
+	DiagnosingClinician     []XCN `hl7:"rep=Y"`
 	Classification          IS
 	ConfidentialIndicator   ID
 	AttestationDateTime     TS
@@ -325,11 +325,11 @@ type PR1 struct {
 	DateTime                TS `hl7:"opt=R"`
 	FunctionalType          IS `hl7:"opt=R"`
 	Minutes                 NM
-	Anesthesiologist        XCN `hl7:"opt=B,rep=Y"`
+	Anesthesiologist        []XCN `hl7:"opt=B,rep=Y"`
 	AnesthesiaCode          IS
 	AnesthesiaMinutes       NM
-	Surgeon                 XCN `hl7:"opt=B,rep=Y"`
-	Practitioner            XCN `hl7:"opt=B,rep=Y"`
+	Surgeon                 []XCN `hl7:"opt=B,rep=Y"`
+	Practitioner            []XCN `hl7:"opt=B,rep=Y"`
 	ConsentCode             CE
 	Priority                NM
 	AssociatedDiagnosisCode CE
@@ -79,7 +79,7 @@ type InsuranceGroup struct {
 
 // The standard OrderGroup (ORM)
 type OrderGroup struct {
-	ORC     ORC
+	ORC     ORC `hl7:"opt=R"`
 	Details OrderDetailGroup
 }
 
@@ -100,7 +100,7 @@ type ObservationGroup struct {
 // The standard ProcedureGroup
 type ProcedureGroup struct {
 	PR1 PR1 `hl7:"opt=R"`
-	// ROL []ROL
+	ROL []ROL
 }
 
 // The standard ResultGroup
@@ -125,3 +125,296 @@ type ObsOrderGroup struct {
 	Results []ObservationGroup `hl7:"opt=R"`
 	// CTI []CTI
 }
+
+// The standard PatientGroup (ADT_A17)
+type SwapPatientGroup struct {
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+}
+
+// The standard PatientGroup (ADT_A24, ADT_A37)
+type LinkPatientGroup struct {
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1
+	DB1 []DB1
+}
+
+// The standard PatientGroup (ADT_A39)
+type MergePatientGroup struct {
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	MRG MRG `hl7:"opt=R"`
+	PV1 PV1
+}
+
+// The standard PatientGroup (ADT_A43)
+type MovePatientGroup struct {
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	MRG MRG `hl7:"opt=R"`
+}
+
+// The standard MergeGroup (ADT_A45)
+type MergeVisitGroup struct {
+	MRG MRG `hl7:"opt=R"`
+	PV1 PV1 `hl7:"opt=R"`
+}
+
+// The standard PatientGroup (RDE)
+type PharmacyEncodedPatientGroup struct {
+	PID       PID `hl7:"opt=R"`
+	PD1       PD1
+	NTE       []NTE
+	Visit     PatientVisitGroup
+	Insurance []InsuranceGroup
+	GT1       GT1
+	AL1       []AL1
+}
+
+// The standard PatientGroup (RDS, RGV, RAS)
+type PharmacyPatientGroup struct {
+	PID   PID `hl7:"opt=R"`
+	PD1   PD1
+	NTE   []NTE
+	AL1   []AL1
+	Visit PatientVisitGroup
+}
+
+// The standard OrderDetailGroup (pharmacy)
+type PharmacyOrderDetailGroup struct {
+	RXO        RXO `hl7:"opt=R"`
+	NTE        []NTE
+	RXR        []RXR `hl7:"opt=R"`
+	Components PharmacyComponentGroup
+}
+
+// The standard ComponentGroup (pharmacy): the components of the order,
+// followed by notes on them.
+type PharmacyComponentGroup struct {
+	RXC []RXC `hl7:"opt=R"`
+	NTE []NTE
+}
+
+// The standard EncodingGroup (RDS, RGV, RAS)
+type PharmacyEncodingGroup struct {
+	RXE RXE   `hl7:"opt=R"`
+	RXR []RXR `hl7:"opt=R"`
+	RXC []RXC
+}
+
+// The standard OrderGroup (RDE)
+type PharmacyEncodedOrderGroup struct {
+	ORC     ORC `hl7:"opt=R"`
+	Detail  PharmacyOrderDetailGroup
+	RXE     RXE   `hl7:"opt=R"`
+	RXR     []RXR `hl7:"opt=R"`
+	RXC     []RXC
+	Results []ObservationGroup
+}
+
+// The standard OrderGroup (RDS)
+type PharmacyDispenseOrderGroup struct {
+	ORC      ORC `hl7:"opt=R"`
+	Detail   PharmacyOrderDetailGroup
+	Encoding PharmacyEncodingGroup
+	RXD      RXD   `hl7:"opt=R"`
+	RXR      []RXR `hl7:"opt=R"`
+	RXC      []RXC
+	Results  []ObservationGroup
+}
+
+// The standard OrderGroup (RGV)
+type PharmacyGiveOrderGroup struct {
+	ORC      ORC `hl7:"opt=R"`
+	Detail   PharmacyOrderDetailGroup
+	Encoding PharmacyEncodingGroup
+	Give     []PharmacyGiveGroup `hl7:"opt=R"`
+}
+
+// The standard GiveGroup (RGV)
+type PharmacyGiveGroup struct {
+	RXG     RXG   `hl7:"opt=R"`
+	RXR     []RXR `hl7:"opt=R"`
+	RXC     []RXC
+	Results []ObservationGroup
+}
+
+// The standard OrderGroup (RAS)
+type PharmacyAdministrationOrderGroup struct {
+	ORC      ORC `hl7:"opt=R"`
+	Detail   PharmacyOrderDetailGroup
+	Encoding PharmacyEncodingGroup
+	RXA      []RXA `hl7:"opt=R"`
+	RXR      RXR   `hl7:"opt=R"`
+	Results  []ObservationGroup
+}
+
+// The standard OrderGroup (VXU)
+type VaccinationGroup struct {
+	ORC     ORC
+	RXA     RXA `hl7:"opt=R"`
+	RXR     RXR
+	Results []ObservationGroup
+}
+
+// The standard PatientGroup (SIU, SRM, SRR)
+type SchedulingPatientGroup struct {
+	PID PID `hl7:"opt=R"`
+	PV1 PV1
+	PV2 PV2
+	OBX []OBX
+	DG1 []DG1
+}
+
+// The standard ResourceGroup (SIU, SRR)
+type ResourceGroup struct {
+	RGS       RGS `hl7:"opt=R"`
+	Services  []ServiceGroup
+	General   []GeneralResourceGroup
+	Locations []LocationResourceGroup
+	Personnel []PersonnelResourceGroup
+}
+
+// The standard ServiceGroup (SIU, SRR)
+type ServiceGroup struct {
+	AIS AIS `hl7:"opt=R"`
+	NTE []NTE
+}
+
+// The standard GeneralResourceGroup (SIU, SRR)
+type GeneralResourceGroup struct {
+	AIG AIG `hl7:"opt=R"`
+	NTE []NTE
+}
+
+// The standard LocationResourceGroup (SIU, SRR)
+type LocationResourceGroup struct {
+	AIL AIL `hl7:"opt=R"`
+	NTE []NTE
+}
+
+// The standard PersonnelResourceGroup (SIU, SRR)
+type PersonnelResourceGroup struct {
+	AIP AIP `hl7:"opt=R"`
+	NTE []NTE
+}
+
+// The standard ScheduleGroup (SRR)
+type ScheduleGroup struct {
+	SCH       SCH `hl7:"opt=R"`
+	NTE       []NTE
+	Patient   []SchedulingPatientGroup
+	Resources []ResourceGroup `hl7:"opt=R"`
+}
+
+// The standard ResourceGroup (SRM), which carries the preferences of each
+// resource requested.
+type RequestResourceGroup struct {
+	RGS       RGS `hl7:"opt=R"`
+	Services  []RequestServiceGroup
+	General   []RequestGeneralResourceGroup
+	Personnel []RequestPersonnelResourceGroup
+	Locations []RequestLocationResourceGroup
+}
+
+// The standard ServiceGroup (SRM)
+type RequestServiceGroup struct {
+	AIS AIS `hl7:"opt=R"`
+	APR APR
+	NTE []NTE
+}
+
+// The standard GeneralResourceGroup (SRM)
+type RequestGeneralResourceGroup struct {
+	AIG AIG `hl7:"opt=R"`
+	APR APR
+	NTE []NTE
+}
+
+// The standard PersonnelResourceGroup (SRM)
+type RequestPersonnelResourceGroup struct {
+	AIP AIP `hl7:"opt=R"`
+	APR APR
+	NTE []NTE
+}
+
+// The standard LocationResourceGroup (SRM)
+type RequestLocationResourceGroup struct {
+	AIL AIL `hl7:"opt=R"`
+	APR APR
+	NTE []NTE
+}
+
+// The standard RecordGroup (MFN_M02)
+type StaffRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	STF STF `hl7:"opt=R"`
+	PRA PRA
+}
+
+// The standard RecordGroup (MFN_M04)
+type ChargeRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	CDM CDM `hl7:"opt=R"`
+	PRC []PRC
+}
+
+// The standard RecordGroup (MFN_M05)
+type LocationRecordGroup struct {
+	MFE         MFE `hl7:"opt=R"`
+	LOC         LOC `hl7:"opt=R"`
+	LCH         []LCH
+	LRL         []LRL
+	Departments []LocationDepartmentGroup `hl7:"opt=R"`
+}
+
+// The standard DepartmentGroup (MFN_M05)
+type LocationDepartmentGroup struct {
+	LDP LDP `hl7:"opt=R"`
+	LCH []LCH
+	LCC []LCC
+}
+
+// The standard RecordGroup (MFN_M08)
+type NumericObservationRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	OM1 OM1 `hl7:"opt=R"`
+	OM2 OM2
+	OM3 OM3
+	OM4 OM4
+}
+
+// The standard RecordGroup (MFN_M09)
+type CategoricalObservationRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	OM1 OM1 `hl7:"opt=R"`
+	OM3 OM3
+	OM4 OM4
+}
+
+// The standard RecordGroup (MFN_M10)
+type BatteryRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	OM1 OM1 `hl7:"opt=R"`
+	OM5 OM5
+	OM4 []OM4
+}
+
+// The standard RecordGroup (MFN_M11)
+type CalculatedObservationRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	OM1 OM1 `hl7:"opt=R"`
+	OM6 OM6
+}
+
+// The standard RecordGroup (MFN_M12)
+type ServiceAttributeRecordGroup struct {
+	MFE MFE `hl7:"opt=R"`
+	OM7 OM7 `hl7:"opt=R"`
+	PRC []PRC
+}
@@ -0,0 +1,369 @@
+/*
+This module contains the standard for segments found in master file messages.
+Primarily, MFNs and MFKs.
+*/
+package faraday
+
+import "iter"
+
+// The standard MFI segment
+type MFI struct {
+	MasterFileIdentifier            CE `hl7:"opt=R,tbl=0175"`
+	MasterFileApplicationIdentifier HD
+	FileLevelEventCode              ID `hl7:"opt=R,tbl=0178"`
+	EnteredDateTime                 TS
+	EffectiveDateTime               TS
+	ResponseLevelCode               ID `hl7:"opt=R,tbl=0179"`
+}
+
+/*
+The primary key of a record varies with the master file, e.g. CE for staff
+or PL for locations, so like OBX.5 it is kept as FT. The key is also the first
+field of the record's payload segment, which has its proper type.
+*/
+// The standard MFE segment
+type MFE struct {
+	RecordLevelEventCode ID `hl7:"opt=R,tbl=0180"`
+	MFNControlId         ST `hl7:"opt=C"`
+	EffectiveDateTime    TS
+	PrimaryKeyValue      FT `hl7:"opt=R"`
+}
+
+// The standard MFA segment
+type MFA struct {
+	RecordLevelEventCode ID `hl7:"opt=R,tbl=0180"`
+	MFNControlId         ST `hl7:"opt=C"`
+	EventCompletionDate  TS
+	ErrorReturnCode      CE `hl7:"opt=R,tbl=0181"`
+	PrimaryKeyValue      FT `hl7:"opt=R"`
+}
+
+// The standard STF segment
+type STF struct {
+	PrimaryKeyValue          CE   `hl7:"opt=R"`
+	StaffIdCode              []CE `hl7:"rep=Y"`
+	StaffName                XPN
+	StaffType                []IS `hl7:"rep=Y"`
+	Sex                      IS   `hl7:"tbl=0001"`
+	DOB                      TS
+	ActiveInactiveFlag       ID       `hl7:"tbl=0183"`
+	Department               []CE     `hl7:"rep=Y"`
+	HospitalService          []CE     `hl7:"rep=Y"`
+	Phone                    []TN     `hl7:"rep=Y"`
+	OfficeHomeAddress        []AD     `hl7:"rep=Y"`
+	ActivationDate           []CM_DIN `hl7:"rep=Y"`
+	InactivationDate         []CM_DIN `hl7:"rep=Y"`
+	BackupPersonId           []CE     `hl7:"rep=Y"`
+	EmailAddress             []ST     `hl7:"rep=Y"`
+	PreferredMethodOfContact ID       `hl7:"tbl=0185"`
+	MaritalStatus            IS
+	JobTitle                 ST
+	JobCodeClass             JCC
+	EmploymentStatus         IS
+	AdditionalInsuredOnAuto  ID
+	DriversLicenseNumber     DLN
+	CopyAutoIns              ID
+	AutoInsExpires           DT
+	DateLastDMVReview        DT
+	DateNextDMVReview        DT
+}
+
+// The standard PRA segment
+type PRA struct {
+	PrimaryKeyValue       CE   `hl7:"opt=R"`
+	PractitionerGroup     []CE `hl7:"rep=Y"`
+	PractitionerCategory  []IS `hl7:"rep=Y"`
+	ProviderBilling       ID
+	Specialty             []CM_SPD `hl7:"rep=Y"`
+	PractitionerIdNumbers []CM_PLN `hl7:"rep=Y"`
+	Privileges            []CM_PIP `hl7:"rep=Y"`
+	DateEnteredPractice   DT
+}
+
+// The standard LOC segment
+type LOC struct {
+	PrimaryKeyValue     PL `hl7:"opt=R"`
+	LocationDescription ST
+	LocationType        []IS `hl7:"opt=R,rep=Y"`
+	OrganizationName    XON
+	LocationAddress     XAD
+	LocationPhone       []XTN `hl7:"rep=Y"`
+	LicenseNumber       []CE  `hl7:"rep=Y"`
+	LocationEquipment   []ID  `hl7:"rep=Y"`
+}
+
+// The standard LCH segment
+type LCH struct {
+	PrimaryKeyValue             PL `hl7:"opt=R"`
+	SegmentActionCode           ID `hl7:"tbl=0206"`
+	SegmentUniqueKey            EI
+	LocationCharacteristicId    CE `hl7:"opt=R"`
+	LocationCharacteristicValue CE `hl7:"opt=R"`
+}
+
+// The standard LRL segment
+type LRL struct {
+	PrimaryKeyValue                         PL `hl7:"opt=R"`
+	SegmentActionCode                       ID `hl7:"tbl=0206"`
+	SegmentUniqueKey                        EI
+	LocationRelationshipId                  CE  `hl7:"opt=R"`
+	OrganizationalLocationRelationshipValue XON `hl7:"opt=C"`
+	PatientLocationRelationshipValue        PL  `hl7:"opt=C"`
+}
+
+// The standard LDP segment
+type LDP struct {
+	PrimaryKeyValue     PL   `hl7:"opt=R"`
+	LocationDepartment  CE   `hl7:"opt=R"`
+	LocationService     []IS `hl7:"rep=Y"`
+	SpecialtyType       []CE `hl7:"rep=Y"`
+	ValidPatientClasses []IS `hl7:"rep=Y,tbl=0004"`
+	ActiveInactiveFlag  ID   `hl7:"tbl=0183"`
+	ActivationDate      TS
+	InactivationDate    TS
+	InactivatedReason   ST
+	VisitingHours       []VH `hl7:"rep=Y"`
+	ContactPhone        XTN
+}
+
+// The standard LCC segment
+type LCC struct {
+	PrimaryKeyValue    PL   `hl7:"opt=R"`
+	LocationDepartment CE   `hl7:"opt=R"`
+	AccommodationType  []CE `hl7:"rep=Y"`
+	ChargeCode         []CE `hl7:"opt=R,rep=Y"`
+}
+
+// The standard CDM segment
+type CDM struct {
+	PrimaryKeyValue              CE   `hl7:"opt=R"`
+	ChargeCodeAlias              []CE `hl7:"rep=Y"`
+	ChargeDescriptionShort       ST   `hl7:"opt=R"`
+	ChargeDescriptionLong        ST
+	DescriptionOverrideIndicator IS
+	ExplodingCharges             []CE `hl7:"rep=Y"`
+	ProcedureCode                []CE `hl7:"rep=Y"`
+	ActiveInactiveFlag           ID   `hl7:"tbl=0183"`
+	InventoryNumber              []CE `hl7:"rep=Y"`
+	ResourceLoad                 NM
+	ContractNumber               []CK  `hl7:"rep=Y"`
+	ContractOrganization         []XON `hl7:"rep=Y"`
+	RoomFeeIndicator             ID    `hl7:"tbl=0136"`
+}
+
+// The standard PRC segment
+type PRC struct {
+	PrimaryKeyValue     CE   `hl7:"opt=R"`
+	FacilityId          []CE `hl7:"rep=Y"`
+	Department          []CE `hl7:"rep=Y"`
+	ValidPatientClasses []IS `hl7:"rep=Y,tbl=0004"`
+	Price               []CP `hl7:"opt=C,rep=Y"`
+	Formula             []ST `hl7:"rep=Y"`
+	MinimumQuantity     NM
+	MaximumQuantity     NM
+	MinimumPrice        MO
+	MaximumPrice        MO
+	EffectiveDate       TS
+	PriceOverrideFlag   IS
+	BillingCategory     []CE `hl7:"rep=Y"`
+	ChargeableFlag      ID   `hl7:"tbl=0136"`
+	ActiveInactiveFlag  ID   `hl7:"tbl=0183"`
+	Cost                MO
+	ChargeOnIndicator   IS
+}
+
+// The standard OM1 segment
+type OM1 struct {
+	SequenceNumber                  NM   `hl7:"opt=R"`
+	ProducerTestId                  CE   `hl7:"opt=R"`
+	PermittedDataTypes              []ID `hl7:"rep=Y,tbl=0125"`
+	SpecimenRequired                ID   `hl7:"opt=R,tbl=0136"`
+	ProducerId                      CE   `hl7:"opt=R"`
+	ObservationDescription          TX
+	OtherTestIds                    CE
+	OtherNames                      []ST `hl7:"opt=R,rep=Y"`
+	PreferredReportName             ST
+	PreferredShortName              ST
+	PreferredLongName               ST
+	Orderability                    ID   `hl7:"tbl=0136"`
+	InstrumentIdentity              []CE `hl7:"rep=Y"`
+	MethodCode                      []CE `hl7:"rep=Y"`
+	Portable                        ID   `hl7:"tbl=0136"`
+	ProducingDepartment             []CE `hl7:"rep=Y"`
+	SectionPhoneNumber              TN
+	NatureOfTest                    IS `hl7:"opt=R"`
+	ReportSubheader                 CE
+	ReportDisplayOrder              ST
+	DefinitionChangeDateTime        TS
+	EffectiveChangeDateTime         TS
+	TypicalTurnAroundTime           NM
+	ProcessingTime                  NM
+	ProcessingPriority              []ID `hl7:"rep=Y"`
+	ReportingPriority               ID
+	OutsideSites                    []CE `hl7:"rep=Y"`
+	OutsideSiteAddress              []AD `hl7:"rep=Y"`
+	OutsideSitePhoneNumber          []TN `hl7:"rep=Y"`
+	ConfidentialityCode             IS
+	ObservationsRequiredToInterpret []CE `hl7:"rep=Y"`
+	InterpretationOfObservations    TX
+	Contraindications               []CE `hl7:"rep=Y"`
+	ReflexTests                     []CE `hl7:"rep=Y"`
+	ReflexTestingRules              ST
+	FixedCannedMessage              []CE `hl7:"rep=Y"`
+	PatientPreparation              TX
+	ProcedureMedication             CE
+	FactorsAffectingObservation     TX
+	PerformanceSchedule             []ST `hl7:"rep=Y"`
+	TestMethodsDescription          TX
+	KindOfQuantityObserved          CE
+	PointVersusInterval             CE
+	ChallengeInformation            TX
+	RelationshipModifier            CE
+	TargetAnatomicSite              CE
+	ImagingMeasurementModality      CE
+}
+
+// The standard OM2 segment
+type OM2 struct {
+	SequenceNumber              NM
+	UnitsOfMeasure              CE
+	DecimalPrecisionRange       []NM `hl7:"rep=Y"`
+	SIUnitsOfMeasure            CE
+	SIConversionFactor          TX
+	ReferenceRange              []CM_RFR `hl7:"rep=Y"`
+	CriticalRange               CM_RNG
+	AbsoluteRange               CM_ABS
+	DeltaCheckCriteria          []CM_DLT `hl7:"rep=Y"`
+	MinimumMeaningfulIncrements NM
+}
+
+// The standard OM3 segment
+type OM3 struct {
+	SequenceNumber        NM
+	PreferredCodingSystem CE
+	ValidCodedAnswers     CE
+	NormalTextCodes       []CE `hl7:"rep=Y"`
+	AbnormalTextCodes     []CE `hl7:"rep=Y"`
+	CriticalTextCodes     []CE `hl7:"rep=Y"`
+	ValueType             ID   `hl7:"tbl=0125"`
+}
+
+// The standard OM4 segment
+type OM4 struct {
+	SequenceNumber              NM
+	DerivedSpecimen             ID `hl7:"tbl=0170"`
+	ContainerDescription        TX
+	ContainerVolume             NM
+	ContainerUnits              CE
+	Specimen                    CE
+	Additive                    CE
+	Preparation                 TX
+	SpecialHandlingRequirements TX
+	NormalCollectionVolume      CQ
+	MinimumCollectionVolume     CQ
+	SpecimenRequirements        TX
+	SpecimenPriorities          []ID `hl7:"rep=Y"`
+	SpecimenRetentionTime       CQ
+}
+
+// The standard OM5 segment
+type OM5 struct {
+	SequenceNumber         NM
+	TestsIncludedInBattery []CE `hl7:"rep=Y"`
+	ObservationIdSuffixes  ST
+}
+
+// The standard OM6 segment
+type OM6 struct {
+	SequenceNumber NM
+	DerivationRule TX
+}
+
+// The standard OM7 segment, as of v2.4
+type OM7 struct {
+	SequenceNumber                  NM   `hl7:"opt=R"`
+	UniversalServiceIdentifier      CE   `hl7:"opt=R"`
+	CategoryIdentifier              []CE `hl7:"rep=Y"`
+	CategoryDescription             TX
+	CategorySynonym                 []ST `hl7:"rep=Y"`
+	EffectiveTestServiceStartDate   TS
+	EffectiveTestServiceEndDate     TS
+	TestServiceDefaultDuration      NM
+	TestServiceDefaultDurationUnits CE
+	TestServiceDefaultFrequency     IS
+	ConsentIndicator                ID `hl7:"tbl=0136"`
+	ConsentIdentifier               CE
+	ConsentEffectiveStartDateTime   TS
+	ConsentEffectiveEndDateTime     TS
+	ConsentIntervalQuantity         NM
+	ConsentIntervalUnits            CE
+	ConsentWaitingPeriodQuantity    NM
+	ConsentWaitingPeriodUnits       CE
+	EffectiveDateTimeOfChange       TS
+	EnteredBy                       XCN
+	OrderableAtLocation             []PL `hl7:"rep=Y"`
+	FormularyStatus                 IS
+	SpecialOrderIndicator           ID   `hl7:"tbl=0136"`
+	PrimaryKeyValueCDM              []CE `hl7:"rep=Y"`
+}
+
+// masterFileEntries returns an iterator over the records of a message, pairing
+// the MFE of each record with the whole record.
+func masterFileEntries[G any](records []G, entry func(G) MFE) iter.Seq2[MFE, G] {
+	return func(yield func(MFE, G) bool) {
+		for _, record := range records {
+			if !yield(entry(record), record) {
+				return
+			}
+		}
+	}
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the staff member it adds, updates or deletes, and
+// their practitioner details, if any.
+func (msg MFN_M02) Entries() iter.Seq2[MFE, StaffRecordGroup] {
+	return masterFileEntries(msg.Records, func(r StaffRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the charge and its prices.
+func (msg MFN_M04) Entries() iter.Seq2[MFE, ChargeRecordGroup] {
+	return masterFileEntries(msg.Records, func(r ChargeRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the location, its characteristics and departments.
+func (msg MFN_M05) Entries() iter.Seq2[MFE, LocationRecordGroup] {
+	return masterFileEntries(msg.Records, func(r LocationRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the observation (OM1) and its attributes (OM2 to OM4).
+func (msg MFN_M08) Entries() iter.Seq2[MFE, NumericObservationRecordGroup] {
+	return masterFileEntries(msg.Records, func(r NumericObservationRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the observation (OM1) and its attributes (OM3, OM4).
+func (msg MFN_M09) Entries() iter.Seq2[MFE, CategoricalObservationRecordGroup] {
+	return masterFileEntries(msg.Records, func(r CategoricalObservationRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the battery (OM1), its tests (OM5) and specimens (OM4).
+func (msg MFN_M10) Entries() iter.Seq2[MFE, BatteryRecordGroup] {
+	return masterFileEntries(msg.Records, func(r BatteryRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the observation (OM1) and how it is calculated (OM6).
+func (msg MFN_M11) Entries() iter.Seq2[MFE, CalculatedObservationRecordGroup] {
+	return masterFileEntries(msg.Records, func(r CalculatedObservationRecordGroup) MFE { return r.MFE })
+}
+
+// Entries returns an iterator over the records of the message, pairing each
+// MFE with its record: the service's attributes (OM7) and its prices.
+func (msg MFN_M12) Entries() iter.Seq2[MFE, ServiceAttributeRecordGroup] {
+	return masterFileEntries(msg.Records, func(r ServiceAttributeRecordGroup) MFE { return r.MFE })
+}
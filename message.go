@@ -5,9 +5,9 @@ package faraday
 
 type ORM_O01 struct {
 	MSH     MSH `hl7:"opt=R"`
-	NTE     NTE
+	NTE     []NTE
 	Patient PatientGroup
-	Order   OrderGroup
+	Order   []OrderGroup `hl7:"opt=R"`
 }
 
 type ADT_A01 struct {
@@ -36,3 +36,403 @@ type ORU_R01 struct {
 	Results []ResultGroup `hl7:"opt=R"`
 	DSC     DSC
 }
+
+// RDE_O01 is the structure of RDE^O01 (pharmacy/treatment encoded order).
+type RDE_O01 struct {
+	MSH     MSH `hl7:"opt=R"`
+	NTE     []NTE
+	Patient PharmacyEncodedPatientGroup
+	Order   []PharmacyEncodedOrderGroup `hl7:"opt=R"`
+}
+
+// RDS_O01 is the structure of RDS^O01 (pharmacy/treatment dispense).
+type RDS_O01 struct {
+	MSH     MSH `hl7:"opt=R"`
+	NTE     []NTE
+	Patient PharmacyPatientGroup
+	Order   []PharmacyDispenseOrderGroup `hl7:"opt=R"`
+}
+
+// RGV_O01 is the structure of RGV^O01 (pharmacy/treatment give).
+type RGV_O01 struct {
+	MSH     MSH `hl7:"opt=R"`
+	NTE     []NTE
+	Patient PharmacyPatientGroup
+	Order   []PharmacyGiveOrderGroup `hl7:"opt=R"`
+}
+
+// RAS_O01 is the structure of RAS^O01 (pharmacy/treatment administration).
+type RAS_O01 struct {
+	MSH     MSH `hl7:"opt=R"`
+	NTE     []NTE
+	Patient PharmacyPatientGroup
+	Order   []PharmacyAdministrationOrderGroup `hl7:"opt=R"`
+}
+
+// VXU_V04 is the structure of VXU^V04 (unsolicited vaccination record
+// update).
+type VXU_V04 struct {
+	MSH       MSH `hl7:"opt=R"`
+	PID       PID `hl7:"opt=R"`
+	PD1       PD1
+	NK1       []NK1
+	Visit     PatientVisitGroup
+	Insurance []InsuranceGroup
+	Order     []VaccinationGroup
+}
+
+// SIU_S12 is the structure of the SIU notifications S12 to S24 and S26.
+type SIU_S12 struct {
+	MSH       MSH `hl7:"opt=R"`
+	SCH       SCH `hl7:"opt=R"`
+	NTE       []NTE
+	Patient   []SchedulingPatientGroup
+	Resources []ResourceGroup `hl7:"opt=R"`
+}
+
+// SRM_S01 is the structure of the schedule requests S01 to S11.
+type SRM_S01 struct {
+	MSH       MSH `hl7:"opt=R"`
+	ARQ       ARQ `hl7:"opt=R"`
+	APR       APR
+	NTE       []NTE
+	Patient   []SchedulingPatientGroup
+	Resources []RequestResourceGroup `hl7:"opt=R"`
+}
+
+// SRR_S01 is the structure of the responses to the schedule requests S01 to
+// S11.
+type SRR_S01 struct {
+	MSH      MSH `hl7:"opt=R"`
+	MSA      MSA `hl7:"opt=R"`
+	ERR      ERR
+	Schedule ScheduleGroup
+}
+
+// MFN_M02 is the structure of MFN^M02 (staff and practitioner master file).
+type MFN_M02 struct {
+	MSH     MSH                `hl7:"opt=R"`
+	MFI     MFI                `hl7:"opt=R"`
+	Records []StaffRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M04 is the structure of MFN^M04 (charge description master file).
+type MFN_M04 struct {
+	MSH     MSH                 `hl7:"opt=R"`
+	MFI     MFI                 `hl7:"opt=R"`
+	Records []ChargeRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M05 is the structure of MFN^M05 (patient location master file).
+type MFN_M05 struct {
+	MSH     MSH                   `hl7:"opt=R"`
+	MFI     MFI                   `hl7:"opt=R"`
+	Records []LocationRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M08 is the structure of MFN^M08 (numeric test/observation master
+// file).
+type MFN_M08 struct {
+	MSH     MSH                             `hl7:"opt=R"`
+	MFI     MFI                             `hl7:"opt=R"`
+	Records []NumericObservationRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M09 is the structure of MFN^M09 (categorical test/observation master
+// file).
+type MFN_M09 struct {
+	MSH     MSH                                 `hl7:"opt=R"`
+	MFI     MFI                                 `hl7:"opt=R"`
+	Records []CategoricalObservationRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M10 is the structure of MFN^M10 (test/observation batteries master
+// file).
+type MFN_M10 struct {
+	MSH     MSH                  `hl7:"opt=R"`
+	MFI     MFI                  `hl7:"opt=R"`
+	Records []BatteryRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M11 is the structure of MFN^M11 (calculated test/observation master
+// file).
+type MFN_M11 struct {
+	MSH     MSH                                `hl7:"opt=R"`
+	MFI     MFI                                `hl7:"opt=R"`
+	Records []CalculatedObservationRecordGroup `hl7:"opt=R"`
+}
+
+// MFN_M12 is the structure of MFN^M12 (master file notification of the
+// attributes of tests, observations and services), which is defined as of
+// v2.4.
+type MFN_M12 struct {
+	MSH     MSH                           `hl7:"opt=R"`
+	MFI     MFI                           `hl7:"opt=R"`
+	Records []ServiceAttributeRecordGroup `hl7:"opt=R"`
+}
+
+// MFK is the structure of the acknowledgments of master file notifications,
+// holding an MFA for each record acknowledged.
+type MFK struct {
+	MSH MSH `hl7:"opt=R"`
+	MSA MSA `hl7:"opt=R"`
+	ERR ERR
+	MFI MFI `hl7:"opt=R"`
+	MFA []MFA
+}
+
+// MDM_T01 is the structure of the document notifications T01, T03, T05, T07,
+// T09 and T11, which carry no content.
+type MDM_T01 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PV1 PV1 `hl7:"opt=R"`
+	TXA TXA `hl7:"opt=R"`
+}
+
+// MDM_T02 is the structure of the document notifications T02, T04, T06, T08
+// and T10, which carry the content of the document in OBX segments (see
+// MDM_T02.Document).
+type MDM_T02 struct {
+	MSH MSH   `hl7:"opt=R"`
+	EVN EVN   `hl7:"opt=R"`
+	PID PID   `hl7:"opt=R"`
+	PV1 PV1   `hl7:"opt=R"`
+	TXA TXA   `hl7:"opt=R"`
+	OBX []OBX `hl7:"opt=R"`
+}
+
+// The ADT structures below are those of the trigger events in HL7 table 0003
+// other than A01 and A19 (a query). The events sharing a structure are
+// registered with it, e.g. ADT^A22 decodes into ADT_A21.
+
+// ADT_A02 is the structure of A02 (transfer a patient).
+type ADT_A02 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+}
+
+// ADT_A03 is the structure of A03 (discharge/end visit).
+type ADT_A03 struct {
+	MSH       MSH `hl7:"opt=R"`
+	EVN       EVN `hl7:"opt=R"`
+	PID       PID `hl7:"opt=R"`
+	PD1       PD1
+	PV1       PV1 `hl7:"opt=R"`
+	PV2       PV2
+	DB1       []DB1
+	DG1       []DG1
+	DRG       DRG
+	Procedure []ProcedureGroup
+	OBX       []OBX
+}
+
+// ADT_A05 is the structure of A05 (pre-admit), A14 (pending admit), A28 (add
+// person) and A31 (update person).
+type ADT_A05 struct {
+	MSH       MSH `hl7:"opt=R"`
+	EVN       EVN `hl7:"opt=R"`
+	PID       PID `hl7:"opt=R"`
+	PD1       PD1
+	NK1       []NK1
+	PV1       PV1 `hl7:"opt=R"`
+	PV2       PV2
+	DB1       []DB1
+	OBX       []OBX
+	AL1       []AL1
+	DG1       []DG1
+	DRG       DRG
+	Procedure []ProcedureGroup
+	GT1       []GT1
+	Insurance []InsuranceGroup
+	ACC       ACC
+	UB1       UB1
+	UB2       UB2
+}
+
+// ADT_A06 is the structure of A06 (outpatient to inpatient) and A07
+// (inpatient to outpatient).
+type ADT_A06 struct {
+	MSH       MSH `hl7:"opt=R"`
+	EVN       EVN `hl7:"opt=R"`
+	PID       PID `hl7:"opt=R"`
+	PD1       PD1
+	MRG       MRG
+	NK1       []NK1
+	PV1       PV1 `hl7:"opt=R"`
+	PV2       PV2
+	DB1       []DB1
+	OBX       []OBX
+	AL1       []AL1
+	DG1       []DG1
+	DRG       DRG
+	Procedure []ProcedureGroup
+	GT1       []GT1
+	Insurance []InsuranceGroup
+	ACC       ACC
+	UB1       UB1
+	UB2       UB2
+}
+
+// ADT_A09 is the structure of A09 (patient departing), A10 (patient
+// arriving), A11 (cancel admit) and A12 (cancel transfer).
+type ADT_A09 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+	DG1 []DG1
+}
+
+// ADT_A15 is the structure of A15 (pending transfer).
+type ADT_A15 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+	DG1 []DG1
+}
+
+// ADT_A16 is the structure of A16 (pending discharge).
+type ADT_A16 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+	DG1 []DG1
+	DRG DRG
+}
+
+// ADT_A17 is the structure of A17 (swap patients), which holds both.
+type ADT_A17 struct {
+	MSH          MSH              `hl7:"opt=R"`
+	EVN          EVN              `hl7:"opt=R"`
+	Patient      SwapPatientGroup `hl7:"opt=R"`
+	OtherPatient SwapPatientGroup `hl7:"opt=R"`
+}
+
+// ADT_A18 is the structure of A18 (merge patient information).
+type ADT_A18 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	MRG MRG `hl7:"opt=R"`
+	PV1 PV1 `hl7:"opt=R"`
+}
+
+// ADT_A20 is the structure of A20 (bed status update).
+type ADT_A20 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	NPU NPU `hl7:"opt=R"`
+}
+
+// ADT_A21 is the structure of A21 and A22 (leave of absence), A23 (delete
+// patient record), A25 to A27 (cancel pending discharge, transfer and
+// admit), A29 (delete person) and A32 and A33 (cancel tracking).
+type ADT_A21 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+}
+
+// ADT_A24 is the structure of A24 (link patient information).
+type ADT_A24 struct {
+	MSH          MSH              `hl7:"opt=R"`
+	EVN          EVN              `hl7:"opt=R"`
+	Patient      LinkPatientGroup `hl7:"opt=R"`
+	OtherPatient LinkPatientGroup `hl7:"opt=R"`
+}
+
+// ADT_A30 is the structure of A30 (merge person), A34 to A36 (merge patient
+// ID and/or account number) and A46 to A49 (change patient identifiers).
+type ADT_A30 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	MRG MRG `hl7:"opt=R"`
+}
+
+// ADT_A37 is the structure of A37 (unlink patient information).
+type ADT_A37 struct {
+	MSH          MSH              `hl7:"opt=R"`
+	EVN          EVN              `hl7:"opt=R"`
+	Patient      LinkPatientGroup `hl7:"opt=R"`
+	OtherPatient LinkPatientGroup `hl7:"opt=R"`
+}
+
+// ADT_A38 is the structure of A38 (cancel pre-admit).
+type ADT_A38 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	PV1 PV1 `hl7:"opt=R"`
+	PV2 PV2
+	DB1 []DB1
+	OBX []OBX
+	DG1 []DG1
+	DRG DRG
+}
+
+// ADT_A39 is the structure of A39 to A42 (merge person, patient, account and
+// visit).
+type ADT_A39 struct {
+	MSH     MSH                 `hl7:"opt=R"`
+	EVN     EVN                 `hl7:"opt=R"`
+	Patient []MergePatientGroup `hl7:"opt=R"`
+}
+
+// ADT_A43 is the structure of A43 and A44 (move patient and account
+// information).
+type ADT_A43 struct {
+	MSH     MSH                `hl7:"opt=R"`
+	EVN     EVN                `hl7:"opt=R"`
+	Patient []MovePatientGroup `hl7:"opt=R"`
+}
+
+// ADT_A45 is the structure of A45 (move visit information).
+type ADT_A45 struct {
+	MSH   MSH `hl7:"opt=R"`
+	EVN   EVN `hl7:"opt=R"`
+	PID   PID `hl7:"opt=R"`
+	PD1   PD1
+	Merge []MergeVisitGroup `hl7:"opt=R"`
+}
+
+// ADT_A50 is the structure of A50 (change visit number) and A51 (change
+// alternate visit ID).
+type ADT_A50 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN `hl7:"opt=R"`
+	PID PID `hl7:"opt=R"`
+	PD1 PD1
+	MRG MRG `hl7:"opt=R"`
+	PV1 PV1 `hl7:"opt=R"`
+}
@@ -0,0 +1,418 @@
+package faraday
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// adtMessage returns an ADT message of the given event, with the segments
+// following MSH and EVN.
+func adtMessage(event, structure string, segments ...string) string {
+	msh := "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000001||ADT^" + event + "^" + structure + "|MSG" + event + "|P|2.3\r" +
+		"EVN|" + event + "|20250724000001\r"
+	return msh + strings.Join(segments, "\r")
+}
+
+const (
+	adtPID      = "PID|1||123^^^MRN||DOE^JANE||19800101|F"
+	adtOtherPID = "PID|2||456^^^MRN||ROE^RICHARD||19750101|M"
+	adtPV1      = "PV1|1|I|W1^101^A"
+	adtMRG      = "MRG|999^^^MRN||A999"
+)
+
+func TestDecodeAny_ADT(t *testing.T) {
+	samples := map[reflect.Type]struct {
+		events   []string
+		segments []string
+	}{
+		reflect.TypeFor[ADT_A01](): {[]string{"A01", "A04", "A08", "A13"}, []string{adtPID, adtPV1}},
+		reflect.TypeFor[ADT_A02](): {[]string{"A02"}, []string{adtPID, adtPV1, "PV2||PRIV"}},
+		reflect.TypeFor[ADT_A03](): {[]string{"A03"}, []string{adtPID, adtPV1, "DG1|1|I10|R10.9|||A", "PR1|1||44950^Appendectomy||20250724|A", "ROL|1|AD|SURG|1234^SMITH^JOHN", "OBX|1|NM|WT||70||||||F"}},
+		reflect.TypeFor[ADT_A05](): {[]string{"A05", "A14", "A28", "A31"}, []string{adtPID, "NK1|1|DOE^JOHN", adtPV1, "IN1|1|PLAN1|INS1"}},
+		reflect.TypeFor[ADT_A06](): {[]string{"A06", "A07"}, []string{adtPID, adtMRG, adtPV1}},
+		reflect.TypeFor[ADT_A09](): {[]string{"A09", "A10", "A11", "A12"}, []string{adtPID, adtPV1, "DG1|1|I10|R10.9|||A"}},
+		reflect.TypeFor[ADT_A15](): {[]string{"A15"}, []string{adtPID, adtPV1}},
+		reflect.TypeFor[ADT_A16](): {[]string{"A16"}, []string{adtPID, adtPV1, "DRG|470"}},
+		reflect.TypeFor[ADT_A17](): {[]string{"A17"}, []string{adtPID, adtPV1, adtOtherPID, "PV1|2|I|W1^102^A"}},
+		reflect.TypeFor[ADT_A18](): {[]string{"A18"}, []string{adtPID, adtMRG, adtPV1}},
+		reflect.TypeFor[ADT_A20](): {[]string{"A20"}, []string{"NPU|W1^101^A|H"}},
+		reflect.TypeFor[ADT_A21](): {[]string{"A21", "A22", "A23", "A25", "A26", "A27", "A29", "A32", "A33"}, []string{adtPID, adtPV1}},
+		reflect.TypeFor[ADT_A24](): {[]string{"A24"}, []string{adtPID, adtPV1, adtOtherPID}},
+		reflect.TypeFor[ADT_A30](): {[]string{"A30", "A34", "A35", "A36", "A46", "A47", "A48", "A49"}, []string{adtPID, adtMRG}},
+		reflect.TypeFor[ADT_A37](): {[]string{"A37"}, []string{adtPID, adtOtherPID}},
+		reflect.TypeFor[ADT_A38](): {[]string{"A38"}, []string{adtPID, adtPV1, "DRG|470"}},
+		reflect.TypeFor[ADT_A39](): {[]string{"A39", "A40", "A41", "A42"}, []string{adtPID, adtMRG, adtOtherPID, "MRG|888^^^MRN", "PV1|2|O"}},
+		reflect.TypeFor[ADT_A43](): {[]string{"A43", "A44"}, []string{adtPID, adtMRG, adtOtherPID, "MRG|888^^^MRN"}},
+		reflect.TypeFor[ADT_A45](): {[]string{"A45"}, []string{adtPID, "MRG|999^^^MRN||||V1", adtPV1, "MRG|999^^^MRN||||V2", "PV1|2|O"}},
+		reflect.TypeFor[ADT_A50](): {[]string{"A50", "A51"}, []string{adtPID, "MRG|999^^^MRN||||V1", adtPV1}},
+	}
+
+	covered := map[string]bool{"A19": true} // a query, not an ADT message
+	for typ, sample := range samples {
+		structure := strings.TrimPrefix(typ.Name(), "ADT_")
+		for _, event := range sample.events {
+			covered[event] = true
+			for _, structure := range []string{"", "ADT_" + structure} {
+				dec := NewDecoder(strings.NewReader(adtMessage(event, structure, sample.segments...)))
+				dec.Strict()
+				val, err := dec.DecodeAny()
+				require.NoError(t, err, "ADT^%s", event)
+				require.Equal(t, typ, reflect.TypeOf(val).Elem(), "ADT^%s", event)
+			}
+		}
+	}
+	for event := range EventType {
+		if strings.HasPrefix(string(event), "A") {
+			require.True(t, covered[string(event)], "ADT^%s has no structure", event)
+		}
+	}
+}
+
+func TestDecoder_ADT_A17(t *testing.T) {
+	var msg ADT_A17
+	raw := adtMessage("A17", "", adtPID, adtPV1, "OBX|1|NM|WT||70||||||F", adtOtherPID, "PV1|2|I|W1^102^A")
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	require.Equal(t, ST("123"), msg.Patient.PID.InternalPatientId[0].IdNumber)
+	require.Equal(t, IS("101"), msg.Patient.PV1.AssignedPatientLocation.Room)
+	require.Len(t, msg.Patient.OBX, 1)
+	require.Equal(t, ST("456"), msg.OtherPatient.PID.InternalPatientId[0].IdNumber)
+	require.Equal(t, IS("102"), msg.OtherPatient.PV1.AssignedPatientLocation.Room)
+
+	// both patients are required
+	dec := NewDecoder(strings.NewReader(adtMessage("A17", "", adtPID, adtPV1)))
+	dec.Strict()
+	require.Error(t, dec.Decode(&msg))
+}
+
+func TestDecoder_ADT_Merge(t *testing.T) {
+	var a40 ADT_A39
+	raw := adtMessage("A40", "ADT_A39", adtPID, adtMRG, adtOtherPID, "MRG|888^^^MRN", "PV1|2|O")
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&a40))
+	require.Len(t, a40.Patient, 2)
+	require.Equal(t, ST("999"), a40.Patient[0].MRG.PriorInternalPatientId[0].IdNumber)
+	require.Equal(t, CX{IdNumber: "A999"}, a40.Patient[0].MRG.PriorPatientAccountNumber)
+	require.Equal(t, PV1{}, a40.Patient[0].PV1)
+	require.Equal(t, ST("888"), a40.Patient[1].MRG.PriorInternalPatientId[0].IdNumber)
+	require.Equal(t, IS("O"), a40.Patient[1].PV1.PatientClass)
+
+	// MRG is required in every patient group
+	dec := NewDecoder(strings.NewReader(adtMessage("A40", "ADT_A39", adtPID)))
+	dec.Strict()
+	require.Error(t, dec.Decode(&a40))
+
+	var a34 ADT_A30
+	require.NoError(t, NewDecoder(strings.NewReader(adtMessage("A34", "ADT_A30", adtPID, adtMRG))).Decode(&a34))
+	require.Equal(t, ID("A34"), a34.EVN.EventTypeCode)
+	require.Equal(t, ST("999"), a34.MRG.PriorInternalPatientId[0].IdNumber)
+
+	var a45 ADT_A45
+	raw = adtMessage("A45", "", adtPID, "MRG|999^^^MRN||||V1", adtPV1, "MRG|999^^^MRN||||V2", "PV1|2|O")
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&a45))
+	require.Len(t, a45.Merge, 2)
+	require.Equal(t, ST("V2"), a45.Merge[1].MRG.PriorVisitNumber.IdNumber)
+	require.Equal(t, SI("2"), a45.Merge[1].PV1.SetId)
+}
+
+const (
+	pharmacyMSH = "MSH|^~\\&|PHARM|Hosp|EHR|Hosp|20250724000001||%s|MSG1|P|2.3\r"
+	pharmacyPID = "PID|1||123^^^MRN||DOE^JANE||19800101|F\r"
+	pharmacyORC = "ORC|NW|P100|F100\r"
+	pharmacyRXO = "RXO|RX001^Amoxicillin 500mg^LOCAL|1||CAP^capsule|||||G\r" +
+		"RXR|PO^Oral\r"
+	pharmacyRXE = "RXE|1^Q8H^^20250724080000|RX001^Amoxicillin 500mg^LOCAL|1||CAP^capsule||||G|30|CAP^capsule|2||||||||||||||||30|CAP^capsule|UD\r" +
+		"RXR|PO^Oral\r"
+)
+
+func TestDecodeAny_Pharmacy(t *testing.T) {
+	decode := func(msgType string, segments ...string) any {
+		t.Helper()
+		raw := strings.Replace(pharmacyMSH, "%s", msgType, 1) + strings.Join(segments, "")
+		dec := NewDecoder(strings.NewReader(raw))
+		dec.Strict()
+		val, err := dec.DecodeAny()
+		require.NoError(t, err, msgType)
+		return val
+	}
+
+	rde, ok := decode("RDE^O01", pharmacyPID, "NTE|1||Patient prefers liquids\r", "PV1|1|I\r",
+		"IN1|1|PLAN1|INS1\r", "AL1|1|DA|PCN^Penicillin\r", pharmacyORC, pharmacyRXO,
+		"RXC|B|D5W^Dextrose 5%|100|ML^milliliter\r", "RXC|A|KCL^Potassium chloride|20|MEQ^milliequivalent\r",
+		"NTE|1||Mix well\r", "NTE|2||Protect from light\r",
+		pharmacyRXE, "OBX|1|NM|WT||70|kg|||||F\r").(*RDE_O01)
+	require.True(t, ok)
+	require.Equal(t, ST("P100"), rde.Order[0].ORC.PlacerOrderNumber.EntityIdentifier)
+	require.Equal(t, ID("G"), rde.Order[0].Detail.RXO.AllowSubstitutions)
+	require.Equal(t, ST("Oral"), rde.Order[0].Detail.RXR[0].Route.Text)
+	require.Len(t, rde.Patient.NTE, 1)
+	require.Equal(t, FT("Patient prefers liquids"), rde.Patient.NTE[0].Comment[0])
+	require.Equal(t, IS("I"), rde.Patient.Visit.PV1.PatientClass)
+	require.Len(t, rde.Patient.Insurance, 1)
+	require.Len(t, rde.Patient.AL1, 1)
+	components := rde.Order[0].Detail.Components
+	require.Len(t, components.RXC, 2)
+	require.Equal(t, ID("B"), components.RXC[0].ComponentType)
+	require.Equal(t, ID("A"), components.RXC[1].ComponentType)
+	require.Len(t, components.NTE, 2)
+	require.Equal(t, FT("Protect from light"), components.NTE[1].Comment[0])
+	require.Empty(t, rde.Order[0].Detail.NTE)
+	require.Equal(t, CE{Identifier: "RX001", Text: "Amoxicillin 500mg", CodingSystem: "LOCAL"}, rde.Order[0].RXE.GiveCode)
+	require.Equal(t, ID("UD"), rde.Order[0].RXE.DispensePackageMethod)
+	require.Len(t, rde.Order[0].RXR, 1)
+	require.Len(t, rde.Order[0].Results, 1)
+
+	rds, ok := decode("RDS^O01", pharmacyPID, "AL1|1|DA|PCN^Penicillin\r", pharmacyORC, pharmacyRXE,
+		"RXD|1|RX001^Amoxicillin 500mg^LOCAL|20250724090000|30|CAP^capsule||RX12345||||N||^^^Main Pharmacy^^^^^1 Main St^^Springfield\r",
+		"RXR|PO^Oral\r").(*RDS_O01)
+	require.True(t, ok)
+	require.Len(t, rds.Patient.AL1, 1)
+	require.Equal(t, NM("30"), rds.Order[0].Encoding.RXE.DispenseAmount)
+	require.Equal(t, ST("RX12345"), rds.Order[0].RXD.PrescriptionNumber)
+	require.Equal(t, ST("Springfield"), rds.Order[0].RXD.DispenseToLocation.City)
+	require.Equal(t, IS("Main Pharmacy"), rds.Order[0].RXD.DispenseToLocation.Facility.NamespaceId)
+	require.Len(t, rds.Order[0].RXR, 1)
+
+	rgv, ok := decode("RGV^O01", pharmacyPID, pharmacyORC, pharmacyRXE,
+		"RXG|1||1^Q8H^^20250724080000|RX001^Amoxicillin 500mg^LOCAL|1||CAP^capsule\r", "RXR|PO^Oral\r",
+		"RXG|2||1^Q8H^^20250724160000|RX001^Amoxicillin 500mg^LOCAL|1||CAP^capsule\r", "RXR|PO^Oral\r").(*RGV_O01)
+	require.True(t, ok)
+	require.Len(t, rgv.Order[0].Give, 2)
+	require.Equal(t, TS("20250724160000"), rgv.Order[0].Give[1].RXG.QuantityTiming.StartDateTime)
+
+	ras, ok := decode("RAS^O01", pharmacyPID, pharmacyORC,
+		"RXA|1|1|20250724080000|20250724080500|RX001^Amoxicillin 500mg^LOCAL|1|CAP^capsule||||||||LOT1||MFR^Maker|||CP|A\r",
+		"RXR|PO^Oral\r").(*RAS_O01)
+	require.True(t, ok)
+	require.Equal(t, ID("CP"), ras.Order[0].RXA[0].CompletionStatus)
+	require.Equal(t, []ST{"LOT1"}, ras.Order[0].RXA[0].SubstanceLotNumber)
+	require.Equal(t, CE{Identifier: "PO", Text: "Oral"}, ras.Order[0].RXR.Route)
+
+	vxu, ok := decode("VXU^V04", pharmacyPID, "NK1|1|DOE^JOHN\r",
+		"RXA|0|1|20250724|20250724|08^Hep B^CVX|0.5|ML^milliliter||||||||LOT2||MSD^Merck|||CP\r",
+		"RXR|IM^Intramuscular|LD^Left deltoid\r",
+		"OBX|1|CE|64994-7^Eligibility^LN||V02^VFC eligible||||||F\r").(*VXU_V04)
+	require.True(t, ok)
+	require.Len(t, vxu.NK1, 1)
+	require.Len(t, vxu.Order, 1)
+	require.Equal(t, ST("Hep B"), vxu.Order[0].RXA.AdministeredCode.Text)
+	require.Equal(t, ST("Left deltoid"), vxu.Order[0].RXR.Site.Text)
+	require.Len(t, vxu.Order[0].Results, 1)
+
+	// RXA is required in each vaccination
+	dec := NewDecoder(strings.NewReader(strings.Replace(pharmacyMSH, "%s", "VXU^V04", 1) + pharmacyPID + "ORC|RE\r"))
+	dec.Strict()
+	_, err := dec.DecodeAny()
+	require.Error(t, err)
+}
+
+func TestEncoder_Pharmacy(t *testing.T) {
+	raw := strings.Replace(pharmacyMSH, "%s", "RDE^O01", 1) + pharmacyPID + "PV1|1|I\r" + pharmacyORC + pharmacyRXO + pharmacyRXE
+
+	var msg RDE_O01
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&msg))
+	out, err := Marshal(&msg)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(out))
+}
+
+const (
+	schedulingSCH = "SCH|P200|F200||||NEW^New appointment|FOLLOWUP^Follow-up visit|NORMAL|30|min|^^30^20250801090000^20250801093000|||||1234^SMITH^JOHN||||5678^CLERK^ANN|||||Booked\r"
+	schedulingRGS = "RGS|1|A\r" +
+		"AIS|1|A|99213^Office visit^CPT|20250801090000|||30|min||Booked\r" +
+		"NTE|1||Bring insurance card\r" +
+		"AIG|1|A|ROOM1^Exam room 1|EXAM^Exam room\r" +
+		"AIL|1|A|CLINIC^101^A|EXAM^Exam room|||||30|min||Booked\r" +
+		"AIP|1|A|1234^SMITH^JOHN|ATT^Attending|||||30|min||Booked\r"
+)
+
+func TestDecodeAny_Scheduling(t *testing.T) {
+	decode := func(msgType string, segments ...string) any {
+		t.Helper()
+		raw := "MSH|^~\\&|SCHED|Hosp|EHR|Hosp|20250724000001||" + msgType + "|MSG1|P|2.3\r" + strings.Join(segments, "")
+		dec := NewDecoder(strings.NewReader(raw))
+		dec.Strict()
+		val, err := dec.DecodeAny()
+		require.NoError(t, err, msgType)
+		return val
+	}
+
+	for _, event := range []string{"S12", "S13", "S14", "S15", "S16", "S17", "S18", "S19", "S20", "S21", "S22", "S23", "S24", "S26"} {
+		siu, ok := decode("SIU^"+event, schedulingSCH, pharmacyPID, "PV1|1|O\r", schedulingRGS, "RGS|2|A\r",
+			"AIP|1|A|5678^JONES^MARY|NUR^Nurse\r").(*SIU_S12)
+		require.True(t, ok, "SIU^%s", event)
+		require.Equal(t, ST("P200"), siu.SCH.PlacerAppointmentId.EntityIdentifier)
+		require.Equal(t, TS("20250801090000"), siu.SCH.AppointmentTimingQuantity[0].StartDateTime)
+		require.Equal(t, ST("Booked"), siu.SCH.FillerStatusCode.Identifier)
+		require.Len(t, siu.Patient, 1)
+		require.Equal(t, IS("O"), siu.Patient[0].PV1.PatientClass)
+		require.Len(t, siu.Resources, 2)
+
+		resource := siu.Resources[0]
+		require.Equal(t, ID("A"), resource.RGS.SegmentActionCode)
+		require.Equal(t, ST("99213"), resource.Services[0].AIS.UniversalServiceId.Identifier)
+		require.Len(t, resource.Services[0].NTE, 1)
+		require.Equal(t, ST("ROOM1"), resource.General[0].AIG.ResourceId.Identifier)
+		require.Equal(t, IS("CLINIC"), resource.Locations[0].AIL.LocationResourceId[0].PointOfCare)
+		require.Equal(t, ST("SMITH"), resource.Personnel[0].AIP.PersonnelResourceId[0].FamilyName)
+		require.Equal(t, ST("JONES"), siu.Resources[1].Personnel[0].AIP.PersonnelResourceId[0].FamilyName)
+	}
+	_, ok := LookupMessage("SIU", "S25", "2.3")
+	require.False(t, ok)
+
+	srm, ok := decode("SRM^S01",
+		"ARQ|P200|||||NEW^New appointment|FOLLOWUP|NORMAL|30|min|20250801080000^20250801120000||||1234^SMITH^JOHN||||5678^CLERK^ANN\r",
+		"APR|MORNING^Y\r", pharmacyPID,
+		"RGS|1\r", "AIS|1||99213^Office visit^CPT\r", "APR|||CLINIC^Y\r",
+		"AIP|1||1234^SMITH^JOHN|ATT^Attending\r", "APR||PREF^Y\r",
+		"AIL|1||CLINIC^101^A|EXAM^Exam room\r").(*SRM_S01)
+	require.True(t, ok)
+	require.Equal(t, TS("20250801120000"), srm.ARQ.RequestedStartDateTimeRange[0].EndDateTime)
+	require.Equal(t, SCV{ParameterClass: "MORNING", ParameterValue: "Y"}, srm.APR.TimeSelectionCriteria[0])
+	require.Len(t, srm.Patient, 1)
+	require.Equal(t, IS("CLINIC"), srm.Resources[0].Services[0].APR.LocationSelectionCriteria[0].ParameterClass)
+	require.Equal(t, IS("PREF"), srm.Resources[0].Personnel[0].APR.ResourceSelectionCriteria[0].ParameterClass)
+	require.Equal(t, CE{Identifier: "EXAM", Text: "Exam room"}, srm.Resources[0].Locations[0].AIL.LocationType)
+
+	srr, ok := decode("SRR^S01", "MSA|AA|MSG1\r", schedulingSCH, pharmacyPID, schedulingRGS).(*SRR_S01)
+	require.True(t, ok)
+	require.Equal(t, ID("AA"), srr.MSA.AcknowledgmentCode)
+	require.Equal(t, ST("F200"), srr.Schedule.SCH.FillerAppointmentId.EntityIdentifier)
+	require.Len(t, srr.Schedule.Resources[0].Services, 1)
+
+	srr, ok = decode("SRR^S04", "MSA|AE|MSG1\r").(*SRR_S01)
+	require.True(t, ok)
+	require.Equal(t, SCH{}, srr.Schedule.SCH)
+}
+
+func TestDecodeAny_MasterFile(t *testing.T) {
+	decode := func(msgType string, segments ...string) any {
+		t.Helper()
+		raw := "MSH|^~\\&|MFS|Hosp|EHR|Hosp|20250724000001||" + msgType + "|MSG1|P|2.3\r" + strings.Join(segments, "")
+		dec := NewDecoder(strings.NewReader(raw))
+		dec.Strict()
+		val, err := dec.DecodeAny()
+		require.NoError(t, err, msgType)
+		return val
+	}
+
+	m02, ok := decode("MFN^M02", "MFI|PRA^Practitioner master file|HL7|UPD|||AL\r",
+		"MFE|MAD|1|20250724|1234^SMITH^JOHN\r",
+		"STF|1234^SMITH^JOHN|NPI^1234567890|SMITH^JOHN^A|MD|M|19700101|A|CARD^Cardiology\r",
+		"PRA|1234^SMITH^JOHN|CARDGRP|ATT||Cardiology^ABIM^C^20000101|1234567^MD^IL^20300101\r",
+		"MFE|MDC|2|20250724|5678^JONES^MARY\r",
+		"STF|5678^JONES^MARY|||RN|F||I\r").(*MFN_M02)
+	require.True(t, ok)
+	require.Equal(t, CE{Identifier: "PRA", Text: "Practitioner master file"}, m02.MFI.MasterFileIdentifier)
+	require.Len(t, m02.Records, 2)
+	require.Equal(t, FT("1234^SMITH^JOHN"), m02.Records[0].MFE.PrimaryKeyValue)
+	require.Equal(t, CM_SPD{SpecialtyName: "Cardiology", GoverningBoard: "ABIM", EligibleOrCertified: "C", CertificationDate: "20000101"}, m02.Records[0].PRA.Specialty[0])
+	require.Equal(t, PRA{}, m02.Records[1].PRA)
+
+	var events []ID
+	var staff []ST
+	var groups [][]CE
+	for mfe, record := range m02.Entries() {
+		events = append(events, mfe.RecordLevelEventCode)
+		staff = append(staff, record.STF.StaffName.FamilyName)
+		groups = append(groups, record.PRA.PractitionerGroup)
+	}
+	require.Equal(t, []ID{"MAD", "MDC"}, events)
+	require.Equal(t, []ST{"SMITH", ""}, staff)
+	require.Equal(t, [][]CE{{{Identifier: "CARDGRP"}}, nil}, groups)
+	require.Equal(t, CE{Identifier: "5678", Text: "JONES", CodingSystem: "MARY"}, m02.Records[1].STF.PrimaryKeyValue)
+	for range m02.Entries() {
+		break // stopping early must not panic
+	}
+
+	m05, ok := decode("MFN^M05", "MFI|LOC|HL7|UPD|||NE\r",
+		"MFE|MAD|1||W1^101^A\r",
+		"LOC|W1^101^A|Ward 1, room 101|B^Bed\r",
+		"LCH|W1^101^A|A||IMP^Impairment|WC^Wheelchair\r",
+		"LDP|W1^101^A|MED^Medicine|MED~SUR||I~O|A\r",
+		"LCC|W1^101^A|MED^Medicine||RB^Room and board\r",
+		"LDP|W1^101^A|SUR^Surgery\r").(*MFN_M05)
+	require.True(t, ok)
+	require.Len(t, m05.Records, 1)
+	record := m05.Records[0]
+	require.Equal(t, IS("101"), record.LOC.PrimaryKeyValue.Room)
+	require.Len(t, record.LCH, 1)
+	require.Len(t, record.Departments, 2)
+	require.Equal(t, []IS{"I", "O"}, record.Departments[0].LDP.ValidPatientClasses)
+	require.Equal(t, ST("RB"), record.Departments[0].LCC[0].ChargeCode[0].Identifier)
+	require.Equal(t, ST("SUR"), record.Departments[1].LDP.LocationDepartment.Identifier)
+	for _, record := range m05.Entries() {
+		require.Equal(t, ST("Ward 1, room 101"), record.LOC.LocationDescription)
+	}
+
+	m08, ok := decode("MFN^M08", "MFI|OMA|HL7|REP|||NE\r",
+		"MFE|MAD|1||GLU^Glucose^LN\r",
+		"OM1|1|GLU^Glucose^LN|NM|Y|LAB^Main lab|||GLUCOSE|Glucose|GLU|Glucose, serum|Y||||||A\r",
+		"OM2|2|mg/dL||||70&99^F^18&120~70&110^M|40^400|0&1000\r",
+		"OM4|3|N|Red top|5|mL\r").(*MFN_M08)
+	require.True(t, ok)
+	require.Equal(t, ST("Glucose"), m08.Records[0].OM1.PreferredReportName)
+	require.Equal(t, CM_RFR{NumericRange: CM_RNG{Low: "70", High: "99"}, AdministrativeSex: "F", AgeRange: CM_RNG{Low: "18", High: "120"}}, m08.Records[0].OM2.ReferenceRange[0])
+	require.Equal(t, CM_RNG{Low: "40", High: "400"}, m08.Records[0].OM2.CriticalRange)
+	require.Equal(t, CM_RNG{Low: "0", High: "1000"}, m08.Records[0].OM2.AbsoluteRange.Range)
+	require.Equal(t, TX("Red top"), m08.Records[0].OM4.ContainerDescription)
+	for mfe, record := range m08.Entries() {
+		require.Equal(t, FT("GLU^Glucose^LN"), mfe.PrimaryKeyValue)
+		require.Equal(t, CE{Identifier: "mg/dL"}, record.OM2.UnitsOfMeasure)
+		require.Equal(t, TX("Red top"), record.OM4.ContainerDescription)
+	}
+
+	m10, ok := decode("MFN^M10", "MFI|OMC|HL7|UPD|||NE\r",
+		"MFE|MAD|1||BMP^Basic metabolic panel\r",
+		"OM1|1|BMP^Basic metabolic panel||Y|LAB|||BMP||||||||||A\r",
+		"OM5|2|GLU^Glucose~NA^Sodium\r").(*MFN_M10)
+	require.True(t, ok)
+	require.Len(t, m10.Records[0].OM5.TestsIncludedInBattery, 2)
+
+	mfk, ok := decode("MFK^M02", "MSA|AA|MSG1\r", "MFI|PRA|HL7|UPD|||AL\r",
+		"MFA|MAD|1|20250724|S|1234^SMITH^JOHN\r", "MFA|MDC|2|20250724|U^Unknown staff|5678^JONES^MARY\r").(*MFK)
+	require.True(t, ok)
+	require.Len(t, mfk.MFA, 2)
+	require.Equal(t, ST("U"), mfk.MFA[1].ErrorReturnCode.Identifier)
+	require.Equal(t, FT("5678^JONES^MARY"), mfk.MFA[1].PrimaryKeyValue)
+
+	// every record needs its payload
+	dec := NewDecoder(strings.NewReader("MSH|^~\\&|MFS|Hosp|EHR|Hosp|20250724000001||MFN^M02|MSG1|P|2.3\r" +
+		"MFI|PRA|HL7|UPD|||AL\rMFE|MAD|1||1234\r"))
+	dec.Strict()
+	_, err := dec.DecodeAny()
+	require.Error(t, err)
+}
+
+func TestDecodeAny_MasterFileM12(t *testing.T) {
+	raw := "MSH|^~\\&|MFS|Hosp|EHR|Hosp|20250724000001||MFN^M12|MSG1|P|2.4\r" +
+		"MFI|OMA|HL7|UPD|||NE\r" +
+		"MFE|MAD|1||80048^Basic metabolic panel^C4\r" +
+		"OM7|1|80048^Basic metabolic panel^C4|CHEM^Chemistry||BMP|||||||||||||||||N|N|80048^BMP^CDM\r" +
+		"PRC|80048^BMP^CDM|HOSP|LAB|O~I|125.00&USD^UP\r"
+
+	dec := NewDecoder(strings.NewReader(raw))
+	dec.Strict()
+	val, err := dec.DecodeAny()
+	require.NoError(t, err)
+	m12, ok := val.(*MFN_M12)
+	require.True(t, ok, "%T", val)
+	require.Len(t, m12.Records, 1)
+	for mfe, record := range m12.Entries() {
+		require.Equal(t, ID("MAD"), mfe.RecordLevelEventCode)
+		require.Equal(t, ST("Chemistry"), record.OM7.CategoryIdentifier[0].Text)
+		require.Equal(t, []ST{"BMP"}, record.OM7.CategorySynonym)
+		require.Equal(t, ID("N"), record.OM7.SpecialOrderIndicator)
+		require.Equal(t, ST("CDM"), record.OM7.PrimaryKeyValueCDM[0].CodingSystem)
+		require.Len(t, record.PRC, 1)
+		require.Equal(t, []IS{"O", "I"}, record.PRC[0].ValidPatientClasses)
+	}
+
+	// M12 is not an event of earlier versions
+	_, ok = LookupMessage("MFN", "M12", "2.3")
+	require.False(t, ok)
+	typ, ok := LookupMessage("MFN", "M12", "2.5.1")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeFor[MFN_M12](), typ)
+}
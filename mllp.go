@@ -0,0 +1,85 @@
+package faraday
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+MLLP (the Minimal Lower Layer Protocol) frames every message sent over a TCP
+connection between a start block and an end block:
+
+	<VT> message <FS><CR>
+
+where VT is 0x0B, FS is 0x1C and CR is 0x0D.
+*/
+const (
+	mllpStartBlock = 0x0B
+	mllpEndBlock   = 0x1C
+	mllpTrailer    = 0x0D
+)
+
+// ErrMessageTooLarge is returned when an MLLP frame holds more bytes than
+// allowed.
+var ErrMessageTooLarge = errors.New("mllp: message too large")
+
+// readFrame reads the next MLLP frame from r, skipping anything before its
+// start block. When the frame exceeds maxSize bytes, the rest of it is
+// discarded and the first maxSize bytes are returned with ErrMessageTooLarge,
+// which leaves r at the start of the next frame.
+func readFrame(r *bufio.Reader, maxSize int) ([]byte, error) {
+	if _, err := r.ReadSlice(mllpStartBlock); err != nil {
+		for errors.Is(err, bufio.ErrBufferFull) {
+			_, err = r.ReadSlice(mllpStartBlock)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var msg []byte
+	tooLarge := false
+	for {
+		chunk, err := r.ReadSlice(mllpEndBlock)
+		if err != nil && !errors.Is(err, bufio.ErrBufferFull) {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if err == nil {
+			chunk = chunk[:len(chunk)-1]
+		}
+		if len(msg)+len(chunk) > maxSize {
+			chunk, tooLarge = chunk[:maxSize-len(msg)], true
+		}
+		msg = append(msg, chunk...)
+		if err == nil {
+			break
+		}
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if b != mllpTrailer {
+		return nil, fmt.Errorf("mllp: expected CR after end block, got 0x%02X", b)
+	}
+	if tooLarge {
+		return msg, ErrMessageTooLarge
+	}
+	return msg, nil
+}
+
+// writeFrame writes msg to w as one MLLP frame.
+func writeFrame(w io.Writer, msg []byte) error {
+	frame := make([]byte, 0, len(msg)+3)
+	frame = append(frame, mllpStartBlock)
+	frame = append(frame, msg...)
+	frame = append(frame, mllpEndBlock, mllpTrailer)
+	_, err := w.Write(frame)
+	return err
+}
@@ -0,0 +1,195 @@
+package faraday
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Rat returns the exact value of the number, so that arithmetic on decimal
+// values such as charges doesn't suffer from float rounding.
+func (n NM) Rat() (*big.Rat, error) {
+	s := strings.TrimSpace(string(n))
+	if !isNumeric(s) {
+		return nil, fmt.Errorf("non-numeric value for NM: '%s'", n)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("non-numeric value for NM: '%s'", n)
+	}
+	return r, nil
+}
+
+// Float64 returns the nearest float64 to the number.
+func (n NM) Float64() (float64, error) {
+	r, err := n.Rat()
+	if err != nil {
+		return 0, err
+	}
+	f, _ := r.Float64()
+	return f, nil
+}
+
+// NewNM formats r with the given number of decimal places.
+func NewNM(r *big.Rat, decimals int) NM {
+	return NM(r.FloatString(decimals))
+}
+
+// decimals returns the number of digits after the decimal point.
+func (n NM) decimals() int {
+	if _, frac, ok := strings.Cut(string(n), "."); ok {
+		return len(strings.TrimSpace(frac))
+	}
+	return 0
+}
+
+// isNumeric reports whether s is an optionally signed decimal number, such as
+// -12, +1.5, 0.25 or .5, as allowed for NM.
+func isNumeric(s string) bool {
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	return whole+frac != "" && isDigits(whole) && isDigits(frac)
+}
+
+// addQuantities adds two NM values, keeping the larger number of decimals.
+func addQuantities(a, b NM) (NM, error) {
+	x, err := a.Rat()
+	if err != nil {
+		return "", err
+	}
+	y, err := b.Rat()
+	if err != nil {
+		return "", err
+	}
+	return NewNM(x.Add(x, y), max(a.decimals(), b.decimals())), nil
+}
+
+// Rat returns the exact quantity, see NM.Rat.
+func (q CQ) Rat() (*big.Rat, error) {
+	return q.Quantity.Rat()
+}
+
+// Add sums two quantities, which must be in the same units.
+func (q CQ) Add(other CQ) (CQ, error) {
+	if q.Units.Identifier != other.Units.Identifier {
+		return CQ{}, fmt.Errorf("cannot add %s to %s", other, q)
+	}
+	sum, err := addQuantities(q.Quantity, other.Quantity)
+	if err != nil {
+		return CQ{}, err
+	}
+	return CQ{Quantity: sum, Units: q.Units}, nil
+}
+
+func (q CQ) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s", q.Quantity, q.Units.Identifier))
+}
+
+// Rat returns the exact amount, see NM.Rat.
+func (m MO) Rat() (*big.Rat, error) {
+	return m.Quantity.Rat()
+}
+
+// Add sums two amounts of money, which must be in the same currency.
+func (m MO) Add(other MO) (MO, error) {
+	if m.Denomination != other.Denomination {
+		return MO{}, fmt.Errorf("cannot add %s to %s", other, m)
+	}
+	sum, err := addQuantities(m.Quantity, other.Quantity)
+	if err != nil {
+		return MO{}, err
+	}
+	return MO{Quantity: sum, Denomination: m.Denomination}, nil
+}
+
+func (m MO) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%s %s", m.Quantity, m.Denomination))
+}
+
+/*
+ParseSN parses a structured numeric, either in its component form as sent in
+OBX-5, i.e. comparator^num1^separator^num2, or written out as text: an optional
+comparator, a number and optionally a separator and second number.
+
+	^1^:^128	>=^10	^2^-^4	<^5
+	>=10	<5	100	1:128	2-4	1.5/2	1+	<>0
+*/
+func ParseSN(raw string) (SN, error) {
+	s := strings.TrimSpace(raw)
+	if strings.Contains(s, "^") {
+		return parseSNComponents(raw, s)
+	}
+
+	var sn SN
+	for _, c := range []comparator{GE, LE, NE, GT, LT, EQ} {
+		if strings.HasPrefix(s, string(c)) {
+			sn.Comparator, s = c, s[len(c):]
+			break
+		}
+	}
+
+	// the first number may be signed, so look for a separator after it
+	i := strings.IndexAny(strings.TrimLeft(s, "+-"), string(S+A+D+R))
+	if i < 0 {
+		sn.FirstNum = NM(s)
+	} else {
+		i += len(s) - len(strings.TrimLeft(s, "+-"))
+		sn.FirstNum, sn.Separator, sn.SecondNum = NM(s[:i]), separator(s[i:i+1]), NM(s[i+1:])
+	}
+	return sn, sn.check(raw)
+}
+
+// parseSNComponents parses the component form of a structured numeric.
+func parseSNComponents(raw, s string) (SN, error) {
+	parts := strings.Split(s, "^")
+	if len(parts) > 4 {
+		return SN{}, fmt.Errorf("invalid SN '%s': expected max 4 components, got %d", raw, len(parts))
+	}
+	parts = append(parts, make([]string, 4-len(parts))...)
+	sn := SN{
+		Comparator: comparator(parts[0]),
+		FirstNum:   NM(parts[1]),
+		Separator:  separator(parts[2]),
+		SecondNum:  NM(parts[3]),
+	}
+	switch sn.Comparator {
+	case "", GT, LT, GE, LE, EQ, NE:
+	default:
+		return SN{}, fmt.Errorf("invalid SN '%s': unknown comparator '%s'", raw, sn.Comparator)
+	}
+	switch sn.Separator {
+	case "", S, A, D, R:
+	default:
+		return SN{}, fmt.Errorf("invalid SN '%s': unknown separator '%s'", raw, sn.Separator)
+	}
+	return sn, sn.check(raw)
+}
+
+// check makes sure the numbers of a parsed structured numeric are numeric and
+// fit its comparator and separator.
+func (sn SN) check(raw string) error {
+	if _, err := sn.FirstNum.Rat(); err != nil {
+		return fmt.Errorf("invalid SN '%s': %w", raw, err)
+	}
+	if sn.SecondNum != "" {
+		if sn.Comparator != "" {
+			return fmt.Errorf("invalid SN '%s': comparator with two numbers", raw)
+		}
+		if sn.Separator == "" {
+			return fmt.Errorf("invalid SN '%s': missing separator", raw)
+		}
+		if _, err := sn.SecondNum.Rat(); err != nil {
+			return fmt.Errorf("invalid SN '%s': %w", raw, err)
+		}
+	} else if sn.Separator != "" && sn.Separator != A {
+		return fmt.Errorf("invalid SN '%s': missing second number", raw)
+	}
+	return nil
+}
+
+// String writes the structured numeric out as text, e.g. >=10 or 1:128.
+func (sn SN) String() string {
+	return string(sn.Comparator) + string(sn.FirstNum) + string(sn.Separator) + string(sn.SecondNum)
+}
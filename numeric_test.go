@@ -0,0 +1,134 @@
+package faraday
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNM(t *testing.T) {
+	for in, want := range map[NM]string{"123.792": "15474/125", "-12": "-12", "+1.5": "3/2", ".5": "1/2", "007": "7", " 4 ": "4"} {
+		r, err := in.Rat()
+		require.NoError(t, err, in)
+		require.Equal(t, want, r.RatString(), in)
+	}
+	for _, in := range []NM{"", "-", ".", "1.2.3", "1e5", "1/2", "0x10", "abc", "--1"} {
+		_, err := in.Rat()
+		require.Error(t, err, in)
+		_, err = in.Float64()
+		require.Error(t, err, in)
+	}
+
+	f, err := NM("5.4").Float64()
+	require.NoError(t, err)
+	require.Equal(t, 5.4, f)
+	require.Equal(t, NM("0.30"), NewNM(big.NewRat(3, 10), 2))
+}
+
+func TestSI(t *testing.T) {
+	i, err := SI("3").Int()
+	require.NoError(t, err)
+	require.Equal(t, 3, i)
+	for _, in := range []SI{"", "A", "1.5", "-1"} {
+		_, err := in.Int()
+		require.Error(t, err, in)
+	}
+}
+
+func TestCQ_MO(t *testing.T) {
+	mg := CE{Identifier: "mg"}
+	sum, err := CQ{Quantity: "0.1", Units: mg}.Add(CQ{Quantity: "0.25", Units: mg})
+	require.NoError(t, err)
+	require.Equal(t, CQ{Quantity: "0.35", Units: mg}, sum)
+	require.Equal(t, "0.35 mg", sum.String())
+
+	_, err = sum.Add(CQ{Quantity: "1", Units: CE{Identifier: "g"}})
+	require.EqualError(t, err, "cannot add 1 g to 0.35 mg")
+	_, err = sum.Add(CQ{Quantity: "x", Units: mg})
+	require.Error(t, err)
+
+	total := MO{Quantity: "0", Denomination: "USD"}
+	for range 10 {
+		total, err = total.Add(MO{Quantity: "0.10", Denomination: "USD"})
+		require.NoError(t, err)
+	}
+	require.Equal(t, MO{Quantity: "1.00", Denomination: "USD"}, total)
+	require.Equal(t, "1.00 USD", total.String())
+	_, err = total.Add(MO{Quantity: "1", Denomination: "EUR"})
+	require.Error(t, err)
+}
+
+func TestParseSN(t *testing.T) {
+	tests := map[string]SN{
+		">=10":  {Comparator: GE, FirstNum: "10"},
+		"<5":    {Comparator: LT, FirstNum: "5"},
+		"<>0":   {Comparator: NE, FirstNum: "0"},
+		"100":   {FirstNum: "100"},
+		"-1.5":  {FirstNum: "-1.5"},
+		"1:128": {FirstNum: "1", Separator: R, SecondNum: "128"},
+		"2-4":   {FirstNum: "2", Separator: S, SecondNum: "4"},
+		"-2--1": {FirstNum: "-2", Separator: S, SecondNum: "-1"},
+		"1.5/2": {FirstNum: "1.5", Separator: D, SecondNum: "2"},
+		"2+":    {FirstNum: "2", Separator: A},
+	}
+	for in, want := range tests {
+		sn, err := ParseSN(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, sn, in)
+		require.Equal(t, in, sn.String())
+	}
+	for _, in := range []string{"", ">=", "1:", "a:b", ">1:2", "1:2:3", "=>1"} {
+		_, err := ParseSN(in)
+		require.Error(t, err, in)
+	}
+}
+
+func TestParseSN_Components(t *testing.T) {
+	tests := map[string]SN{
+		"^1^:^128": {FirstNum: "1", Separator: R, SecondNum: "128"},
+		"^2^-^4":   {FirstNum: "2", Separator: S, SecondNum: "4"},
+		"^-2^-^-1": {FirstNum: "-2", Separator: S, SecondNum: "-1"},
+		"^1.5^/^2": {FirstNum: "1.5", Separator: D, SecondNum: "2"},
+		"^2^+":     {FirstNum: "2", Separator: A},
+		">=^10":    {Comparator: GE, FirstNum: "10"},
+		"<^5":      {Comparator: LT, FirstNum: "5"},
+		"<>^0^^":   {Comparator: NE, FirstNum: "0"},
+		"^100":     {FirstNum: "100"},
+		" ^1^:^2 ": {FirstNum: "1", Separator: R, SecondNum: "2"},
+	}
+	for in, want := range tests {
+		sn, err := ParseSN(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, sn, in)
+	}
+	for _, in := range []string{"^", ">=^", "^1^:", "^a^:^b", ">^1^:^2", "^1^:^2^3", "=>^1", "^1^x^2", "^1^^2"} {
+		_, err := ParseSN(in)
+		require.Error(t, err, in)
+	}
+
+	var obx struct {
+		MSH MSH
+		OBX OBX
+	}
+	raw := "MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|1|P|2.3\rOBX|1|SN|TITER||^1^:^128\r"
+	require.NoError(t, NewDecoder(strings.NewReader(raw)).Decode(&obx))
+	sn, err := ParseSN(string(obx.OBX.ObservationValue[0]))
+	require.NoError(t, err)
+	require.Equal(t, SN{FirstNum: "1", Separator: R, SecondNum: "128"}, sn)
+}
+
+func TestSN_Components(t *testing.T) {
+	delims := newDelimiters('|', defaultDelims)
+	var sn SN
+	spec := NewFieldSpec(5, reflect.ValueOf(&sn).Elem())
+	require.NoError(t, spec.parse([]byte("<^10^-^20"), delims, false))
+	require.Equal(t, SN{Comparator: LT, FirstNum: "10", Separator: S, SecondNum: "20"}, sn)
+
+	enc := &Encoder{delims: delims}
+	out, err := enc.encodeValue(reflect.ValueOf(sn), 0)
+	require.NoError(t, err)
+	require.Equal(t, "<^10^-^20", string(out))
+}
@@ -11,20 +11,20 @@ here as the "standard" data type.
 // The standard OBX segment
 type OBX struct {
 	SetId                        SI
-	ValueType                    ID `hl7:"opt=C"`
-	ObservationIdentifier        CE `hl7:"opt=R"`
-	ObservationSubId             ST `hl7:"opt=C"`
-	ObservationValue             FT `hl7:"opt=C,rep=Y"`
+	ValueType                    ID   `hl7:"opt=C,tbl=0125"`
+	ObservationIdentifier        CE   `hl7:"opt=R"`
+	ObservationSubId             ST   `hl7:"opt=C"`
+	ObservationValue             []FT `hl7:"opt=C,rep=Y"`
 	Units                        CE
 	ReferencesRange              ST
-	AbnormalFlags                ID `hl7:"rep=Y5"`
+	AbnormalFlags                []ID `hl7:"rep=Y5,tbl=0078"`
 	Probability                  NM
-	AbnormalTestNature           ID `hl7:"rep=Y"`
-	ResultStatus                 ID `hl7:"opt=R"`
+	AbnormalTestNature           []ID `hl7:"rep=Y"`
+	ResultStatus                 ID   `hl7:"opt=R,tbl=0085"`
 	LastDateObservedNormalValues TS
 	UserDefinedAccessChecks      ST
 	ObservationDateTime          TS
 	ProducerId                   CE
 	ResponsibleObserver          XCN
-	ObservationMethod            CE `hl7:"rep = Y"`
+	ObservationMethod            []CE `hl7:"rep=Y"`
 }
@@ -18,7 +18,7 @@ func fromString(s string) optionality {
 	case "X":
 		return Unused
 	case "O":
-		return Required
+		return Optional
 	default:
 		return Optional
 	}
@@ -5,11 +5,11 @@ package faraday
 
 // The standard ORC segment
 type ORC struct {
-	OrderControl           ID `hl7:"opt=R"`
+	OrderControl           ID `hl7:"opt=R,tbl=0119"`
 	PlacerOrderNumber      EI `hl7:"opt=C"`
 	FillerOrderNumber      EI `hl7:"opt=C"`
 	PlacerGroupNumber      EI
-	OrderStatus            ID
+	OrderStatus            ID `hl7:"tbl=0038"`
 	ResponseFlag           ID
 	QuantityTiming         TQ
 	Parent                 CM_POR
@@ -18,7 +18,7 @@ type ORC struct {
 	VerifiedBy             XCN
 	OrderingProvider       XCN
 	EntryLocation          PL
-	CallbackPhoneNumber    XTN `hl7:"rep=Y2"`
+	CallbackPhoneNumber    []XTN `hl7:"rep=Y2"`
 	EffectiveDateTime      TS
 	OrderControlCodeReason CE
 	EnteringOrganization   CE
@@ -37,14 +37,14 @@ type OBR struct {
 	ObservationDateTime                TS `hl7:"opt=C"`
 	ObservationEndDateTime             TS
 	CollectionVolume                   CQ
-	CollectorIdentifier                XCN `hl7:"rep=Y"`
+	CollectorIdentifier                []XCN `hl7:"rep=Y"`
 	SpecimenActionCode                 ID
 	DangerCode                         CE
 	RelevantClinicalInfo               ST
 	SpecimenReceivedDateTime           TS `hl7:"opt=C"`
 	SpecimenSource                     CM_SPE
-	OrderingProvider                   XCN `hl7:"rep=Y"`
-	OrderCallbackPhoneNumber           XTN `hl7:"rep=Y2"`
+	OrderingProvider                   []XCN `hl7:"rep=Y"`
+	OrderCallbackPhoneNumber           []XTN `hl7:"rep=Y2"`
 	PlacerField1                       ST
 	PlacerField2                       ST
 	FillerField1                       ST
@@ -52,25 +52,183 @@ type OBR struct {
 	StatusChangeDatTime                TS `hl7:"opt=C"`
 	ChargeToPractice                   CM_CHP
 	DiagnosticServiceSectionId         ID
-	ResultStatus                       ID `hl7:"opt=C"`
+	ResultStatus                       ID `hl7:"opt=C,tbl=0123"`
 	ParentResult                       CM_PRE
-	QuantityTiming                     TQ  `hl7:"rep=Y"`
-	ResultCopiesTo                     XCN `hl7:"rep=Y5"`
+	QuantityTiming                     []TQ  `hl7:"rep=Y"`
+	ResultCopiesTo                     []XCN `hl7:"rep=Y5"`
 	Parent                             CM_POR
 	TransportationMode                 ID
-	ReasonForStudy                     CE `hl7:"rep=Y"`
+	ReasonForStudy                     []CE `hl7:"rep=Y"`
 	PrincipalResultInterpreter         CM_OBS
-	AssistantResultInterpreter         CM_OBS `hl7:"rep=Y"`
-	Technician                         CM_OBS `hl7:"rep=Y"`
-	Transcriptionist                   CM_OBS `hl7:"rep=Y"`
+	AssistantResultInterpreter         []CM_OBS `hl7:"rep=Y"`
+	Technician                         []CM_OBS `hl7:"rep=Y"`
+	Transcriptionist                   []CM_OBS `hl7:"rep=Y"`
 	ScheduledDateTime                  TS
 	SampleContainersCount              NM
-	SampleTransportLogistics           CE `hl7:"rep=Y"`
-	CollectorComment                   CE `hl7:"rep=Y"`
+	SampleTransportLogistics           []CE `hl7:"rep=Y"`
+	CollectorComment                   []CE `hl7:"rep=Y"`
 	TransportArrangementResponsibility CE
 	TransportArranged                  ID
 	EscortRequired                     ID
-	PlannedPatientTransportComment     CE `hl7:"rep=Y"`
+	PlannedPatientTransportComment     []CE `hl7:"rep=Y"`
+}
+
+// The standard RXO segment
+type RXO struct {
+	RequestedGiveCode                   CE `hl7:"opt=R"`
+	RequestedGiveAmountMinimum          NM `hl7:"opt=R"`
+	RequestedGiveAmountMaximum          NM
+	RequestedGiveUnits                  CE `hl7:"opt=R"`
+	RequestedDosageForm                 CE
+	PharmacyTreatmentInstructions       []CE `hl7:"rep=Y"`
+	AdministrationInstructions          []CE `hl7:"rep=Y"`
+	DeliverToLocation                   CM_LA1
+	AllowSubstitutions                  ID `hl7:"tbl=0161"`
+	RequestedDispenseCode               CE
+	RequestedDispenseAmount             NM
+	RequestedDispenseUnits              CE
+	NumberOfRefills                     NM
+	OrderingProviderDEANumber           []XCN `hl7:"opt=C,rep=Y"`
+	PharmacistTreatmentSupplierVerifier []XCN `hl7:"opt=C,rep=Y"`
+	NeedsHumanReview                    ID    `hl7:"tbl=0136"`
+	RequestedGivePer                    ST    `hl7:"opt=C"`
+	RequestedGiveStrength               NM
+	RequestedGiveStrengthUnits          CE
+	Indication                          []CE `hl7:"rep=Y"`
+	RequestedGiveRateAmount             ST
+	RequestedGiveRateUnits              CE
+}
+
+// The standard RXR segment
+type RXR struct {
+	Route                CE `hl7:"opt=R"`
+	Site                 CE
+	AdministrationDevice CE
+	AdministrationMethod CE
+}
+
+// The standard RXC segment
+type RXC struct {
+	ComponentType          ID `hl7:"opt=R,tbl=0166"`
+	ComponentCode          CE `hl7:"opt=R"`
+	ComponentAmount        NM `hl7:"opt=R"`
+	ComponentUnits         CE `hl7:"opt=R"`
+	ComponentStrength      NM
+	ComponentStrengthUnits CE
+}
+
+// The standard RXE segment
+type RXE struct {
+	QuantityTiming                      TQ `hl7:"opt=R"`
+	GiveCode                            CE `hl7:"opt=R"`
+	GiveAmountMinimum                   NM `hl7:"opt=R"`
+	GiveAmountMaximum                   NM
+	GiveUnits                           CE `hl7:"opt=R"`
+	GiveDosageForm                      CE
+	AdministrationInstructions          []CE `hl7:"rep=Y"`
+	DeliverToLocation                   CM_LA1
+	SubstitutionStatus                  ID `hl7:"tbl=0167"`
+	DispenseAmount                      NM `hl7:"opt=C"`
+	DispenseUnits                       CE `hl7:"opt=C"`
+	NumberOfRefills                     NM
+	OrderingProviderDEANumber           []XCN `hl7:"opt=C,rep=Y"`
+	PharmacistTreatmentSupplierVerifier []XCN `hl7:"rep=Y"`
+	PrescriptionNumber                  ST    `hl7:"opt=C"`
+	NumberOfRefillsRemaining            NM    `hl7:"opt=C"`
+	NumberOfRefillsDosesDispensed       NM    `hl7:"opt=C"`
+	MostRecentRefillDateTime            TS    `hl7:"opt=C"`
+	TotalDailyDose                      CQ    `hl7:"opt=C"`
+	NeedsHumanReview                    ID    `hl7:"tbl=0136"`
+	SpecialDispensingInstructions       []CE  `hl7:"rep=Y"`
+	GivePer                             ST    `hl7:"opt=C"`
+	GiveRateAmount                      ST
+	GiveRateUnits                       CE
+	GiveStrength                        NM
+	GiveStrengthUnits                   CE
+	GiveIndication                      []CE `hl7:"rep=Y"`
+	DispensePackageSize                 NM
+	DispensePackageSizeUnit             CE
+	DispensePackageMethod               ID `hl7:"tbl=0321"`
+}
+
+// The standard RXD segment
+type RXD struct {
+	DispenseSubIdCounter          NM `hl7:"opt=R"`
+	DispenseGiveCode              CE `hl7:"opt=R"`
+	DispensedDateTime             TS `hl7:"opt=R"`
+	ActualDispenseAmount          NM `hl7:"opt=R"`
+	ActualDispenseUnits           CE `hl7:"opt=C"`
+	ActualDosageForm              CE
+	PrescriptionNumber            ST    `hl7:"opt=R"`
+	NumberOfRefillsRemaining      NM    `hl7:"opt=C"`
+	DispenseNotes                 []ST  `hl7:"rep=Y"`
+	DispensingProvider            []XCN `hl7:"rep=Y"`
+	SubstitutionStatus            ID    `hl7:"tbl=0167"`
+	TotalDailyDose                CQ
+	DispenseToLocation            CM_LA2
+	NeedsHumanReview              ID   `hl7:"tbl=0136"`
+	SpecialDispensingInstructions []CE `hl7:"rep=Y"`
+	ActualStrength                NM
+	ActualStrengthUnit            CE
+	SubstanceLotNumber            []ST `hl7:"rep=Y"`
+	SubstanceExpirationDate       []TS `hl7:"rep=Y"`
+	SubstanceManufacturerName     []CE `hl7:"rep=Y"`
+	Indication                    []CE `hl7:"rep=Y"`
+	DispensePackageSize           NM
+	DispensePackageSizeUnit       CE
+	DispensePackageMethod         ID `hl7:"tbl=0321"`
+}
+
+// The standard RXG segment
+type RXG struct {
+	GiveSubIdCounter                  NM `hl7:"opt=R"`
+	DispenseSubIdCounter              NM
+	QuantityTiming                    TQ `hl7:"opt=R"`
+	GiveCode                          CE `hl7:"opt=R"`
+	GiveAmountMinimum                 NM `hl7:"opt=R"`
+	GiveAmountMaximum                 NM
+	GiveUnits                         CE `hl7:"opt=R"`
+	GiveDosageForm                    CE
+	AdministrationNotes               []CE `hl7:"rep=Y"`
+	SubstitutionStatus                ID   `hl7:"tbl=0167"`
+	DispenseToLocation                CM_LA2
+	NeedsHumanReview                  ID   `hl7:"tbl=0136"`
+	SpecialAdministrationInstructions []CE `hl7:"rep=Y"`
+	GivePer                           ST   `hl7:"opt=C"`
+	GiveRateAmount                    ST
+	GiveRateUnits                     CE
+	GiveStrength                      NM
+	GiveStrengthUnits                 CE
+	SubstanceLotNumber                []ST `hl7:"rep=Y"`
+	SubstanceExpirationDate           []TS `hl7:"rep=Y"`
+	SubstanceManufacturerName         []CE `hl7:"rep=Y"`
+	Indication                        []CE `hl7:"rep=Y"`
+}
+
+// The standard RXA segment
+type RXA struct {
+	GiveSubIdCounter           NM `hl7:"opt=R"`
+	AdministrationSubIdCounter NM `hl7:"opt=R"`
+	StartDateTime              TS `hl7:"opt=R"`
+	EndDateTime                TS `hl7:"opt=R"`
+	AdministeredCode           CE `hl7:"opt=R"`
+	AdministeredAmount         NM `hl7:"opt=R"`
+	AdministeredUnits          CE `hl7:"opt=C"`
+	AdministeredDosageForm     CE
+	AdministrationNotes        []CE  `hl7:"rep=Y"`
+	AdministeringProvider      []XCN `hl7:"rep=Y"`
+	AdministeredAtLocation     CM_LA2
+	AdministeredPer            ST `hl7:"opt=C"`
+	AdministeredStrength       NM
+	AdministeredStrengthUnits  CE
+	SubstanceLotNumber         []ST `hl7:"rep=Y"`
+	SubstanceExpirationDate    []TS `hl7:"rep=Y"`
+	SubstanceManufacturerName  []CE `hl7:"rep=Y"`
+	SubstanceRefusalReason     []CE `hl7:"rep=Y"`
+	Indication                 []CE `hl7:"rep=Y"`
+	CompletionStatus           ID   `hl7:"tbl=0322"`
+	ActionCode                 ID   `hl7:"tbl=0323"`
+	SystemEntryDateTime        TS
 }
 
 // An Order Group--contains an ORC, optionally followed by an OBR and then
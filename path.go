@@ -0,0 +1,344 @@
+package faraday
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+Paths address a value in a message the way HL7 "terser" expressions do:
+
+	SEG[(occurrence)]-field[(repetition)][-component[-subcomponent]]
+
+All indices are 1-based and default to 1, e.g. PID-3(2)-4-1 is the first
+subcomponent of the 4th component of the 2nd repetition of PID-3 in the first
+PID segment, while OBX(3)-5 is the first component of OBX-5 in the 3rd OBX.
+Segment occurrences are counted across the whole message, regardless of the
+groups they belong to.
+*/
+var pathPattern = regexp.MustCompile(`^([A-Z][A-Z0-9]{2})(?:\((\d+)\))?(?:-(\d+)(?:\((\d+)\))?(?:-(\d+)(?:-(\d+))?)?)?$`)
+
+// ParsePath parses a terser-style path into the Location it addresses.
+func ParsePath(path string) (Location, error) {
+	m := pathPattern.FindStringSubmatch(strings.TrimSpace(path))
+	if m == nil {
+		return Location{}, fmt.Errorf("invalid path '%s'", path)
+	}
+	if !knownSegment(m[1]) {
+		return Location{}, fmt.Errorf("invalid path '%s': unknown segment %s", path, m[1])
+	}
+
+	loc := Location{Segment: m[1]}
+	for i, dst := range []*int{&loc.Occurrence, &loc.Field, &loc.Repetition, &loc.Component, &loc.Subcomponent} {
+		n := 1
+		if m[i+2] != "" {
+			n, _ = strconv.Atoi(m[i+2])
+			if n == 0 {
+				return Location{}, fmt.Errorf("invalid path '%s': indices start at 1", path)
+			}
+		} else if dst == &loc.Field {
+			n = 0
+		}
+		*dst = n
+	}
+	return loc, nil
+}
+
+// knownSegment reports whether name is a standard or registered segment, or a
+// Z-segment.
+func knownSegment(name string) bool {
+	return defaultSegments.known(name) || strings.HasPrefix(name, "Z")
+}
+
+// Get returns the value at path in a decoded message struct or Message. Values
+// which are not present in the message are returned as "".
+func Get(msg any, path string) (string, error) {
+	loc, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	if loc.Field == 0 {
+		return "", fmt.Errorf("path '%s' does not address a field", path)
+	}
+	if m, ok := asMessage(msg); ok {
+		return m.get(loc), nil
+	}
+	v, err := messageValue(msg)
+	if err != nil {
+		return "", err
+	}
+	seg, ok := findSegment(v, loc, false)
+	if !ok {
+		return "", nil
+	}
+	leaf, ok := structLeaf(seg, loc, false)
+	if !ok {
+		return "", nil
+	}
+	return leaf.String(), nil
+}
+
+// Exists reports whether the segment, or the non-empty value, at path is
+// present in a decoded message struct or Message.
+func Exists(msg any, path string) bool {
+	loc, err := ParsePath(path)
+	if err != nil {
+		return false
+	}
+	if m, ok := asMessage(msg); ok {
+		if loc.Field == 0 {
+			_, ok := m.segment(loc)
+			return ok
+		}
+		return m.get(loc) != ""
+	}
+	v, err := messageValue(msg)
+	if err != nil {
+		return false
+	}
+	seg, ok := findSegment(v, loc, false)
+	if !ok || loc.Field == 0 {
+		return ok
+	}
+	leaf, ok := structLeaf(seg, loc, false)
+	return ok && leaf.Len() > 0
+}
+
+// Set sets the value at path in a decoded message struct or Message, which
+// must be passed by pointer. Missing repetitions, components and the like are
+// added as needed, as is the next occurrence of a segment, provided the
+// message has room for it.
+func Set(msg any, path string, value string) error {
+	loc, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	if loc.Field == 0 {
+		return fmt.Errorf("path '%s' does not address a field", path)
+	}
+	if m, ok := msg.(*Message); ok {
+		m.set(loc, value)
+		return nil
+	}
+
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("Set: expected non-nil pointer, got %T", msg)
+	}
+	if v = v.Elem(); v.Kind() != reflect.Struct {
+		return fmt.Errorf("Set: not a pointer to struct (got %T)", msg)
+	}
+	seg, ok := findSegment(v, loc, true)
+	if !ok {
+		return fmt.Errorf("Set: no room for %s(%d) in %s", loc.Segment, loc.Occurrence, v.Type())
+	}
+	leaf, ok := structLeaf(seg, loc, true)
+	if !ok {
+		return fmt.Errorf("Set: %s does not exist in %s", path, seg.Type())
+	}
+	leaf.SetString(value)
+	return nil
+}
+
+func asMessage(msg any) (Message, bool) {
+	switch m := msg.(type) {
+	case Message:
+		return m, true
+	case *Message:
+		if m != nil {
+			return *m, true
+		}
+	}
+	return Message{}, false
+}
+
+func messageValue(msg any) (reflect.Value, error) {
+	v := reflect.ValueOf(msg)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, fmt.Errorf("got nil %T", msg)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("not a message struct (got %T)", msg)
+	}
+	return v, nil
+}
+
+func (m Message) segment(loc Location) (int, bool) {
+	n := 0
+	for i, seg := range m.Segments {
+		if seg.Name != loc.Segment {
+			continue
+		}
+		if n++; n == loc.Occurrence {
+			return i, true
+		}
+	}
+	return n, false
+}
+
+func (m Message) get(loc Location) string {
+	i, ok := m.segment(loc)
+	if !ok {
+		return ""
+	}
+	fields := m.Segments[i].Fields
+	if loc.Field > len(fields) {
+		return ""
+	}
+	reps := fields[loc.Field-1].Repetitions
+	if loc.Repetition > len(reps) {
+		return ""
+	}
+	comps := reps[loc.Repetition-1].Components
+	if loc.Component > len(comps) {
+		return ""
+	}
+	subs := comps[loc.Component-1].Subcomponents
+	if loc.Subcomponent > len(subs) {
+		return ""
+	}
+	return string(subs[loc.Subcomponent-1])
+}
+
+func (m *Message) set(loc Location, value string) {
+	i, ok := m.segment(loc)
+	if !ok {
+		for n := i; n < loc.Occurrence; n++ {
+			m.Segments = append(m.Segments, Segment{Name: loc.Segment})
+		}
+		i = len(m.Segments) - 1
+	}
+	seg := &m.Segments[i]
+	seg.Fields = grow(seg.Fields, loc.Field)
+	field := &seg.Fields[loc.Field-1]
+	field.Repetitions = grow(field.Repetitions, loc.Repetition)
+	rep := &field.Repetitions[loc.Repetition-1]
+	rep.Components = grow(rep.Components, loc.Component)
+	comp := &rep.Components[loc.Component-1]
+	comp.Subcomponents = grow(comp.Subcomponents, loc.Subcomponent)
+	comp.Subcomponents[loc.Subcomponent-1] = Subcomponent(value)
+}
+
+func grow[T any](s []T, n int) []T {
+	if len(s) >= n {
+		return s
+	}
+	return append(s, make([]T, n-len(s))...)
+}
+
+// findSegment walks a message struct in message order to the segment
+// addressed by loc. Zero-valued segments are considered absent; when create
+// is set, the next occurrence may be taken from an empty segment field or
+// appended to a segment slice.
+func findSegment(v reflect.Value, loc Location, create bool) (reflect.Value, bool) {
+	f := segmentFinder{loc: loc}
+	if seg, ok := f.walk(v, schemaOf(v.Type())); ok {
+		return seg, true
+	}
+	if create && f.seen == loc.Occurrence-1 && f.spare.IsValid() {
+		if f.spare.Kind() == reflect.Slice {
+			f.spare.Set(reflect.Append(f.spare, reflect.New(f.spare.Type().Elem()).Elem()))
+			return f.spare.Index(f.spare.Len() - 1), true
+		}
+		return f.spare, true
+	}
+	return reflect.Value{}, false
+}
+
+type segmentFinder struct {
+	loc   Location
+	seen  int
+	spare reflect.Value // first place a new occurrence could go
+}
+
+func (f *segmentFinder) walk(v reflect.Value, schema *structSchema) (reflect.Value, bool) {
+	for _, member := range schema.members {
+		field := v.Field(member.index)
+		if member.group {
+			if !member.repeats {
+				if seg, ok := f.walk(field, member.schema); ok {
+					return seg, true
+				}
+				continue
+			}
+			for i := range field.Len() {
+				if seg, ok := f.walk(field.Index(i), member.schema); ok {
+					return seg, true
+				}
+			}
+			continue
+		}
+
+		if member.name != f.loc.Segment {
+			continue
+		}
+		if member.repeats {
+			for i := range field.Len() {
+				if f.seen++; f.seen == f.loc.Occurrence {
+					return field.Index(i), true
+				}
+			}
+			if !f.spare.IsValid() && field.CanSet() {
+				f.spare = field
+			}
+			continue
+		}
+		if field.IsZero() {
+			if !f.spare.IsValid() && field.CanSet() {
+				f.spare = field
+			}
+			continue
+		}
+		if f.seen++; f.seen == f.loc.Occurrence {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// structLeaf walks from a segment struct down to the string value addressed
+// by loc. Missing repetitions are appended when create is set. Composites
+// nested deeper than subcomponents resolve to their first value.
+func structLeaf(seg reflect.Value, loc Location, create bool) (reflect.Value, bool) {
+	if loc.Field > seg.NumField() || !seg.Type().Field(loc.Field-1).IsExported() {
+		return reflect.Value{}, false
+	}
+	v := seg.Field(loc.Field - 1)
+	if v.Kind() == reflect.Slice {
+		if v.Len() < loc.Repetition {
+			if !create {
+				return reflect.Value{}, false
+			}
+			v.Set(reflect.AppendSlice(v, reflect.MakeSlice(v.Type(), loc.Repetition-v.Len(), loc.Repetition-v.Len())))
+		}
+		v = v.Index(loc.Repetition - 1)
+	} else if loc.Repetition > 1 {
+		return reflect.Value{}, false
+	}
+
+	for _, idx := range []int{loc.Component, loc.Subcomponent} {
+		if v.Kind() == reflect.String {
+			if idx > 1 {
+				return reflect.Value{}, false
+			}
+			continue
+		}
+		if v.Kind() != reflect.Struct || idx > v.NumField() {
+			return reflect.Value{}, false
+		}
+		v = v.Field(idx - 1)
+	}
+	for v.Kind() == reflect.Struct && v.NumField() > 0 {
+		v = v.Field(0)
+	}
+	if v.Kind() != reflect.String {
+		return reflect.Value{}, false
+	}
+	return v, true
+}
@@ -0,0 +1,120 @@
+package faraday
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	loc, err := ParsePath("PID-3(2)-4-1")
+	require.NoError(t, err)
+	require.Equal(t, Location{Segment: "PID", Occurrence: 1, Field: 3, Repetition: 2, Component: 4, Subcomponent: 1}, loc)
+
+	loc, err = ParsePath("OBX(3)-5")
+	require.NoError(t, err)
+	require.Equal(t, Location{Segment: "OBX", Occurrence: 3, Field: 5, Repetition: 1, Component: 1, Subcomponent: 1}, loc)
+
+	loc, err = ParsePath("ZPI")
+	require.NoError(t, err)
+	require.Equal(t, Location{Segment: "ZPI", Occurrence: 1, Repetition: 1, Component: 1, Subcomponent: 1}, loc)
+
+	for _, path := range []string{"", "PID-", "pid-3", "PID-0", "PID(0)-3", "XYZ-1", "PID-3-4-1-2"} {
+		_, err := ParsePath(path)
+		require.Error(t, err, path)
+	}
+}
+
+const pathTestMessage = "MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|1|P|2.3\r" +
+	"PID|1||123^^^MRN~456^^^ACCT&1.2.3||DOE^JANE\r" +
+	"OBR|1|||CBC\r" +
+	"OBX|1|NM|WBC||5.4||||||F\r" +
+	"OBX|2|NM|HGB||13.2||||||F\r" +
+	"OBR|2|||BMP\r" +
+	"OBX|1|NM|NA||140||||||F\r"
+
+func TestGet(t *testing.T) {
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&msg))
+	var tree Message
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&tree))
+
+	tests := map[string]string{
+		"MSH-1":        "|",
+		"MSH-9-2":      "R01",
+		"PID-3":        "123",
+		"PID-3(2)-4":   "ACCT",
+		"PID-3(2)-4-2": "1.2.3",
+		"PID-5-2":      "JANE",
+		"OBR(2)-4":     "BMP",
+		"OBX(3)-3":     "NA",
+		"OBX(2)-5":     "13.2",
+		"OBX(4)-5":     "",
+		"PID-3(3)":     "",
+		"NTE-3":        "",
+	}
+	for path, want := range tests {
+		for _, m := range []any{msg, &tree} {
+			got, err := Get(m, path)
+			require.NoError(t, err, path)
+			require.Equal(t, want, got, "%s in %T", path, m)
+		}
+	}
+
+	_, err := Get(msg, "PID")
+	require.Error(t, err)
+	_, err = Get(42, "PID-3")
+	require.Error(t, err)
+}
+
+func TestExists(t *testing.T) {
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&msg))
+	var tree Message
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&tree))
+
+	for _, m := range []any{&msg, tree} {
+		require.True(t, Exists(m, "OBX(3)"))
+		require.True(t, Exists(m, "PID-3(2)-4-2"))
+		require.False(t, Exists(m, "OBX(4)"))
+		require.False(t, Exists(m, "PID-2"))
+		require.False(t, Exists(m, "NTE"))
+		require.False(t, Exists(m, "bad path"))
+	}
+}
+
+func TestSet(t *testing.T) {
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&msg))
+
+	require.NoError(t, Set(&msg, "PID-3(3)-1", "789"))
+	require.NoError(t, Set(&msg, "PID-3(3)-4-2", "2.16.840"))
+	require.NoError(t, Set(&msg, "OBX(2)-5", "13.5"))
+	require.Equal(t,
+		[]CX{
+			{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN"}},
+			{IdNumber: "456", AssigningAuthority: HD{NamespaceId: "ACCT", UniversalId: "1.2.3"}},
+			{IdNumber: "789", AssigningAuthority: HD{UniversalId: "2.16.840"}},
+		},
+		msg.Results[0].Patient.PID.InternalPatientId,
+	)
+	require.Equal(t, []FT{"13.5"}, msg.Results[0].Order[0].Results[1].OBX.ObservationValue)
+
+	// OBX only repeats as part of its group, so there is no room for a 4th one
+	require.Error(t, Set(&msg, "OBX(4)-3", "K"))
+	require.Error(t, Set(msg, "PID-3", "x"))
+	require.Error(t, Set(&msg, "PID-1-2", "x"))
+	require.Error(t, Set(&msg, "PID-1(2)", "x"))
+	require.Error(t, Set(&msg, "ZPI-1", "x"))
+
+	var tree Message
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(pathTestMessage))).Decode(&tree))
+	require.NoError(t, Set(&tree, "PID-3(2)-4-3", "ISO"))
+	require.NoError(t, Set(&tree, "ZPI(2)-2", "custom"))
+
+	out, err := Marshal(tree)
+	require.NoError(t, err)
+	require.Contains(t, string(out), "PID|1||123^^^MRN~456^^^ACCT&1.2.3&ISO||DOE^JANE\r")
+	require.True(t, bytes.HasSuffix(out, []byte("ZPI\rZPI||custom\r")))
+}
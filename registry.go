@@ -0,0 +1,411 @@
+package faraday
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+The message registry maps the message type, trigger event and version in
+MSH-9 and MSH-12 to the structure a message decodes into, which lets
+Decoder.DecodeAny pick the structure itself. It comes populated with the
+structures of this package; RegisterMessage adds site-specific ones.
+
+An empty event or version in a registration matches any, and a version
+matches itself and later versions. The registration for the event wins over
+the structure named in MSH-9.3, which wins over a registration for any event,
+and then the one for the latest version:
+
+	ADT^A04 v2.5.1	(ADT, A04, 2.5.1), (ADT, A04, 2.5), ..., (ADT, A04, ""), (ADT, "", 2.5.1), ...
+*/
+
+type messageKey struct {
+	typ, event, version string
+}
+
+var messageRegistry = struct {
+	sync.RWMutex
+	types map[messageKey]reflect.Type
+}{types: map[messageKey]reflect.Type{}}
+
+func init() {
+	// the ADT structures and the events sharing them
+	for t, events := range map[reflect.Type][]string{
+		reflect.TypeFor[ADT_A01](): {"A01", "A04", "A08", "A13"},
+		reflect.TypeFor[ADT_A02](): {"A02"},
+		reflect.TypeFor[ADT_A03](): {"A03"},
+		reflect.TypeFor[ADT_A05](): {"A05", "A14", "A28", "A31"},
+		reflect.TypeFor[ADT_A06](): {"A06", "A07"},
+		reflect.TypeFor[ADT_A09](): {"A09", "A10", "A11", "A12"},
+		reflect.TypeFor[ADT_A15](): {"A15"},
+		reflect.TypeFor[ADT_A16](): {"A16"},
+		reflect.TypeFor[ADT_A17](): {"A17"},
+		reflect.TypeFor[ADT_A18](): {"A18"},
+		reflect.TypeFor[ADT_A20](): {"A20"},
+		reflect.TypeFor[ADT_A21](): {"A21", "A22", "A23", "A25", "A26", "A27", "A29", "A32", "A33"},
+		reflect.TypeFor[ADT_A24](): {"A24"},
+		reflect.TypeFor[ADT_A30](): {"A30", "A34", "A35", "A36", "A46", "A47", "A48", "A49"},
+		reflect.TypeFor[ADT_A37](): {"A37"},
+		reflect.TypeFor[ADT_A38](): {"A38"},
+		reflect.TypeFor[ADT_A39](): {"A39", "A40", "A41", "A42"},
+		reflect.TypeFor[ADT_A43](): {"A43", "A44"},
+		reflect.TypeFor[ADT_A45](): {"A45"},
+		reflect.TypeFor[ADT_A50](): {"A50", "A51"},
+	} {
+		for _, event := range events {
+			registerMessage(t, "ADT", event, "")
+		}
+	}
+	registerMessage(reflect.TypeFor[ORM_O01](), "ORM", "O01", "")
+	registerMessage(reflect.TypeFor[ORU_R01](), "ORU", "R01", "")
+	registerMessage(reflect.TypeFor[RDE_O01](), "RDE", "O01", "")
+	registerMessage(reflect.TypeFor[RDS_O01](), "RDS", "O01", "")
+	registerMessage(reflect.TypeFor[RGV_O01](), "RGV", "O01", "")
+	registerMessage(reflect.TypeFor[RAS_O01](), "RAS", "O01", "")
+	registerMessage(reflect.TypeFor[VXU_V04](), "VXU", "V04", "")
+	registerMessage(reflect.TypeFor[MFN_M02](), "MFN", "M02", "")
+	registerMessage(reflect.TypeFor[MFN_M04](), "MFN", "M04", "")
+	registerMessage(reflect.TypeFor[MFN_M05](), "MFN", "M05", "")
+	registerMessage(reflect.TypeFor[MFN_M08](), "MFN", "M08", "")
+	registerMessage(reflect.TypeFor[MFN_M09](), "MFN", "M09", "")
+	registerMessage(reflect.TypeFor[MFN_M10](), "MFN", "M10", "")
+	registerMessage(reflect.TypeFor[MFN_M11](), "MFN", "M11", "")
+	registerMessage(reflect.TypeFor[MFN_M12](), "MFN", "M12", "2.4")
+	registerMessage(reflect.TypeFor[MFK](), "MFK", "", "")
+	for _, event := range []string{"T01", "T03", "T05", "T07", "T09", "T11"} {
+		registerMessage(reflect.TypeFor[MDM_T01](), "MDM", event, "")
+	}
+	for _, event := range []string{"T02", "T04", "T06", "T08", "T10"} {
+		registerMessage(reflect.TypeFor[MDM_T02](), "MDM", event, "")
+	}
+	for i := 1; i <= 26; i++ {
+		event := fmt.Sprintf("S%02d", i)
+		switch {
+		case i <= 11:
+			registerMessage(reflect.TypeFor[SRM_S01](), "SRM", event, "")
+			registerMessage(reflect.TypeFor[SRR_S01](), "SRR", event, "")
+		case i != 25: // S25 is a query (SQM)
+			registerMessage(reflect.TypeFor[SIU_S12](), "SIU", event, "")
+		}
+	}
+	registerMessage(reflect.TypeFor[ACK](), "ACK", "", "")
+}
+
+// RegisterMessage registers T as the structure of messages of type msgType
+// (MSH-9.1) and trigger event (MSH-9.2) in version (MSH-12). An empty event
+// or version matches any. Registering the same key again replaces the
+// structure, so the standard ones can be overridden.
+func RegisterMessage[T any](msgType, event, version string) error {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("RegisterMessage: expected struct type, got %s", t)
+	}
+	if msgType == "" {
+		return fmt.Errorf("RegisterMessage: message type is required")
+	}
+	registerMessage(t, msgType, event, version)
+	return nil
+}
+
+func registerMessage(t reflect.Type, msgType, event, version string) {
+	messageRegistry.Lock()
+	defer messageRegistry.Unlock()
+	messageRegistry.types[messageKey{msgType, event, version}] = t
+}
+
+// LookupMessage returns the structure registered for a message type, trigger
+// event and version, if any. Structures registered for an earlier version
+// than the one asked for apply to it, e.g. those for 2.5 to 2.5.1.
+func LookupMessage(msgType, event, version string) (reflect.Type, bool) {
+	if t, ok := lookupEvent(msgType, event, version); ok {
+		return t, true
+	}
+	return lookupEvent(msgType, "", version)
+}
+
+// lookupEvent returns the structure registered for exactly the message type
+// and trigger event, for the latest version no later than version.
+func lookupEvent(msgType, event, version string) (reflect.Type, bool) {
+	messageRegistry.RLock()
+	defer messageRegistry.RUnlock()
+	var found reflect.Type
+	var foundVersion string
+	for key, t := range messageRegistry.types {
+		if key.typ != msgType || key.event != event || compareVersions(key.version, version) > 0 {
+			continue
+		}
+		if found == nil || compareVersions(key.version, foundVersion) > 0 {
+			found, foundVersion = t, key.version
+		}
+	}
+	return found, found != nil
+}
+
+// lookupStructure returns the structure registered for the message structure
+// in MSH-9.3 (e.g. ADT_A01), which names it regardless of the event.
+func lookupStructure(name, version string) (reflect.Type, bool) {
+	typ, event, ok := bytes.Cut([]byte(name), []byte("_"))
+	if !ok {
+		return nil, false
+	}
+	return LookupMessage(string(typ), string(event), version)
+}
+
+// DecodeAny decodes the next message into the structure registered for its
+// MSH-9 and MSH-12 (see RegisterMessage) and returns a pointer to it, e.g.
+// *ADT_A01 for an ADT^A04. Events without a registration of their own decode
+// into the structure named in MSH-9.3, if any. Messages of unregistered types
+// decode into a *Message.
+func (dec *Decoder) DecodeAny() (any, error) {
+	segments, err := dec.readMessage()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DecodeAny: %w", err)
+	}
+	if err := dec.checkHeader(segments); err != nil {
+		return nil, fmt.Errorf("DecodeAny: %w", err)
+	}
+
+	// the registration for the event wins over the structure in MSH-9.3,
+	// which wins over the registration for any event
+	t := reflect.TypeFor[Message]()
+	msgType, event, structure, version := dec.peekHeader(segments[0])
+	if rt, ok := lookupEvent(msgType, event, version); ok && event != "" {
+		t = rt
+	} else if rt, ok := lookupStructure(structure, version); ok {
+		t = rt
+	} else if rt, ok := LookupMessage(msgType, event, version); ok {
+		t = rt
+	}
+
+	v := reflect.New(t)
+	if err := dec.decodeMessage(v.Elem(), segments); err != nil {
+		return v.Interface(), err
+	}
+	return v.Interface(), nil
+}
+
+// peekHeader reads the components of MSH-9 and the version in MSH-12 from a
+// raw MSH segment.
+func (dec *Decoder) peekHeader(header []byte) (msgType, event, structure, version string) {
+	fields := bytes.Split(header, []byte{dec.delims.field})
+	field := func(n int) [][]byte {
+		// fields[0] is the segment name and fields[1] MSH-2, so MSH-n is at n-1
+		if n-1 >= len(fields) {
+			return nil
+		}
+		return bytes.Split(fields[n-1], []byte{dec.delims.component})
+	}
+	component := func(comps [][]byte, n int) string {
+		if n-1 >= len(comps) {
+			return ""
+		}
+		return string(comps[n-1])
+	}
+	msh9 := field(9)
+	return component(msh9, 1), component(msh9, 2), component(msh9, 3), component(field(12), 1)
+}
+
+/*
+Segments are recognised by name: a struct field holds a segment if the bare
+name in its hl7 tag, its type name or its field name is that of a standard
+segment. Custom segments, such as the Z-segments of a vendor, are added with
+RegisterSegment for every Decoder (and the Encoder and Validate), or with
+Decoder.RegisterSegment for one Decoder only. Once registered, they can be
+placed anywhere in a message or group struct:
+
+	type ZPI struct {
+		SetId        SI
+		VipIndicator ID
+	}
+
+	type ADT_A01_Site struct {
+		MSH MSH `hl7:"opt=R"`
+		EVN EVN `hl7:"opt=R"`
+		PID PID `hl7:"opt=R"`
+		ZPI ZPI
+		...
+	}
+
+	faraday.RegisterSegment[ZPI]("ZPI")
+*/
+
+var segmentNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{2}$`)
+
+// segmentRegistry holds the custom segments registered on top of the
+// standard ones, and of its parent if it has one.
+type segmentRegistry struct {
+	parent *segmentRegistry
+
+	mu    sync.RWMutex
+	types map[string]reflect.Type // by segment name
+	names map[reflect.Type]string // by segment type
+	count atomic.Uint64           // registrations so far
+
+	schemas sync.Map // map[schemaKey]*structSchema
+}
+
+type schemaKey struct {
+	typ     reflect.Type
+	version uint64
+}
+
+// defaultSegments holds the segments registered with RegisterSegment.
+var defaultSegments = &segmentRegistry{}
+
+// RegisterSegment registers T as the structure of the custom segment name,
+// e.g. a Z-segment, for all Decoders. It fails if name or T is that of a
+// standard segment, or either is already registered with another.
+func RegisterSegment[T any](name string) error {
+	if err := defaultSegments.register(name, reflect.TypeFor[T]()); err != nil {
+		return fmt.Errorf("RegisterSegment: %w", err)
+	}
+	return nil
+}
+
+// RegisterSegment registers the type of seg, a segment struct or a pointer to
+// one, as the structure of the custom segment name for this Decoder only.
+// Segments registered globally (see RegisterSegment) remain known.
+func (dec *Decoder) RegisterSegment(name string, seg any) error {
+	return registerOwnSegment(&dec.registry, name, seg)
+}
+
+// segments returns the segments known to the Decoder.
+func (dec *Decoder) segments() *segmentRegistry {
+	if dec.registry != nil {
+		return dec.registry
+	}
+	return defaultSegments
+}
+
+// RegisterSegment registers the type of seg, a segment struct or a pointer to
+// one, as the structure of the custom segment name for this Encoder only, as
+// Decoder.RegisterSegment does for a Decoder.
+func (enc *Encoder) RegisterSegment(name string, seg any) error {
+	return registerOwnSegment(&enc.registry, name, seg)
+}
+
+// segments returns the segments known to the Encoder.
+func (enc *Encoder) segments() *segmentRegistry {
+	if enc.registry != nil {
+		return enc.registry
+	}
+	return defaultSegments
+}
+
+// registerOwnSegment registers a segment with the registry of a Decoder or
+// Encoder, creating it on first use.
+func registerOwnSegment(registry **segmentRegistry, name string, seg any) error {
+	t := reflect.TypeOf(seg)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if *registry == nil {
+		*registry = &segmentRegistry{parent: defaultSegments}
+	}
+	if err := (*registry).register(name, t); err != nil {
+		return fmt.Errorf("RegisterSegment: %w", err)
+	}
+	return nil
+}
+
+func (r *segmentRegistry) register(name string, t reflect.Type) error {
+	if !segmentNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid segment name '%s'", name)
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("expected struct type for segment %s, got %v", name, t)
+	}
+	if _, ok := standardSegments[name]; ok {
+		return fmt.Errorf("%s conflicts with the standard segment", name)
+	}
+	if _, ok := standardSegments[t.Name()]; ok {
+		return fmt.Errorf("type %s conflicts with the standard segment", t)
+	}
+
+	// the checks against r itself are made under the lock taken for the
+	// insert, so that concurrent registrations cannot both pass them
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	other, ok := r.types[name]
+	if !ok {
+		other, ok = r.parent.typeOf(name)
+	}
+	if ok && other != t {
+		return fmt.Errorf("segment %s is already registered as %s", name, other)
+	}
+	otherName, ok := r.names[t]
+	if !ok {
+		otherName, ok = r.parent.nameOf(t)
+	}
+	if ok && otherName != name {
+		return fmt.Errorf("type %s is already registered as segment %s", t, otherName)
+	}
+	if r.types == nil {
+		r.types = map[string]reflect.Type{}
+		r.names = map[reflect.Type]string{}
+	}
+	r.types[name] = t
+	r.names[t] = name
+	r.count.Add(1)
+	return nil
+}
+
+// known reports whether name is a standard or registered segment.
+func (r *segmentRegistry) known(name string) bool {
+	if _, ok := standardSegments[name]; ok {
+		return true
+	}
+	_, ok := r.typeOf(name)
+	return ok
+}
+
+// isSegmentType reports whether t is the type of a standard or registered
+// segment.
+func (r *segmentRegistry) isSegmentType(t reflect.Type) bool {
+	if _, ok := standardSegments[t.Name()]; ok {
+		return true
+	}
+	_, ok := r.nameOf(t)
+	return ok
+}
+
+func (r *segmentRegistry) typeOf(name string) (reflect.Type, bool) {
+	for ; r != nil; r = r.parent {
+		r.mu.RLock()
+		t, ok := r.types[name]
+		r.mu.RUnlock()
+		if ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (r *segmentRegistry) nameOf(t reflect.Type) (string, bool) {
+	for ; r != nil; r = r.parent {
+		r.mu.RLock()
+		name, ok := r.names[t]
+		r.mu.RUnlock()
+		if ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// version changes whenever a segment is registered with r or its parents.
+func (r *segmentRegistry) version() uint64 {
+	var v uint64
+	for ; r != nil; r = r.parent {
+		v += r.count.Load()
+	}
+	return v
+}
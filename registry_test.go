@@ -0,0 +1,289 @@
+package faraday
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupMessage(t *testing.T) {
+	typ, ok := LookupMessage("ADT", "A08", "2.3")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeFor[ADT_A01](), typ)
+
+	typ, ok = LookupMessage("ACK", "R01", "2.3")
+	require.True(t, ok)
+	require.Equal(t, reflect.TypeFor[ACK](), typ)
+
+	_, ok = LookupMessage("ADT", "A19", "2.3")
+	require.False(t, ok)
+}
+
+type zsiteMessage struct {
+	MSH MSH `hl7:"opt=R"`
+	PID PID
+}
+
+func TestDecodeAny(t *testing.T) {
+	raw := "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A04|MSG1|P|2.3\r" +
+		"EVN|A04\r" +
+		"PID|1||123^^^MRN||DOE^JANE\r" +
+		"PV1|1|O\r" +
+		"MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724000000||ORU^R01|MSG2|P|2.3\r" +
+		"PID|1||123^^^MRN||DOE^JANE\r" +
+		"MSH|^~\\&|SITE|Hosp|EHR|Hosp|20250724000000||ZSI^Z01|MSG3|P|2.3\r" +
+		"PID|1||123^^^MRN||DOE^JANE\r" +
+		"MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A99^ADT_A01|MSG4|P|2.3\r" +
+		"EVN|A99\r"
+
+	dec := NewDecoder(strings.NewReader(raw))
+	val, err := dec.DecodeAny()
+	require.NoError(t, err)
+	adt, ok := val.(*ADT_A01)
+	require.True(t, ok, "got %T", val)
+	require.Equal(t, ST("MSG1"), adt.MSH.MessageControlId)
+	require.Equal(t, IS("O"), adt.PV1.PatientClass)
+
+	val, err = dec.DecodeAny()
+	require.NoError(t, err)
+	require.IsType(t, &ORU_R01{}, val)
+
+	val, err = dec.DecodeAny()
+	require.NoError(t, err)
+	msg, ok := val.(*Message)
+	require.True(t, ok, "got %T", val)
+	require.Len(t, msg.Segments, 2)
+
+	val, err = dec.DecodeAny()
+	require.NoError(t, err)
+	require.IsType(t, &ADT_A01{}, val)
+
+	_, err = dec.DecodeAny()
+	require.Equal(t, io.EOF, err)
+
+	require.Error(t, RegisterMessage[string]("ZSI", "Z01", ""))
+	require.NoError(t, RegisterMessage[zsiteMessage]("ZSI", "Z01", "2.3"))
+	t.Cleanup(func() {
+		messageRegistry.Lock()
+		delete(messageRegistry.types, messageKey{"ZSI", "Z01", "2.3"})
+		messageRegistry.Unlock()
+	})
+
+	val, err = NewDecoder(strings.NewReader(raw)).DecodeAny()
+	require.NoError(t, err)
+	require.IsType(t, &ADT_A01{}, val)
+
+	dec = NewDecoder(strings.NewReader(raw[strings.Index(raw, "MSH|^~\\&|SITE"):]))
+	val, err = dec.DecodeAny()
+	require.NoError(t, err)
+	site, ok := val.(*zsiteMessage)
+	require.True(t, ok, "got %T", val)
+	require.Equal(t, ST("MSG3"), site.MSH.MessageControlId)
+}
+
+type siteA04 struct {
+	MSH MSH `hl7:"opt=R"`
+	EVN EVN
+	PID PID
+	ZPI ZPI
+}
+
+func TestDecodeAny_EventWinsOverStructure(t *testing.T) {
+	require.NoError(t, RegisterSegment[ZPI]("ZPI"))
+	unregisterSegments(t, "ZPI")
+	require.NoError(t, RegisterMessage[siteA04]("ADT", "A04", ""))
+	t.Cleanup(func() {
+		// restore the standard registration
+		registerMessage(reflect.TypeFor[ADT_A01](), "ADT", "A04", "")
+	})
+
+	raw := "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A04^ADT_A01|MSG1|P|2.3\r" +
+		"EVN|A04\r" +
+		"PID|1||123^^^MRN||DOE^JANE\r" +
+		"ZPI|1|Y\r"
+	val, err := NewDecoder(strings.NewReader(raw)).DecodeAny()
+	require.NoError(t, err)
+	site, ok := val.(*siteA04)
+	require.True(t, ok, "got %T", val)
+	require.Equal(t, ID("Y"), site.ZPI.VipIndicator)
+
+	// events without a registration of their own still use MSH-9.3
+	raw = strings.Replace(raw, "ADT^A04^ADT_A01", "ADT^A99^ADT_A01", 1)
+	val, err = NewDecoder(strings.NewReader(raw)).DecodeAny()
+	require.NoError(t, err)
+	require.IsType(t, &ADT_A01{}, val)
+}
+
+type ZPI struct {
+	SetId        SI
+	VipIndicator ID
+}
+
+type vendorDS struct {
+	SetId  SI
+	Status ST
+}
+
+type zsiteResult struct {
+	OBR OBR `hl7:"opt=R"`
+	ZDS []vendorDS
+	OBX []OBX
+}
+
+type zsiteORU struct {
+	MSH     MSH `hl7:"opt=R"`
+	PID     PID `hl7:"opt=R"`
+	ZPI     ZPI `hl7:"opt=R"`
+	Results []zsiteResult
+}
+
+const zsiteTestMessage = "MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724000000||ORU^R01|MSG1|P|2.3\r" +
+	"PID|1||123^^^MRN||DOE^JANE\r" +
+	"ZPI|1|Y\r" +
+	"OBR|1|||GLU\r" +
+	"ZDS|1|final\r" +
+	"OBX|1|NM|GLU||5.5||||||F\r" +
+	"OBR|2|||NA\r" +
+	"ZDS|1|prelim\r" +
+	"ZDS|2|final\r"
+
+// unregisterSegments removes the segments a test registered globally.
+func unregisterSegments(t *testing.T, names ...string) {
+	t.Cleanup(func() {
+		r := defaultSegments
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, name := range names {
+			delete(r.names, r.types[name])
+			delete(r.types, name)
+		}
+		r.count.Add(1)
+	})
+}
+
+func TestRegisterSegment(t *testing.T) {
+	var msg zsiteORU
+	require.NoError(t, NewDecoder(strings.NewReader(zsiteTestMessage)).Decode(&msg))
+	require.Empty(t, msg.ZPI)
+	require.Empty(t, msg.Results[1].ZDS)
+
+	unregisterSegments(t, "ZPI", "ZDS")
+	require.NoError(t, RegisterSegment[ZPI]("ZPI"))
+	require.NoError(t, RegisterSegment[vendorDS]("ZDS"))
+	require.NoError(t, RegisterSegment[ZPI]("ZPI"))
+
+	require.NoError(t, NewDecoder(strings.NewReader(zsiteTestMessage)).Decode(&msg))
+	require.Equal(t, ZPI{SetId: "1", VipIndicator: "Y"}, msg.ZPI)
+	require.Len(t, msg.Results, 2)
+	require.Equal(t, []vendorDS{{SetId: "1", Status: "final"}}, msg.Results[0].ZDS)
+	require.Len(t, msg.Results[0].OBX, 1)
+	require.Equal(t, []vendorDS{{SetId: "1", Status: "prelim"}, {SetId: "2", Status: "final"}}, msg.Results[1].ZDS)
+
+	var missing []Location
+	for _, issue := range Validate(zsiteORU{}).Errors() {
+		missing = append(missing, issue.Location)
+	}
+	require.Contains(t, missing, Location{Segment: "ZPI"})
+
+	out, err := Marshal(msg)
+	require.NoError(t, err)
+	require.Equal(t, zsiteTestMessage, string(out))
+
+	for _, err := range []error{
+		RegisterSegment[ZPI]("PID"),
+		RegisterSegment[PID]("ZPX"),
+		RegisterSegment[vendorDS]("ZPI"),
+		RegisterSegment[ZPI]("ZPX"),
+		RegisterSegment[ZPI]("zpi"),
+		RegisterSegment[string]("ZST"),
+	} {
+		require.Error(t, err)
+	}
+	require.ErrorContains(t, RegisterSegment[ZPI]("PID"), "conflicts with the standard segment")
+}
+
+func TestRegisterSegment_Concurrent(t *testing.T) {
+	types := []reflect.Type{reflect.TypeFor[ZPI](), reflect.TypeFor[vendorDS]()}
+	for range 50 {
+		r := &segmentRegistry{}
+		var (
+			wg sync.WaitGroup
+			ok atomic.Int32
+		)
+		for _, typ := range types {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if r.register("ZZZ", typ) == nil {
+					ok.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), ok.Load())
+	}
+}
+
+func TestSegmentTypes(t *testing.T) {
+	require.Contains(t, SegmentTypes, "PID")
+	require.NotContains(t, SegmentTypes, "ZPI")
+
+	// changing the copy leaves the registry as it is
+	SegmentTypes["ZPI"] = struct{}{}
+	delete(SegmentTypes, "PID")
+	t.Cleanup(func() {
+		delete(SegmentTypes, "ZPI")
+		SegmentTypes["PID"] = struct{}{}
+	})
+	require.False(t, defaultSegments.known("ZPI"))
+	require.True(t, defaultSegments.known("PID"))
+	require.NoError(t, RegisterSegment[ZPI]("ZPI"))
+	unregisterSegments(t, "ZPI")
+}
+
+func TestDecoder_RegisterSegment(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(zsiteTestMessage + zsiteTestMessage))
+	require.NoError(t, dec.RegisterSegment("ZPI", ZPI{}))
+	require.NoError(t, dec.RegisterSegment("ZDS", &vendorDS{}))
+	require.Error(t, dec.RegisterSegment("OBX", ZPI{}))
+
+	var msg zsiteORU
+	require.NoError(t, dec.Decode(&msg))
+	require.Equal(t, ZPI{SetId: "1", VipIndicator: "Y"}, msg.ZPI)
+	require.Len(t, msg.Results[1].ZDS, 2)
+
+	// other Decoders do not know the segments
+	var other zsiteORU
+	require.NoError(t, NewDecoder(strings.NewReader(zsiteTestMessage)).Decode(&other))
+	require.Empty(t, other.ZPI)
+
+	dec.Strict()
+	require.NoError(t, dec.Decode(&msg))
+}
+
+func TestEncoder_RegisterSegment(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(zsiteTestMessage))
+	require.NoError(t, dec.RegisterSegment("ZPI", ZPI{}))
+	require.NoError(t, dec.RegisterSegment("ZDS", vendorDS{}))
+	var msg zsiteORU
+	require.NoError(t, dec.Decode(&msg))
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	require.NoError(t, enc.RegisterSegment("ZPI", ZPI{}))
+	require.NoError(t, enc.RegisterSegment("ZDS", &vendorDS{}))
+	require.Error(t, enc.RegisterSegment("OBX", ZPI{}))
+	require.NoError(t, enc.Encode(&msg))
+	require.Equal(t, zsiteTestMessage, buf.String())
+
+	// other Encoders do not know the segments
+	encoded, err := Marshal(&msg)
+	require.NoError(t, err)
+	require.NotContains(t, string(encoded), "ZPI")
+}
@@ -0,0 +1,141 @@
+/*
+This module contains the standard for segments found in scheduling messages.
+Primarily, SIUs.
+*/
+package faraday
+
+// The standard SCH segment
+type SCH struct {
+	PlacerAppointmentId       EI `hl7:"opt=C"`
+	FillerAppointmentId       EI `hl7:"opt=C"`
+	OccurrenceNumber          NM `hl7:"opt=C"`
+	PlacerGroupNumber         EI
+	ScheduleId                CE
+	EventReason               CE `hl7:"opt=R"`
+	AppointmentReason         CE
+	AppointmentType           CE
+	AppointmentDuration       NM
+	AppointmentDurationUnits  CE
+	AppointmentTimingQuantity []TQ  `hl7:"opt=R,rep=Y"`
+	PlacerContactPerson       []XCN `hl7:"rep=Y"`
+	PlacerContactPhoneNumber  XTN
+	PlacerContactAddress      []XAD `hl7:"rep=Y"`
+	PlacerContactLocation     PL
+	FillerContactPerson       []XCN `hl7:"opt=R,rep=Y"`
+	FillerContactPhoneNumber  XTN
+	FillerContactAddress      []XAD `hl7:"rep=Y"`
+	FillerContactLocation     PL
+	EnteredByPerson           []XCN `hl7:"opt=R,rep=Y"`
+	EnteredByPhoneNumber      []XTN `hl7:"rep=Y"`
+	EnteredByLocation         PL
+	ParentPlacerAppointmentId EI
+	ParentFillerAppointmentId EI `hl7:"opt=C"`
+	FillerStatusCode          CE `hl7:"tbl=0278"`
+}
+
+// The standard ARQ segment
+type ARQ struct {
+	PlacerAppointmentId         EI `hl7:"opt=R"`
+	FillerAppointmentId         EI `hl7:"opt=C"`
+	OccurrenceNumber            NM `hl7:"opt=C"`
+	PlacerGroupNumber           EI
+	ScheduleId                  CE
+	RequestEventReason          CE
+	AppointmentReason           CE
+	AppointmentType             CE
+	AppointmentDuration         NM
+	AppointmentDurationUnits    CE
+	RequestedStartDateTimeRange []DR `hl7:"rep=Y"`
+	Priority                    ST
+	RepeatingInterval           RI
+	RepeatingIntervalDuration   ST
+	PlacerContactPerson         []XCN `hl7:"opt=R,rep=Y"`
+	PlacerContactPhoneNumber    []XTN `hl7:"rep=Y"`
+	PlacerContactAddress        []XAD `hl7:"rep=Y"`
+	PlacerContactLocation       PL
+	EnteredByPerson             []XCN `hl7:"opt=R,rep=Y"`
+	EnteredByPhoneNumber        []XTN `hl7:"rep=Y"`
+	EnteredByLocation           PL
+	ParentPlacerAppointmentId   EI
+	ParentFillerAppointmentId   EI
+}
+
+// The standard APR segment
+type APR struct {
+	TimeSelectionCriteria     []SCV `hl7:"rep=Y"`
+	ResourceSelectionCriteria []SCV `hl7:"rep=Y"`
+	LocationSelectionCriteria []SCV `hl7:"rep=Y"`
+	SlotSpacingCriteria       NM
+	FillerOverrideCriteria    []SCV `hl7:"rep=Y"`
+}
+
+// The standard RGS segment
+type RGS struct {
+	SetId             SI `hl7:"opt=R"`
+	SegmentActionCode ID `hl7:"opt=C,tbl=0206"`
+	ResourceGroupId   CE
+}
+
+// The standard AIS segment
+type AIS struct {
+	SetId                    SI `hl7:"opt=R"`
+	SegmentActionCode        ID `hl7:"opt=C,tbl=0206"`
+	UniversalServiceId       CE `hl7:"opt=R"`
+	StartDateTime            TS
+	StartDateTimeOffset      NM
+	StartDateTimeOffsetUnits CE
+	Duration                 NM
+	DurationUnits            CE
+	AllowSubstitutionCode    IS
+	FillerStatusCode         CE `hl7:"tbl=0278"`
+}
+
+// The standard AIG segment
+type AIG struct {
+	SetId                    SI `hl7:"opt=R"`
+	SegmentActionCode        ID `hl7:"opt=C,tbl=0206"`
+	ResourceId               CE
+	ResourceType             CE   `hl7:"opt=R"`
+	ResourceGroup            []CE `hl7:"rep=Y"`
+	ResourceQuantity         NM
+	ResourceQuantityUnits    CE
+	StartDateTime            TS
+	StartDateTimeOffset      NM
+	StartDateTimeOffsetUnits CE
+	Duration                 NM
+	DurationUnits            CE
+	AllowSubstitutionCode    IS
+	FillerStatusCode         CE `hl7:"tbl=0278"`
+}
+
+// The standard AIL segment
+type AIL struct {
+	SetId                    SI   `hl7:"opt=R"`
+	SegmentActionCode        ID   `hl7:"opt=C,tbl=0206"`
+	LocationResourceId       []PL `hl7:"opt=C,rep=Y"`
+	LocationType             CE   `hl7:"opt=R"`
+	LocationGroup            CE
+	StartDateTime            TS
+	StartDateTimeOffset      NM
+	StartDateTimeOffsetUnits CE
+	Duration                 NM
+	DurationUnits            CE
+	AllowSubstitutionCode    IS
+	FillerStatusCode         CE `hl7:"tbl=0278"`
+}
+
+// The standard AIP segment
+type AIP struct {
+	SetId                    SI    `hl7:"opt=R"`
+	SegmentActionCode        ID    `hl7:"opt=C,tbl=0206"`
+	PersonnelResourceId      []XCN `hl7:"opt=C,rep=Y"`
+	ResourceRole             CE    `hl7:"opt=R"`
+	ResourceGroup            CE
+	StartDateTime            TS
+	StartDateTimeOffset      NM
+	StartDateTimeOffsetUnits CE
+	Duration                 NM
+	DurationUnits            CE
+	AllowSubstitutionCode    IS
+	FillerStatusCode         CE `hl7:"tbl=0278"`
+}
@@ -0,0 +1,418 @@
+package faraday
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrServerClosed is returned by Server.Serve and ListenAndServe after the
+// server was shut down or closed.
+var ErrServerClosed = errors.New("mllp: Server closed")
+
+// Request is an inbound message received by a Server.
+type Request struct {
+	RemoteAddr net.Addr
+	MSH        MSH    // the decoded header, used to acknowledge the message
+	Raw        []byte // the message as received, without MLLP framing
+}
+
+// Decode decodes the message into val, see Decoder.Decode. Its segments may
+// be as large as the message, which the Server limits to MaxMessageSize.
+func (req *Request) Decode(val any) error {
+	dec := NewDecoder(bytes.NewReader(req.Raw))
+	dec.maxSegment = len(req.Raw) + 1
+	return dec.Decode(val)
+}
+
+/*
+A Handler processes an inbound message and returns the ACK to send back for
+it. Returning a nil ACK sends an automatic accept (AA), and returning an error
+an automatic application error (AE) holding the error text. When the sender
+asked for enhanced mode acknowledgments, the automatic ACKs use commit codes
+(CA, CE) instead.
+
+The context is cancelled when the connection the message was received on is
+closed, which includes the Server being closed.
+*/
+type Handler interface {
+	ServeHL7(ctx context.Context, req *Request) (*ACK, error)
+}
+
+// HandlerFunc lets an ordinary function be used as a Handler.
+type HandlerFunc func(ctx context.Context, req *Request) (*ACK, error)
+
+func (f HandlerFunc) ServeHL7(ctx context.Context, req *Request) (*ACK, error) {
+	return f(ctx, req)
+}
+
+/*
+Server accepts MLLP connections and hands every message received on them to
+its Handler, writing back the ACK for each message before reading the next
+one. Connections are served concurrently.
+
+Only the MSH segment of a message is decoded before calling the Handler.
+Messages whose header cannot be decoded, or which are larger than
+MaxMessageSize, are rejected with an automatic AR (or CR) without calling the
+Handler. An ACK returned by the Handler which cannot be encoded is replaced by
+an automatic AE (or CE), and the error is logged.
+*/
+type Server struct {
+	Addr    string // TCP address to listen on, ":2575" if empty
+	Handler Handler
+
+	// ReadTimeout is the maximum time to receive a message once it started,
+	// IdleTimeout the maximum time to wait for the next message and
+	// WriteTimeout the maximum time to write an ACK. Zero means no timeout.
+	ReadTimeout  time.Duration
+	IdleTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// MaxMessageSize is the maximum size of a message in bytes, 16MB if zero.
+	// Request.Decode reads segments of any size up to it, e.g. an OBX holding
+	// a large document.
+	MaxMessageSize int
+
+	// ErrorLog logs errors which cannot be reported to the sender, using the
+	// log package's standard logger if nil.
+	ErrorLog *log.Logger
+
+	mu         sync.Mutex
+	listeners  map[net.Listener]struct{}
+	conns      map[*serverConn]struct{}
+	inShutdown atomic.Bool
+	wg         sync.WaitGroup
+	ctx        context.Context
+	cancel     context.CancelFunc
+}
+
+// ListenAndServe listens on s.Addr and serves connections until the server is
+// shut down or closed.
+func (s *Server) ListenAndServe() error {
+	if s.inShutdown.Load() {
+		return ErrServerClosed
+	}
+	addr := s.Addr
+	if addr == "" {
+		addr = ":2575"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until the server is shut down or closed,
+// and always returns a non-nil error. l is closed on return.
+func (s *Server) Serve(l net.Listener) error {
+	if s.Handler == nil {
+		l.Close()
+		return errors.New("mllp: Server has no Handler")
+	}
+	if !s.trackListener(l, true) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.trackListener(l, false)
+
+	var delay time.Duration
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if s.inShutdown.Load() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+
+		c := &serverConn{server: s, conn: nc}
+		if !s.trackConn(c, true) {
+			nc.Close()
+			return ErrServerClosed
+		}
+		go c.serve()
+	}
+}
+
+/*
+Shutdown gracefully shuts down the server: it stops accepting connections,
+closes connections waiting for a message and waits for the others to finish
+the message they are handling and send its ACK. If ctx expires first, the
+remaining connections are left open and its error is returned; Close can be
+used to force them shut.
+*/
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.inShutdown.Store(true)
+	s.closeListeners()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		s.closeIdleConns()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close immediately closes the listeners and all connections, cancelling the
+// context of messages being handled.
+func (s *Server) Close() error {
+	s.inShutdown.Store(true)
+	err := s.closeListeners()
+
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) trackListener(l net.Listener, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !add {
+		delete(s.listeners, l)
+		return true
+	}
+	if s.inShutdown.Load() {
+		return false
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[net.Listener]struct{})
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+func (s *Server) trackConn(c *serverConn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !add {
+		delete(s.conns, c)
+		s.wg.Done()
+		return true
+	}
+	if s.inShutdown.Load() {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[*serverConn]struct{})
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	c.ctx, c.cancel = context.WithCancel(s.ctx)
+	s.conns[c] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) closeListeners() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) closeIdleConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c := range s.conns {
+		c.closeIfIdle()
+	}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func (s *Server) maxMessageSize() int {
+	if s.MaxMessageSize > 0 {
+		return s.MaxMessageSize
+	}
+	return maxSegmentSize
+}
+
+// serverConn is a connection accepted by a Server. It is idle while waiting
+// for the next message and active from the first byte of a message until
+// its ACK has been written.
+type serverConn struct {
+	server *Server
+	conn   net.Conn
+
+	// ctx is passed to the Handler, and cancelled once the connection is
+	// closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	active bool
+	closed bool
+}
+
+func (c *serverConn) serve() {
+	defer c.server.trackConn(c, false)
+	defer c.cancel()
+	defer c.conn.Close()
+
+	r := bufio.NewReader(c.conn)
+	for {
+		c.setDeadline(c.server.IdleTimeout)
+		if _, err := r.Peek(1); err != nil || !c.setActive(true) {
+			return
+		}
+
+		c.setDeadline(c.server.ReadTimeout)
+		raw, err := readFrame(r, c.server.maxMessageSize())
+		if err != nil && !errors.Is(err, ErrMessageTooLarge) {
+			return
+		}
+		ack, err := c.server.handle(c.ctx, c.conn.RemoteAddr(), raw, err)
+		if err != nil {
+			c.server.logf("mllp: cannot acknowledge message from %s: %v", c.conn.RemoteAddr(), err)
+			return
+		}
+
+		if c.server.WriteTimeout > 0 {
+			c.conn.SetWriteDeadline(time.Now().Add(c.server.WriteTimeout))
+		}
+		if err := writeFrame(c.conn, ack); err != nil {
+			return
+		}
+		if !c.setActive(false) || c.server.inShutdown.Load() {
+			return
+		}
+	}
+}
+
+func (c *serverConn) setDeadline(timeout time.Duration) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	c.conn.SetReadDeadline(deadline)
+}
+
+// setActive marks the connection as active or idle, and reports false if it
+// was closed in the meantime.
+func (c *serverConn) setActive(active bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active = active
+	return !c.closed
+}
+
+func (c *serverConn) closeIfIdle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.active && !c.closed {
+		c.closed = true
+		c.conn.Close()
+	}
+}
+
+// handle produces the encoded ACK for a received message. readErr is
+// ErrMessageTooLarge if the message was cut short.
+func (s *Server) handle(ctx context.Context, addr net.Addr, raw []byte, readErr error) ([]byte, error) {
+	msh, err := requestHeader(raw)
+
+	var ack ACK
+	switch {
+	case readErr != nil:
+		ack = s.autoACK(msh, ApplicationReject, fmt.Sprintf("message exceeds %d bytes", s.maxMessageSize()))
+	case err != nil:
+		ack = s.autoACK(msh, ApplicationReject, fmt.Sprintf("invalid message: %v", err))
+	case msh.MessageControlId == "":
+		ack = s.autoACK(msh, ApplicationReject, "invalid message: MSH-10 is missing")
+	default:
+		reply, err := s.serveHL7(ctx, &Request{RemoteAddr: addr, MSH: msh, Raw: raw})
+		switch {
+		case err != nil:
+			ack = s.autoACK(msh, ApplicationError, err.Error())
+		case reply != nil:
+			ack = *reply
+			out, err := Marshal(ack)
+			if err == nil {
+				return out, nil
+			}
+			s.logf("mllp: cannot encode ACK to message %s from %s: %v", msh.MessageControlId, addr, err)
+			ack = s.autoACK(msh, ApplicationError, "cannot encode acknowledgment")
+		default:
+			ack = s.autoACK(msh, ApplicationAccept, "")
+		}
+	}
+	return Marshal(ack)
+}
+
+// requestHeader decodes the MSH segment a message starts with, leaving the
+// rest of the message to the Handler.
+func requestHeader(raw []byte) (MSH, error) {
+	var msh MSH
+	seg, _, _ := bytes.Cut(raw, []byte{'\r'})
+	seg = bytes.TrimLeft(seg, "\n")
+	if len(seg) < 8 || string(seg[:3]) != "MSH" {
+		return msh, fmt.Errorf("expected first segment to be MSH")
+	}
+	err := msh.decodeHeader(seg[3:], false)
+	return msh, err
+}
+
+// serveHL7 calls the Handler, turning a panic into an error.
+func (s *Server) serveHL7(ctx context.Context, req *Request) (ack *ACK, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ack, err = nil, fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return s.Handler.ServeHL7(ctx, req)
+}
+
+// autoACK builds an ACK with the given original mode code, switched to its
+// commit counterpart when the sender asked for enhanced mode.
+func (*Server) autoACK(msh MSH, code ID, text string) ACK {
+	if msh.EnhancedMode() {
+		code = map[ID]ID{
+			ApplicationAccept: CommitAccept,
+			ApplicationError:  CommitError,
+			ApplicationReject: CommitReject,
+		}[code]
+	}
+	return newACK(msh, code, text)
+}
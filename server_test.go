@@ -0,0 +1,298 @@
+package faraday
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFrame(t *testing.T) {
+	stream := "\n\x0BMSH|one\r\x1C\x0D\x0BMSH|two\rPID|1\r\x1C\x0D\x0BMSH|three-is-too-long\r\x1C\x0D\x0BMSH|four\x1C"
+	r := bufio.NewReaderSize(strings.NewReader(stream), 16)
+
+	msg, err := readFrame(r, 16)
+	require.NoError(t, err)
+	require.Equal(t, "MSH|one\r", string(msg))
+
+	msg, err = readFrame(r, 16)
+	require.NoError(t, err)
+	require.Equal(t, "MSH|two\rPID|1\r", string(msg))
+
+	msg, err = readFrame(r, 16)
+	require.ErrorIs(t, err, ErrMessageTooLarge)
+	require.Equal(t, "MSH|three-is-too", string(msg))
+
+	_, err = readFrame(r, 16)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, []byte("MSH|one\r")))
+	require.Equal(t, "\x0BMSH|one\r\x1C\x0D", buf.String())
+}
+
+const serverTestMessage = "MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|MSG1|P|2.3\r" +
+	"PID|1||123^^^MRN||DOE^JANE\r"
+
+// startServer serves on a loopback listener until the test ends.
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+// exchange sends msg on conn and decodes the ACK sent back.
+func exchange(t *testing.T, conn net.Conn, r *bufio.Reader, msg string) ACK {
+	t.Helper()
+	require.NoError(t, writeFrame(conn, []byte(msg)))
+	raw, err := readFrame(r, maxSegmentSize)
+	require.NoError(t, err)
+	var ack ACK
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&ack))
+	return ack
+}
+
+func TestServer(t *testing.T) {
+	addr := startServer(t, &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		var msg ORU_R01
+		if err := req.Decode(&msg); err != nil {
+			return nil, err
+		}
+		switch msg.Results[0].Patient.PID.PatientName[0].GivenName {
+		case "ERROR":
+			return nil, errors.New("no such patient")
+		case "PANIC":
+			panic("oops")
+		case "CUSTOM":
+			ack, err := NewACK(req.MSH, ApplicationReject, "custom")
+			return &ack, err
+		}
+		return nil, nil
+	})})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	ack := exchange(t, conn, r, serverTestMessage)
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationAccept, MessageControlId: "MSG1"}, ack.MSA)
+	require.Equal(t, HD{NamespaceId: "EHR"}, ack.MSH.SendingApplication)
+	require.Equal(t, CM_MSG{Type: "ACK", Event: "R01"}, ack.MSH.MessageType)
+
+	ack = exchange(t, conn, r, strings.Replace(serverTestMessage, "JANE", "ERROR", 1))
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationError, MessageControlId: "MSG1", TextMessage: "no such patient"}, ack.MSA)
+
+	ack = exchange(t, conn, r, strings.Replace(serverTestMessage, "JANE", "PANIC", 1))
+	require.Equal(t, ApplicationError, ack.MSA.AcknowledgmentCode)
+	require.Equal(t, ST("handler panic: oops"), ack.MSA.TextMessage)
+
+	ack = exchange(t, conn, r, strings.Replace(serverTestMessage, "JANE", "CUSTOM", 1))
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationReject, MessageControlId: "MSG1", TextMessage: "custom"}, ack.MSA)
+
+	ack = exchange(t, conn, r, "PID|1||123\r")
+	require.Equal(t, ApplicationReject, ack.MSA.AcknowledgmentCode)
+	require.Equal(t, ST(""), ack.MSA.MessageControlId)
+
+	ack = exchange(t, conn, r, strings.Replace(serverTestMessage, "P|2.3", "P|2.3|||AL", 1))
+	require.Equal(t, CommitAccept, ack.MSA.AcknowledgmentCode)
+}
+
+// logLines is an io.Writer sending every line logged to it on the channel.
+type logLines chan string
+
+func (l logLines) Write(b []byte) (int, error) {
+	l <- string(b)
+	return len(b), nil
+}
+
+func TestServer_UnencodableACK(t *testing.T) {
+	logged := make(logLines, 1)
+	addr := startServer(t, &Server{
+		ErrorLog: log.New(logged, "", 0),
+		Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+			ack, err := NewACK(req.MSH, ApplicationAccept, "")
+			ack.MSH.EncodingCharacters = "^~"
+			return &ack, err
+		}),
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ack := exchange(t, conn, bufio.NewReader(conn), serverTestMessage)
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationError, MessageControlId: "MSG1", TextMessage: "cannot encode acknowledgment"}, ack.MSA)
+	require.Contains(t, <-logged, "cannot encode ACK to message MSG1")
+}
+
+func TestServer_ConnContext(t *testing.T) {
+	contexts := make(chan context.Context, 1)
+	addr := startServer(t, &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		contexts <- ctx
+		return nil, nil
+	})})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	exchange(t, conn, bufio.NewReader(conn), serverTestMessage)
+	ctx := <-contexts
+	require.NoError(t, ctx.Err())
+
+	conn.Close()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("handler context outlived its connection")
+	}
+}
+
+func TestServer_MaxMessageSize(t *testing.T) {
+	addr := startServer(t, &Server{
+		MaxMessageSize: 120,
+		Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+			return nil, nil
+		}),
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	ack := exchange(t, conn, r, serverTestMessage+"OBX|1|TX|NOTE||"+string(bytes.Repeat([]byte("x"), 200))+"\r")
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationReject, MessageControlId: "MSG1", TextMessage: "message exceeds 120 bytes"}, ack.MSA)
+
+	ack = exchange(t, conn, r, serverTestMessage)
+	require.Equal(t, ApplicationAccept, ack.MSA.AcknowledgmentCode)
+}
+
+func TestServer_LargeSegment(t *testing.T) {
+	const size = maxSegmentSize + 1<<20
+	addr := startServer(t, &Server{
+		MaxMessageSize: 2 * maxSegmentSize,
+		Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+			var msg ORU_R01
+			if err := req.Decode(&msg); err != nil {
+				return nil, err
+			}
+			if n := len(msg.Results[0].Order[0].Results[0].OBX.ObservationValue[0]); n != size {
+				return nil, fmt.Errorf("got %d bytes of OBX-5", n)
+			}
+			return nil, nil
+		}),
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	ack := exchange(t, conn, r, serverTestMessage+"OBR|1OBX|1|ED|DOC||"+strings.Repeat("x", size)+"")
+	require.Equal(t, MSA{AcknowledgmentCode: ApplicationAccept, MessageControlId: "MSG1"}, ack.MSA)
+}
+
+func TestRequestHeader(t *testing.T) {
+	msh, err := requestHeader([]byte("\nMSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|MSG1|P|2.3\rPID|1|\\X\\\r"))
+	require.NoError(t, err)
+	require.Equal(t, ST("MSG1"), msh.MessageControlId)
+
+	_, err = requestHeader([]byte("PID|1\rMSH|^~\\&\r"))
+	require.Error(t, err)
+}
+
+func TestServer_Concurrent(t *testing.T) {
+	addr := startServer(t, &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		return nil, nil
+	})})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := net.Dial("tcp", addr)
+			require.NoError(t, err)
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for range 10 {
+				ack := exchange(t, conn, r, serverTestMessage)
+				require.Equal(t, ApplicationAccept, ack.MSA.AcknowledgmentCode)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServer_IdleTimeout(t *testing.T) {
+	addr := startServer(t, &Server{
+		IdleTimeout: 50 * time.Millisecond,
+		Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+			return nil, nil
+		}),
+	})
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	received, release := make(chan struct{}), make(chan struct{})
+	s := &Server{Handler: HandlerFunc(func(ctx context.Context, req *Request) (*ACK, error) {
+		close(received)
+		<-release
+		return nil, nil
+	})}
+	addr := startServer(t, s)
+
+	busy, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer busy.Close()
+	idle, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer idle.Close()
+
+	require.NoError(t, writeFrame(busy, []byte(serverTestMessage)))
+	<-received
+
+	shutdown := make(chan error)
+	go func() { shutdown <- s.Shutdown(context.Background()) }()
+
+	// the idle connection is closed right away
+	idle.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = idle.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned before the ACK was sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	raw, err := readFrame(bufio.NewReader(busy), maxSegmentSize)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "MSA|AA|MSG1")
+	require.NoError(t, <-shutdown)
+
+	_, err = net.Dial("tcp", addr)
+	require.Error(t, err)
+}
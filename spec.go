@@ -21,6 +21,7 @@ type FieldSpec struct {
 	Repeats      bool
 	RepeatCount  uint8
 	ControlTable *ControlTable
+	TableId      string
 
 	validationErr error
 }
@@ -44,44 +45,71 @@ func (spec *FieldSpec) ParseTag(tag string) *FieldSpec {
 	for pair := range strings.SplitSeq(tag, ",") {
 		parts := strings.SplitN(pair, "=", 2)
 		if len(parts) == 2 {
+			parts[0], parts[1] = strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
 			updateSpec(spec, parts...)
 		}
 	}
 	return spec
 }
 
-func (spec *FieldSpec) parse(field, delimiters []byte) error {
-	// NOTE: for now, if the field repeats just use the first element
-	field = bytes.SplitN(field, delimiters[1:2], 2)[0]
-	switch spec.Val.Kind() {
+func (spec *FieldSpec) parse(field []byte, delims delimiters, keepEscapes bool) error {
+	if spec.Val.Kind() == reflect.Slice {
+		return spec.parseRepetitions(field, delims, keepEscapes)
+	}
+	// a non-repeating struct field only holds the first repetition
+	field = bytes.SplitN(field, []byte{delims.repeat}, 2)[0]
+	return parseValue(spec.Val, field, delims, keepEscapes, 0)
+}
+
+// parseRepetitions decodes every repetition of a field into a slice-typed
+// struct field. Repetitions beyond the RepeatCount set by the `rep` tag are
+// kept as well; they are reported by validation (see Validate).
+func (spec *FieldSpec) parseRepetitions(field []byte, delims delimiters, keepEscapes bool) error {
+	if len(field) == 0 {
+		return nil
+	}
+	reps := bytes.Split(field, []byte{delims.repeat})
+	slice := reflect.MakeSlice(spec.Typ, len(reps), len(reps))
+	for i, rep := range reps {
+		if err := parseValue(slice.Index(i), rep, delims, keepEscapes, 0); err != nil {
+			return err
+		}
+	}
+	spec.Val.Set(slice)
+	return nil
+}
+
+// parseValue sets a field (depth 0), component (depth 1) or subcomponent
+// (depth 2). Composites nested deeper than subcomponents cannot be represented
+// in ER7, so the value is placed in their first field. Escape sequences are
+// replaced once the value has been split, unless keepEscapes is set.
+func parseValue(val reflect.Value, raw []byte, delims delimiters, keepEscapes bool, depth int) error {
+	switch val.Kind() {
 	default:
-		return fmt.Errorf("unsupported field type: %s", spec.Val.Kind().String())
+		return fmt.Errorf("unsupported field type: %s", val.Kind().String())
 	case reflect.String:
-		spec.Val.SetString(string(field))
+		if !keepEscapes {
+			raw = delims.unescapeText(raw)
+		}
+		val.SetString(string(raw))
 	case reflect.Struct:
-		components := bytes.Split(field, delimiters[:1])
-		for i := range min(spec.Val.NumField(), len(components)) {
-			if len(components[i]) == 0 {
+		if depth >= 2 {
+			if val.NumField() == 0 {
+				return nil
+			}
+			return parseValue(val.Field(0), raw, delims, keepEscapes, depth)
+		}
+		sep := delims.component
+		if depth == 1 {
+			sep = delims.subcomponent
+		}
+		parts := bytes.Split(raw, []byte{sep})
+		for i := range min(val.NumField(), len(parts)) {
+			if len(parts[i]) == 0 {
 				continue
 			}
-			fVal := spec.Val.Field(i)
-			switch fVal.Kind() {
-			case reflect.String:
-				fVal.SetString(string(components[i]))
-			case reflect.Struct:
-				subcomponents := bytes.Split(components[i], delimiters[3:])
-				for j := range min(fVal.NumField(), len(subcomponents)) {
-					cVal := fVal.Field(j)
-					if cVal.Kind() == reflect.Struct {
-						for k := range min(cVal.NumField(), len(subcomponents)) {
-							// at this point, everything should be string
-							sVal := cVal.Field(k)
-							sVal.SetString(string(subcomponents[k]))
-						}
-					} else {
-						cVal.SetString(string(subcomponents[j]))
-					}
-				}
+			if err := parseValue(val.Field(i), parts[i], delims, keepEscapes, depth+1); err != nil {
+				return err
 			}
 		}
 	}
@@ -105,13 +133,14 @@ func (spec *FieldSpec) validate(data []byte, delimiters []byte) {
 			fVal := val.Field(i)
 			if fVal.Kind() == reflect.Struct {
 				j := 0
-				for subcomponent := range bytes.SplitSeq(component, delimiters[3:]) {
-					if j > fVal.NumField() {
+				for subcomponent := range bytes.SplitSeq(component, delimiters[3:4]) {
+					if j >= fVal.NumField() {
 						spec.validationErr = fmt.Errorf("expected max %d subcomponents for field number %d", fVal.NumField(), spec.Position)
 						break
 					}
-					sfVal := fVal.Field(j)
-					sfVal.SetString(string(subcomponent))
+					if sfVal := fVal.Field(j); sfVal.Kind() == reflect.String {
+						sfVal.SetString(string(subcomponent))
+					}
 					j++
 				}
 			} else {
@@ -157,6 +186,7 @@ func updateSpec(spec *FieldSpec, parts ...string) {
 			}
 		}
 	case "tbl":
+		spec.TableId = parts[1]
 		spec.ControlTable = TableMap[parts[1]]
 	}
 }
@@ -61,3 +61,29 @@ func TestFieldSpecValidate(t *testing.T) {
 		spec.Val.Interface().(HD),
 	)
 }
+
+func TestFieldSpecParse_Repetitions(t *testing.T) {
+	var ids []CX
+	spec := NewFieldSpec(3, reflect.ValueOf(&ids).Elem())
+	spec.ParseTag("opt=R,rep=Y")
+	require.NoError(t, spec.parse([]byte("123^^^MRN~456^^^ACCT&1.2.3"), newDelimiters('|', defaultDelims), false))
+	require.Equal(t,
+		[]CX{
+			{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN"}},
+			{IdNumber: "456", AssigningAuthority: HD{NamespaceId: "ACCT", UniversalId: "1.2.3"}},
+		},
+		ids,
+	)
+
+	var flags []ID
+	spec = NewFieldSpec(8, reflect.ValueOf(&flags).Elem())
+	spec.ParseTag("rep=Y2")
+	require.NoError(t, spec.parse([]byte("H~HH~A"), newDelimiters('|', defaultDelims), false))
+	require.Equal(t, []ID{"H", "HH", "A"}, flags)
+
+	var name XPN
+	spec = NewFieldSpec(5, reflect.ValueOf(&name).Elem())
+	spec.ParseTag("rep=Y")
+	require.NoError(t, spec.parse([]byte("DOE^JANE~SMITH^JANE"), newDelimiters('|', defaultDelims), false))
+	require.Equal(t, XPN{FamilyName: "DOE", GivenName: "JANE"}, name)
+}
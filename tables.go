@@ -1,16 +1,41 @@
 package faraday
 
+import "maps"
+
 // HL7- and ISO-defined tables.
 // Note that this does not include user-defined tables.
-// By nature, those should be configured.
+// By nature, those should be configured (see Tables).
 var TableMap = map[string]*ControlTable{
+	"0001":    &AdministrativeSex,
 	"0003":    &EventType,
+	"0004":    &PatientClasses,
+	"0008":    &AcknowledgmentCodes,
+	"0038":    &OrderStatuses,
 	"0076":    &MessageType,
 	"0061":    &CheckDigitScheme,
-	"0102":    &RelationalConjunctions,
+	"0078":    &AbnormalFlags,
+	"0085":    &ObservationResultStatuses,
+	"0102":    &DelayedAcknowledgmentTypes,
 	"0103":    &ProcessingIds,
 	"0104":    &VersionIds,
+	"0119":    &OrderControlCodes,
+	"0123":    &ResultStatuses,
+	"0125":    &ValueTypes,
+	"0127":    &AllergyTypes,
+	"0128":    &AllergySeverities,
+	"0136":    &YesNoIndicators,
 	"0155":    &AcknowledgementConditions,
+	"0161":    &AllowSubstitutions,
+	"0166":    &RxComponentTypes,
+	"0167":    &SubstitutionStatuses,
+	"0170":    &DerivedSpecimens,
+	"0175":    &MasterFileIdentifierCodes,
+	"0178":    &FileLevelEventCodes,
+	"0179":    &ResponseLevelCodes,
+	"0180":    &RecordLevelEventCodes,
+	"0181":    &RecordLevelErrorCodes,
+	"0183":    &ActiveInactive,
+	"0185":    &PreferredMethodsOfContact,
 	"0190":    &AddressTypes,
 	"0191":    &ReferencedDataTypes,
 	"0200":    &NameTypeCodes,
@@ -18,13 +43,24 @@ var TableMap = map[string]*ControlTable{
 	"0202":    &TelecommunicationEquipmentTypes,
 	"0203":    &IdentifierTypeCodes,
 	"0205":    &PriceTypes,
+	"0206":    &SegmentActionCodes,
 	"0207":    &ProcessingModes,
 	"0209":    &RelationalOperators,
+	"0210":    &RelationalConjunctions,
 	"0211":    &AlternateCharacterSets,
 	"0267":    &DaysOfWeek,
+	"0271":    &DocumentCompletionStatuses,
+	"0272":    &DocumentConfidentialityStatuses,
+	"0273":    &DocumentAvailabilityStatuses,
+	"0275":    &DocumentStorageStatuses,
+	"0278":    &FillerStatusCodes,
+	"0287":    &ActionCodes,
 	"0291":    &ReferencedDataSubTypes,
 	"0298":    &RangeTypes,
 	"0301":    &UniversalIdTypes,
+	"0321":    &DispenseMethods,
+	"0322":    &CompletionStatuses,
+	"0323":    &RxActionCodes,
 	"4000":    &NameRepresentationCodes,
 	"ISO3166": &CountryCodes,
 	"ISO4217": &IsoDenominations,
@@ -37,278 +73,495 @@ func (table ControlTable) Valid(v ID) bool {
 	return ok
 }
 
+// Lookup returns the description of a code, reporting whether the table
+// holds it.
+func (table ControlTable) Lookup(code ID) (string, bool) {
+	desc, ok := table[code]
+	return desc, ok
+}
+
+// Describe returns the description of a code, or "" if the table does not
+// hold it.
+func (table ControlTable) Describe(code ID) string {
+	return table[code]
+}
+
+// with returns a copy of the table with codes added.
+func (table ControlTable) with(codes ControlTable) ControlTable {
+	out := make(ControlTable, len(table)+len(codes))
+	maps.Copy(out, table)
+	maps.Copy(out, codes)
+	return out
+}
+
+// HL7 Table 0001
+var AdministrativeSex = ControlTable{
+	"F": "Female",
+	"M": "Male",
+	"O": "Other",
+	"U": "Unknown",
+}
+
 // HL7 Table 0003
 var EventType = ControlTable{
-	"022": "",
-	"A01": "",
-	"A02": "",
-	"A03": "",
-	"A04": "",
-	"A05": "",
-	"A06": "",
-	"A07": "",
-	"A08": "",
-	"A09": "",
-	"A10": "",
-	"A11": "",
-	"A12": "",
-	"A13": "",
-	"A14": "",
-	"A15": "",
-	"A16": "",
-	"A17": "",
-	"A18": "",
-	"A19": "",
-	"A20": "",
-	"A21": "",
-	"A22": "",
-	"A23": "",
-	"A24": "",
-	"A25": "",
-	"A26": "",
-	"A27": "",
-	"A28": "",
-	"A29": "",
-	"A30": "",
-	"A31": "",
-	"A32": "",
-	"A33": "",
-	"A34": "",
-	"A35": "",
-	"A36": "",
-	"A37": "",
-	"A38": "",
-	"A39": "",
-	"A40": "",
-	"A41": "",
-	"A42": "",
-	"A43": "",
-	"A44": "",
-	"A45": "",
-	"A46": "",
-	"A47": "",
-	"A48": "",
-	"A49": "",
-	"A50": "",
-	"A51": "",
-	"C01": "",
-	"C02": "",
-	"C03": "",
-	"C04": "",
-	"C05": "",
-	"C06": "",
-	"C07": "",
-	"C08": "",
-	"C09": "",
-	"C10": "",
-	"C11": "",
-	"C12": "",
-	"CNQ": "",
-	"I01": "",
-	"I02": "",
-	"I03": "",
-	"I04": "",
-	"I05": "",
-	"I06": "",
-	"I07": "",
-	"I08": "",
-	"I09": "",
-	"I10": "",
-	"I11": "",
-	"I12": "",
-	"I13": "",
-	"I14": "",
-	"I15": "",
-	"M01": "",
-	"M02": "",
-	"M03": "",
-	"M04": "",
-	"M05": "",
-	"M06": "",
-	"M07": "",
-	"M08": "",
-	"M09": "",
-	"M10": "",
-	"M11": "",
-	"O01": "",
-	"O02": "",
-	"P01": "",
-	"P02": "",
-	"P03": "",
-	"P04": "",
-	"P05": "",
-	"P06": "",
-	"Q01": "",
-	"Q02": "",
-	"Q03": "",
-	"Q04": "",
-	"Q05": "",
-	"Q06": "",
-	"R01": "",
-	"R02": "",
-	"R03": "",
-	"R04": "",
-	"R05": "",
-	"R06": "",
-	"RAR": "",
-	"RER": "",
-	"ROR": "",
-	"S01": "",
-	"S02": "",
-	"S03": "",
-	"S04": "",
-	"S05": "",
-	"S06": "",
-	"S07": "",
-	"S08": "",
-	"S09": "",
-	"S10": "",
-	"S11": "",
-	"S12": "",
-	"S13": "",
-	"S14": "",
-	"S15": "",
-	"S16": "",
-	"S17": "",
-	"S18": "",
-	"S19": "",
-	"S20": "",
-	"S21": "",
-	"S22": "",
-	"S23": "",
-	"S24": "",
-	"S25": "",
-	"S26": "",
-	"T01": "",
-	"T02": "",
-	"T03": "",
-	"T04": "",
-	"T05": "",
-	"T06": "",
-	"T07": "",
-	"T09": "",
-	"V01": "",
-	"V02": "",
-	"V03": "",
-	"V04": "",
-	"W01": "",
-	"W02": "",
+	"A01": "ADT/ACK - Admit/visit notification",
+	"A02": "ADT/ACK - Transfer a patient",
+	"A03": "ADT/ACK - Discharge/end visit",
+	"A04": "ADT/ACK - Register a patient",
+	"A05": "ADT/ACK - Pre-admit a patient",
+	"A06": "ADT/ACK - Change an outpatient to an inpatient",
+	"A07": "ADT/ACK - Change an inpatient to an outpatient",
+	"A08": "ADT/ACK - Update patient information",
+	"A09": "ADT/ACK - Patient departing - tracking",
+	"A10": "ADT/ACK - Patient arriving - tracking",
+	"A11": "ADT/ACK - Cancel admit/visit notification",
+	"A12": "ADT/ACK - Cancel transfer",
+	"A13": "ADT/ACK - Cancel discharge/end visit",
+	"A14": "ADT/ACK - Pending admit",
+	"A15": "ADT/ACK - Pending transfer",
+	"A16": "ADT/ACK - Pending discharge",
+	"A17": "ADT/ACK - Swap patients",
+	"A18": "ADT/ACK - Merge patient information",
+	"A19": "QRY/ADR - Patient query",
+	"A20": "ADT/ACK - Bed status update",
+	"A21": "ADT/ACK - Patient goes on a \"leave of absence\"",
+	"A22": "ADT/ACK - Patient returns from a \"leave of absence\"",
+	"A23": "ADT/ACK - Delete a patient record",
+	"A24": "ADT/ACK - Link patient information",
+	"A25": "ADT/ACK - Cancel pending discharge",
+	"A26": "ADT/ACK - Cancel pending transfer",
+	"A27": "ADT/ACK - Cancel pending admit",
+	"A28": "ADT/ACK - Add person information",
+	"A29": "ADT/ACK - Delete person information",
+	"A30": "ADT/ACK - Merge person information",
+	"A31": "ADT/ACK - Update person information",
+	"A32": "ADT/ACK - Cancel patient arriving - tracking",
+	"A33": "ADT/ACK - Cancel patient departing - tracking",
+	"A34": "ADT/ACK - Merge patient information - patient ID only",
+	"A35": "ADT/ACK - Merge patient information - account number only",
+	"A36": "ADT/ACK - Merge patient information - patient ID and account number",
+	"A37": "ADT/ACK - Unlink patient information",
+	"A38": "ADT/ACK - Cancel pre-admit",
+	"A39": "ADT/ACK - Merge person - external ID",
+	"A40": "ADT/ACK - Merge patient - internal ID",
+	"A41": "ADT/ACK - Merge account - patient account number",
+	"A42": "ADT/ACK - Merge visit - visit number",
+	"A43": "ADT/ACK - Move patient information - internal ID",
+	"A44": "ADT/ACK - Move account information - patient account number",
+	"A45": "ADT/ACK - Move visit information - visit number",
+	"A46": "ADT/ACK - Change external ID",
+	"A47": "ADT/ACK - Change internal ID",
+	"A48": "ADT/ACK - Change alternate patient ID",
+	"A49": "ADT/ACK - Change patient account number",
+	"A50": "ADT/ACK - Change visit number",
+	"A51": "ADT/ACK - Change alternate visit ID",
+	"C01": "CRM - Register a patient on a clinical trial",
+	"C02": "CRM - Cancel a patient registration on clinical trial (for clerical mistakes only)",
+	"C03": "CRM - Correct/update registration information",
+	"C04": "CRM - Patient has gone off a clinical trial",
+	"C05": "CRM - Patient enters phase of clinical trial",
+	"C06": "CRM - Cancel patient entering a phase (clerical mistake)",
+	"C07": "CRM - Correct/update phase information",
+	"C08": "CRM - Patient has gone off phase of clinical trial",
+	"C09": "CSU - Automated time intervals for reporting, like monthly",
+	"C10": "CSU - Patient completes the clinical trial",
+	"C11": "CSU - Patient completes a phase of the clinical trial",
+	"C12": "CSU - Update/correction of patient order/result information",
+	"CNQ": "QRY/EQQ/VQQ/RQQ - Cancel query",
+	"I01": "RQI/RPI - Request for insurance information",
+	"I02": "RQI/RPL - Request/receipt of patient selection display list",
+	"I03": "RQI/RPR - Request/receipt of patient selection list",
+	"I04": "RQD/RPI - Request for patient demographic data",
+	"I05": "RQC/RCI - Request for patient clinical information",
+	"I06": "RQC/RCL - Request/receipt of clinical data listing",
+	"I07": "PIN/ACK - Unsolicited insurance information",
+	"I08": "RQA/RPA - Request for treatment authorization information",
+	"I09": "RQA/RPA - Request for modification to an authorization",
+	"I10": "RQA/RPA - Request for resubmission of an authorization",
+	"I11": "RQA/RPA - Request for cancellation of an authorization",
+	"I12": "REF/RRI - Patient referral",
+	"I13": "REF/RRI - Modify patient referral",
+	"I14": "REF/RRI - Cancel patient referral",
+	"I15": "REF/RRI - Request patient referral status",
+	"M01": "MFN/MFK - Master file not otherwise specified (for backward compatibility only)",
+	"M02": "MFN/MFK - Master file - staff practitioner",
+	"M03": "MFN/MFK - Master file - test/observation",
+	"M04": "MFD/ACK - Master files delayed application acknowledgment",
+	"M05": "MFN/MFK - Patient location master file",
+	"M06": "MFN/MFK - Charge description master file",
+	"M07": "MFN/MFK - Clinical study with phases and schedules master file",
+	"M08": "MFN/MFK - Test/observation (numeric) master file",
+	"M09": "MFN/MFK - Test/observation (categorical) master file",
+	"M10": "MFN/MFK - Test/observation batteries master file",
+	"M11": "MFN/MFK - Test/calculated observations master file",
+	"O01": "ORM - Order message (also RDE, RDS, RGV, RAS)",
+	"O02": "ORR - Order response (also RRE, RRD, RRG, RRA)",
+	"P01": "BAR/ACK - Add and update patient account",
+	"P02": "BAR/ACK - Purge patient account",
+	"P03": "DFT/ACK - Post detail financial transaction",
+	"P04": "QRY/DSP - Generate bill and A/R statements",
+	"P05": "BAR/ACK - Update account",
+	"P06": "BAR/ACK - End account",
+	"Q01": "QRY/DSR - Query sent for immediate response",
+	"Q02": "QRY/QCK - Query sent for deferred response",
+	"Q03": "DSR/ACK - Deferred response to a query",
+	"Q04": "EQQ - Embedded query language query",
+	"Q05": "UDM/ACK - Unsolicited display update message",
+	"Q06": "OSQ/OSR - Query for order status",
+	"R01": "ORU/ACK - Unsolicited transmission of an observation message",
+	"R02": "QRY - Query for results of observation",
+	"R03": "QRY/DSR - Display-oriented results, query/unsolicited update (for backward compatibility only)",
+	"R04": "ORF - Response to query; transmission of requested observation",
+	"R05": "QRY/DSR - Query for display results",
+	"R06": "UDM - Unsolicited update/display results",
+	"RAR": "RAR - Pharmacy administration information query response",
+	"RER": "RER - Pharmacy encoded order information query response",
+	"ROR": "ROR - Pharmacy prescription order query response",
+	"S01": "SRM/SRR - Request new appointment booking",
+	"S02": "SRM/SRR - Request appointment rescheduling",
+	"S03": "SRM/SRR - Request appointment modification",
+	"S04": "SRM/SRR - Request appointment cancellation",
+	"S05": "SRM/SRR - Request appointment discontinuation",
+	"S06": "SRM/SRR - Request appointment deletion",
+	"S07": "SRM/SRR - Request addition of service/resource on appointment",
+	"S08": "SRM/SRR - Request modification of service/resource on appointment",
+	"S09": "SRM/SRR - Request cancellation of service/resource on appointment",
+	"S10": "SRM/SRR - Request discontinuation of service/resource on appointment",
+	"S11": "SRM/SRR - Request deletion of service/resource on appointment",
+	"S12": "SIU/ACK - Notification of new appointment booking",
+	"S13": "SIU/ACK - Notification of appointment rescheduling",
+	"S14": "SIU/ACK - Notification of appointment modification",
+	"S15": "SIU/ACK - Notification of appointment cancellation",
+	"S16": "SIU/ACK - Notification of appointment discontinuation",
+	"S17": "SIU/ACK - Notification of appointment deletion",
+	"S18": "SIU/ACK - Notification of addition of service/resource on appointment",
+	"S19": "SIU/ACK - Notification of modification of service/resource on appointment",
+	"S20": "SIU/ACK - Notification of cancellation of service/resource on appointment",
+	"S21": "SIU/ACK - Notification of discontinuation of service/resource on appointment",
+	"S22": "SIU/ACK - Notification of deletion of service/resource on appointment",
+	"S23": "SIU/ACK - Notification of blocked schedule time slot(s)",
+	"S24": "SIU/ACK - Notification of opened (\"unblocked\") schedule time slot(s)",
+	"S25": "SQM/SQR - Query schedule information",
+	"S26": "SIU/ACK - Notification that patient did not show up for scheduled appointment",
+	"T01": "MDM/ACK - Original document notification",
+	"T02": "MDM/ACK - Original document notification and content",
+	"T03": "MDM/ACK - Document status change notification",
+	"T04": "MDM/ACK - Document status change notification and content",
+	"T05": "MDM/ACK - Document addendum notification",
+	"T06": "MDM/ACK - Document addendum notification and content",
+	"T07": "MDM/ACK - Document edit notification",
+	"T08": "MDM/ACK - Document edit notification and content",
+	"T09": "MDM/ACK - Document replacement notification",
+	"T10": "MDM/ACK - Document replacement notification and content",
+	"T11": "MDM/ACK - Document cancel notification",
+	"V01": "VXQ - Query for vaccination record",
+	"V02": "VXX - Response to vaccination query returning multiple PID matches",
+	"V03": "VXR - Vaccination record response",
+	"V04": "VXU - Unsolicited vaccination record update",
+	"W01": "ORU - Waveform result, unsolicited transmission of requested information",
+	"W02": "QRF - Waveform result, response to query",
+}
+
+// HL7 Table 0004
+var PatientClasses = ControlTable{
+	"B": "Obstetrics",
+	"E": "Emergency",
+	"I": "Inpatient",
+	"O": "Outpatient",
+	"P": "Preadmit",
+	"R": "Recurring patient",
+}
+
+// HL7 Table 0038
+var OrderStatuses = ControlTable{
+	"A":  "Some, but not all, results available",
+	"CA": "Order was canceled",
+	"CM": "Order is completed",
+	"DC": "Order was discontinued",
+	"ER": "Error, order not found",
+	"HD": "Order is on hold",
+	"IP": "In process, unspecified",
+	"RP": "Order has been replaced",
+	"SC": "In process, scheduled",
 }
 
 // HL7 Table 0061
 var CheckDigitScheme = ControlTable{
-	"M10": "",
-	"M11": "",
+	"M10": "Mod 10 algorithm",
+	"M11": "Mod 11 algorithm",
 }
 
 // HL7 Table 0076
 var MessageType = ControlTable{
-	"ACK": "",
-	"ADR": "",
-	"ADT": "",
-	"ARD": "",
-	"BAR": "",
-	"CNQ": "",
-	"CSU": "",
-	"DFT": "",
-	"DSR": "",
-	"EDR": "",
-	"ERP": "",
-	"EQQ": "",
-	"MCF": "",
-	"MDM": "",
-	"MFD": "",
-	"MFK": "",
-	"MFN": "",
-	"MFQ": "",
-	"MFR": "",
-	"ORF": "",
-	"ORM": "",
-	"ORR": "",
-	"ORU": "",
-	"OSQ": "",
-	"OSR": "",
-	"PEX": "",
-	"PGL": "",
-	"PGQ": "",
-	"PPG": "",
-	"PPP": "",
-	"PPR": "",
-	"PPT": "",
-	"PPV": "",
-	"PRR": "",
-	"PTR": "",
-	"PTV": "",
-	"QRY": "",
-	"RAR": "",
-	"RAS": "",
-	"RCI": "",
-	"RCL": "",
-	"RDE": "",
-	"RDR": "",
-	"RDS": "",
-	"REF": "",
-	"RER": "",
-	"RGR": "",
-	"RGV": "",
-	"ROC": "",
-	"ROD": "",
-	"ROR": "",
-	"RPA": "",
-	"RPI": "",
-	"RPL": "",
-	"RPR": "",
-	"RQA": "",
-	"RQC": "",
-	"RQI": "",
-	"RRA": "",
-	"RRD": "",
-	"RRE": "",
-	"RRG": "",
-	"RRI": "",
-	"SIU": "",
-	"SPQ": "",
-	"SQM": "",
-	"SQR": "",
-	"SRM": "",
-	"SRR": "",
-	"SUR": "",
-	"TBR": "",
-	"UDM": "",
-	"VQQ": "",
-	"VXQ": "",
-	"VXR": "",
-	"VXU": "",
-	"VXX": "",
+	"ACK": "General acknowledgment message",
+	"ADR": "ADT response",
+	"ADT": "ADT message",
+	"ARD": "Ancillary RPT (display)",
+	"BAR": "Add/change billing account",
+	"CNQ": "Cancel query",
+	"CSU": "Unsolicited clinical study data",
+	"DFT": "Detail financial transaction",
+	"DSR": "Display response",
+	"EDR": "Enhanced display response",
+	"EQQ": "Embedded query language query",
+	"ERP": "Event replay response",
+	"MCF": "Delayed acknowledgment",
+	"MDM": "Documentation message",
+	"MFD": "Master files delayed application acknowledgment",
+	"MFK": "Master files application acknowledgment",
+	"MFN": "Master files notification",
+	"MFQ": "Master files query",
+	"MFR": "Master files query response",
+	"ORF": "Observation result/record response",
+	"ORM": "Order message",
+	"ORR": "Order acknowledgment message",
+	"ORU": "Observation result/unsolicited",
+	"OSQ": "Order status query",
+	"OSR": "Order status response",
+	"PEX": "Product experience",
+	"PGL": "Patient goal",
+	"PGQ": "Patient goal query",
+	"PPG": "Patient pathway (goal-oriented)",
+	"PPP": "Patient pathway (problem-oriented)",
+	"PPR": "Patient problem",
+	"PPT": "Patient pathway (goal-oriented) response",
+	"PPV": "Patient goal response",
+	"PRR": "Patient problem response",
+	"PTR": "Patient pathway (problem-oriented) response",
+	"PTV": "Patient pathway (goal-oriented) query response",
+	"QRY": "Query, original mode",
+	"RAR": "Pharmacy administration information",
+	"RAS": "Pharmacy administration message",
+	"RCI": "Return clinical information",
+	"RCL": "Return clinical list",
+	"RDE": "Pharmacy encoded order message",
+	"RDR": "Pharmacy dispense information",
+	"RDS": "Pharmacy dispense message",
+	"REF": "Patient referral",
+	"RER": "Pharmacy encoded order information",
+	"RGR": "Pharmacy dose information",
+	"RGV": "Pharmacy give message",
+	"ROC": "Request clinical information",
+	"ROD": "Request patient demographics",
+	"ROR": "Pharmacy prescription order response",
+	"RPA": "Return patient authorization",
+	"RPI": "Return patient information",
+	"RPL": "Return patient display list",
+	"RPR": "Return patient list",
+	"RQA": "Request patient authorization",
+	"RQC": "Request clinical information",
+	"RQI": "Request patient information",
+	"RRA": "Pharmacy administration acknowledgment",
+	"RRD": "Pharmacy dispense acknowledgment",
+	"RRE": "Pharmacy encoded order acknowledgment",
+	"RRG": "Pharmacy give acknowledgment",
+	"RRI": "Return referral information",
+	"SIU": "Schedule information unsolicited",
+	"SPQ": "Stored procedure request",
+	"SQM": "Schedule query",
+	"SQR": "Schedule query response",
+	"SRM": "Schedule request",
+	"SRR": "Scheduled request response",
+	"SUR": "Summary product experience report",
+	"TBR": "Tabular response",
+	"UDM": "Unsolicited display message",
+	"VQQ": "Virtual table query",
+	"VXQ": "Query for vaccination record",
+	"VXR": "Vaccination query record response",
+	"VXU": "Unsolicited vaccination record update",
+	"VXX": "Vaccination query response with multiple PID matches",
+}
+
+// HL7 Table 0008
+var AcknowledgmentCodes = ControlTable{
+	"AA": "Original mode: Application Accept - Enhanced mode: Application acknowledgment: Accept",
+	"AE": "Original mode: Application Error - Enhanced mode: Application acknowledgment: Error",
+	"AR": "Original mode: Application Reject - Enhanced mode: Application acknowledgment: Reject",
+	"CA": "Enhanced mode: Accept acknowledgment: Commit Accept",
+	"CE": "Enhanced mode: Accept acknowledgment: Commit Error",
+	"CR": "Enhanced mode: Accept acknowledgment: Commit Reject",
+}
+
+// HL7 Table 0078
+var AbnormalFlags = ControlTable{
+	"L":  "Below low normal",
+	"H":  "Above high normal",
+	"LL": "Below lower panic limits",
+	"HH": "Above upper panic limits",
+	"<":  "Below absolute low-off instrument scale",
+	">":  "Above absolute high-off instrument scale",
+	"N":  "Normal (applies to non-numeric results)",
+	"A":  "Abnormal (applies to non-numeric results)",
+	"AA": "Very abnormal (applies to non-numeric units, analogous to panic limits for numeric units)",
+	"U":  "Significant change up",
+	"D":  "Significant change down",
+	"B":  "Better (use when direction not relevant)",
+	"W":  "Worse (use when direction not relevant)",
+	"S":  "Susceptible",
+	"R":  "Resistant",
+	"I":  "Intermediate",
+	"MS": "Moderately susceptible",
+	"VS": "Very susceptible",
+}
+
+// HL7 Table 0085
+var ObservationResultStatuses = ControlTable{
+	"C": "Record coming over is a correction and thus replaces a final result",
+	"D": "Deletes the OBX record",
+	"F": "Final results; can only be changed with a corrected result",
+	"I": "Specimen in lab; results pending",
+	"P": "Preliminary results",
+	"R": "Results entered - not verified",
+	"S": "Partial results",
+	"X": "Results cannot be obtained for this observation",
+	"U": "Results status change to final without retransmitting results already sent as preliminary",
+	"W": "Post original as wrong, e.g. transmitted for wrong patient",
 }
 
 // HL7 Table 0102
-var RelationalConjunctions = ControlTable{
-	"AND": "",
-	"OR":  "",
+var DelayedAcknowledgmentTypes = ControlTable{
+	"D": "Message received, stored for later processing",
+	"F": "Acknowledgment after processing",
 }
 
 // HL7 Table 0103
 var ProcessingIds = ControlTable{
-	"D": "",
-	"P": "",
-	"T": "",
+	"D": "Debugging",
+	"P": "Production",
+	"T": "Training",
 }
 
 // HL7 Table 0104
 var VersionIds = ControlTable{
-	"2.0":   "",
-	"2.0D":  "",
-	"2.1":   "",
-	"2.2":   "",
-	"2.3":   "",
-	"2.3.1": "",
-	"2.4":   "",
-	"2.5":   "",
-	"2.6":   "",
-	"2.7":   "",
-	"2.7.1": "",
-	"2.8":   "",
+	"2.0":   "Release 2.0, September 1988",
+	"2.0D":  "Demo 2.0, October 1988",
+	"2.1":   "Release 2.1, March 1990",
+	"2.2":   "Release 2.2, December 1994",
+	"2.3":   "Release 2.3, March 1997",
+	"2.3.1": "Release 2.3.1, May 1999",
+	"2.4":   "Release 2.4, November 2000",
+	"2.5":   "Release 2.5, July 2003",
+	"2.5.1": "Release 2.5.1, April 2007",
+	"2.6":   "Release 2.6, October 2007",
+	"2.7":   "Release 2.7, January 2011",
+	"2.7.1": "Release 2.7.1, August 2012",
+	"2.8":   "Release 2.8, February 2014",
+}
+
+// HL7 Table 0119
+var OrderControlCodes = ControlTable{
+	"AF": "Order refill request approval",
+	"CA": "Cancel order request",
+	"CH": "Child order",
+	"CN": "Combined result",
+	"CR": "Canceled as requested",
+	"DC": "Discontinue order request",
+	"DE": "Data errors",
+	"DF": "Order refill request denied",
+	"DR": "Discontinued as requested",
+	"FU": "Order refilled, unsolicited",
+	"HD": "Hold order request",
+	"HR": "On hold as requested",
+	"LI": "Link order to patient care problem or goal",
+	"NA": "Number assigned",
+	"NW": "New order",
+	"OC": "Order canceled",
+	"OD": "Order discontinued",
+	"OE": "Order released",
+	"OF": "Order refilled as requested",
+	"OH": "Order held",
+	"OK": "Order accepted & OK",
+	"OR": "Released as requested",
+	"PA": "Parent order",
+	"RE": "Observations to follow",
+	"RF": "Refill order request",
+	"RL": "Release previous hold",
+	"RO": "Replacement order",
+	"RP": "Order replace request",
+	"RQ": "Replaced as requested",
+	"RR": "Request received",
+	"RU": "Replaced unsolicited",
+	"SC": "Status changed",
+	"SN": "Send order number",
+	"SR": "Response to send order status request",
+	"SS": "Send order status request",
+	"UA": "Unable to accept order",
+	"UC": "Unable to cancel",
+	"UD": "Unable to discontinue",
+	"UF": "Unable to refill",
+	"UH": "Unable to put on hold",
+	"UM": "Unable to replace",
+	"UN": "Unlink order from patient care problem or goal",
+	"UR": "Unable to release",
+	"UX": "Unable to change",
+	"XO": "Change order request",
+	"XR": "Changed as requested",
+	"XX": "Order changed, unsolicited",
+}
+
+// HL7 Table 0123
+var ResultStatuses = ControlTable{
+	"A": "Some, but not all, results available",
+	"C": "Correction to results",
+	"F": "Final results; results stored and verified",
+	"I": "No results available; specimen received, procedure incomplete",
+	"O": "Order received; specimen not yet received",
+	"P": "Preliminary: a verified early result is available, final results not yet obtained",
+	"R": "Results stored; not yet verified",
+	"S": "No results available; procedure scheduled, but not done",
+	"X": "No results available; order canceled",
+	"Y": "No order on record for this test (used only on queries)",
+	"Z": "No record of this patient (used only on queries)",
+}
+
+// HL7 Table 0125
+var ValueTypes = ControlTable{
+	"AD":  "Address",
+	"CE":  "Coded entry",
+	"CF":  "Coded element with formatted values",
+	"CK":  "Composite ID with check digit",
+	"CN":  "Composite ID and name",
+	"CP":  "Composite price",
+	"CX":  "Extended composite ID with check digit",
+	"DT":  "Date",
+	"ED":  "Encapsulated data",
+	"FT":  "Formatted text (display)",
+	"MO":  "Money",
+	"NM":  "Numeric",
+	"PN":  "Person name",
+	"RP":  "Reference pointer",
+	"SN":  "Structured numeric",
+	"ST":  "String data",
+	"TM":  "Time",
+	"TN":  "Telephone number",
+	"TS":  "Time stamp (date & time)",
+	"TX":  "Text data (display)",
+	"XAD": "Extended address",
+	"XCN": "Extended composite name and number for persons",
+	"XON": "Extended composite name and number for organizations",
+	"XPN": "Extended person name",
+	"XTN": "Extended telecommunications number",
+}
+
+// HL7 Table 0127
+var AllergyTypes = ControlTable{
+	"DA": "Drug allergy",
+	"FA": "Food allergy",
+	"MA": "Miscellaneous allergy",
+	"MC": "Miscellaneous contraindication",
+}
+
+// HL7 Table 0128
+var AllergySeverities = ControlTable{
+	"MI": "Mild",
+	"MO": "Moderate",
+	"SV": "Severe",
+}
+
+// HL7 Table 0136
+var YesNoIndicators = ControlTable{
+	"Y": "Yes",
+	"N": "No",
 }
 
 // HL7 Table 0155
@@ -319,16 +572,110 @@ var AcknowledgementConditions = ControlTable{
 	"SU": "Successful completion only",
 }
 
+// HL7 Table 0161
+var AllowSubstitutions = ControlTable{
+	"N": "Substitutions are NOT authorized",
+	"G": "Allow generic substitutions",
+	"T": "Allow therapeutic substitutions",
+}
+
+// HL7 Table 0166
+var RxComponentTypes = ControlTable{
+	"A": "Additive",
+	"B": "Base",
+}
+
+// HL7 Table 0167
+var SubstitutionStatuses = ControlTable{
+	"N": "No substitute was dispensed",
+	"G": "A generic substitution was dispensed",
+	"T": "A therapeutic substitution was dispensed",
+	"0": "No product selection indicated",
+	"1": "Substitution not allowed by prescriber",
+	"2": "Substitution allowed - patient requested product dispensed",
+	"3": "Substitution allowed - pharmacist selected product dispensed",
+	"4": "Substitution allowed - generic drug not in stock",
+	"5": "Substitution allowed - brand drug dispensed as a generic",
+	"7": "Substitution not allowed - brand drug mandated by law",
+	"8": "Substitution allowed - generic drug not available in marketplace",
+}
+
+// HL7 Table 0170
+var DerivedSpecimens = ControlTable{
+	"C": "Child observation",
+	"N": "Not applicable",
+	"P": "Parent observation",
+}
+
+// HL7 Table 0175
+var MasterFileIdentifierCodes = ControlTable{
+	"CDM": "Charge description master file",
+	"CMA": "Clinical study with phases and scheduled master file",
+	"CMB": "Clinical study without phases but with scheduled master file",
+	"LOC": "Location master file",
+	"OMA": "Numerical observation master file",
+	"OMB": "Categorical observation master file",
+	"OMC": "Observation batteries master file",
+	"OMD": "Calculated observations master file",
+	"PRA": "Practitioner master file",
+	"STF": "Staff master file",
+}
+
+// HL7 Table 0178
+var FileLevelEventCodes = ControlTable{
+	"REP": "Replace current version of this master file with the version contained in this message",
+	"UPD": "Change file records as defined in the record-level event codes for each record that follows",
+}
+
+// HL7 Table 0179
+var ResponseLevelCodes = ControlTable{
+	"NE": "Never. No application-level response needed",
+	"ER": "Error/reject conditions only. Only MFA segments denoting errors must be returned via the application-level acknowledgment for this message",
+	"AL": "Always. All MFA segments (whether denoting errors or not) must be returned via the application-level acknowledgment message",
+	"SU": "Success. Only MFA segments denoting success must be returned via the application-level acknowledgment for this message",
+}
+
+// HL7 Table 0180
+var RecordLevelEventCodes = ControlTable{
+	"MAD": "Add record to master file",
+	"MDL": "Delete record from master file",
+	"MUP": "Update record for master file",
+	"MDC": "Deactivate: discontinue using record in master file, but do not delete from database",
+	"MAC": "Reactivate deactivated record",
+}
+
+// HL7 Table 0181
+var RecordLevelErrorCodes = ControlTable{
+	"S": "Successful posting of the record defined by the MFE segment",
+	"U": "Unsuccessful posting of the record defined by the MFE segment",
+}
+
+// HL7 Table 0183
+var ActiveInactive = ControlTable{
+	"A": "Active staff",
+	"I": "Inactive staff",
+}
+
+// HL7 Table 0185
+var PreferredMethodsOfContact = ControlTable{
+	"B": "Beeper number",
+	"C": "Cellular phone number",
+	"E": "E-mail address (for backward compatibility)",
+	"F": "FAX number",
+	"H": "Home phone number",
+	"O": "Office phone number",
+}
+
 // HL7 Table 0190
 var AddressTypes = ControlTable{
-	"B": "", // Firm/Business
-	"C": "", // Current or Temporary
-	"F": "", // Country of Origin
-	"H": "", // Home
-	"M": "", // Mailing
-	"N": "", // Birth (nee)
-	"O": "", // Office
-	"P": "", // Permanent
+	"B": "Firm/Business",
+	"C": "Current or Temporary",
+	"F": "Country of Origin",
+	"H": "Home",
+	"M": "Mailing",
+	"N": "Birth (nee)",
+	"O": "Office",
+	"P": "Permanent",
 }
 
 // HL7 Table 0191
@@ -345,11 +692,11 @@ var ReferencedDataTypes = ControlTable{
 
 // HL7 Table 0200
 var NameTypeCodes = ControlTable{
-	"A": "",
-	"C": "",
-	"D": "",
-	"L": "",
-	"M": "",
+	"A": "Alias name",
+	"C": "Adopted name",
+	"D": "Display name",
+	"L": "Legal name",
+	"M": "Maiden name",
 }
 
 // HL7 Table 0201
@@ -414,12 +761,19 @@ var PriceTypes = ControlTable{
 	"TP": "total price",
 }
 
+// HL7 Table 0206
+var SegmentActionCodes = ControlTable{
+	"A": "Add/Insert",
+	"D": "Delete",
+	"U": "Update",
+}
+
 // HL7 Table 0207
 var ProcessingModes = ControlTable{
-	"a":           "",
-	"i":           "",
-	"Not present": "",
-	"r":           "",
+	"a":           "Archive",
+	"i":           "Initial load",
+	"Not present": "Not present (the default, meaning current processing)",
+	"r":           "Restore from archive",
 }
 
 // HL7 Table 0209
@@ -434,6 +788,12 @@ var RelationalOperators = ControlTable{
 	"GN": "Generic",
 }
 
+// HL7 Table 0210
+var RelationalConjunctions = ControlTable{
+	"AND": "Default",
+	"OR":  "Or",
+}
+
 // HL7 Table 0211
 var AlternateCharacterSets = ControlTable{
 	"8859/1":          "ISO 8859/1",
@@ -464,6 +824,66 @@ var DaysOfWeek = ControlTable{
 	"FRI": "Friday",
 }
 
+// HL7 Table 0271
+var DocumentCompletionStatuses = ControlTable{
+	"AU": "Authenticated",
+	"DI": "Dictated",
+	"DO": "Documented",
+	"IN": "Incomplete",
+	"IP": "In progress",
+	"LA": "Legally authenticated",
+	"PA": "Pre-authenticated",
+}
+
+// HL7 Table 0272
+var DocumentConfidentialityStatuses = ControlTable{
+	"R": "Restricted",
+	"U": "Usual control",
+	"V": "Very restricted",
+}
+
+// HL7 Table 0273
+var DocumentAvailabilityStatuses = ControlTable{
+	"AV": "Available for patient care",
+	"CA": "Deleted",
+	"OB": "Obsolete",
+	"UN": "Unavailable for patient care",
+}
+
+// HL7 Table 0275
+var DocumentStorageStatuses = ControlTable{
+	"AA": "Active and archived",
+	"AC": "Active",
+	"AR": "Archived (not active)",
+	"PU": "Purged",
+}
+
+// HL7 Table 0278
+var FillerStatusCodes = ControlTable{
+	"Pending":   "Appointment has not yet been confirmed",
+	"Waitlist":  "Appointment has been placed on a waiting list for a particular slot, or set of slots",
+	"Booked":    "The indicated appointment is booked",
+	"Started":   "The indicated appointment has begun and is currently in progress",
+	"Complete":  "The indicated appointment has completed normally",
+	"Cancelled": "The indicated appointment was stopped from occurring (canceled prior to starting)",
+	"Dc":        "The indicated appointment was discontinued (DC'ed while in progress, discontinued parent appointment, or discontinued child appointment)",
+	"Deleted":   "The indicated appointment was deleted from the filler application",
+	"Blocked":   "The indicated time slot(s) is(are) blocked",
+	"Overbook":  "The appointment has been confirmed; however it is confirmed in an overbooked state",
+	"Noshow":    "The patient did not show up for the appointment",
+}
+
+// HL7 Table 0287
+var ActionCodes = ControlTable{
+	"AD": "Add",
+	"CO": "Correct",
+	"DE": "Delete",
+	"LI": "Link",
+	"UC": "Unchanged",
+	"UN": "Unlink",
+	"UP": "Update",
+}
+
 // HL7 Table 0291
 var ReferencedDataSubTypes = ControlTable{
 	"TIFF":         "TIFF image data",
@@ -502,6 +922,29 @@ var UniversalIdTypes = ControlTable{
 	"x500":   "X.500 directory name",
 }
 
+// HL7 Table 0321
+var DispenseMethods = ControlTable{
+	"AD": "Automatic dispensing",
+	"F":  "Floor stock",
+	"TR": "Traditional",
+	"UD": "Unit dose",
+}
+
+// HL7 Table 0322
+var CompletionStatuses = ControlTable{
+	"CP": "Complete",
+	"NA": "Not administered",
+	"PA": "Partially administered",
+	"RE": "Refused",
+}
+
+// HL7 Table 0323
+var RxActionCodes = ControlTable{
+	"A": "Add",
+	"D": "Delete",
+	"U": "Update",
+}
+
 // HL7 Table 4000
 var NameRepresentationCodes = ControlTable{
 	"I": "Ideographic",
@@ -509,6 +952,41 @@ var NameRepresentationCodes = ControlTable{
 	"P": "Phonetic",
 }
 
+// HL7 Table 0001 (v2.5)
+var AdministrativeSexV25 = AdministrativeSex.with(ControlTable{
+	"A": "Ambiguous",
+	"N": "Not applicable",
+})
+
+// HL7 Table 0003 (v2.4)
+var EventTypeV24 = EventType.with(ControlTable{
+	"M12": "MFN/MFK - Master file notification message",
+})
+
+// HL7 Table 0004 (v2.5)
+var PatientClassesV25 = PatientClasses.with(ControlTable{
+	"C": "Commercial account",
+	"N": "Not applicable",
+	"U": "Unknown",
+})
+
+// HL7 Table 0085 (v2.5)
+var ObservationResultStatusesV25 = ObservationResultStatuses.with(ControlTable{
+	"N": "Not asked; the observation was not sought though the universal service ID implies it would be",
+})
+
+// HL7 Table 0125 (v2.5)
+var ValueTypesV25 = ValueTypes.with(ControlTable{
+	"CNE": "Coded with no exceptions",
+	"CWE": "Coded with exceptions",
+	"DR":  "Date/time range",
+	"DTM": "Date/time",
+	"ID":  "Coded value for HL7 defined tables",
+	"IS":  "Coded value for user-defined tables",
+	"MA":  "Multiplexed array",
+	"NA":  "Numeric array",
+})
+
 // ISO 3166
 var CountryCodes = ControlTable{
 	"ABW": "", "AFG": "", "AGO": "", "AIA": "", "ALA": "", "ALB": "", "AND": "", "ARE": "", "ARG": "", "ARM": "",
@@ -721,10 +1199,21 @@ var IsoDenominations = ControlTable{
 	"ZWG": "Zimbabwe Gold",
 }
 
-var SegmentTypes = map[string]struct{}{
+/*
+SegmentTypes holds the names of the segments defined by the standard. It is
+a copy, so changing it does not change which segments are recognised: custom
+segments are added with RegisterSegment, which also tells their struct type
+apart.
+*/
+var SegmentTypes = maps.Clone(standardSegments)
+
+// standardSegments are the segments defined by the standard; see
+// RegisterSegment for others.
+var standardSegments = map[string]struct{}{
 	"ACC": {},
 	"ADD": {},
 	"AID": {},
+	"AIG": {},
 	"AIL": {},
 	"AIP": {},
 	"AIS": {},
@@ -784,6 +1273,7 @@ var SegmentTypes = map[string]struct{}{
 	"OM4": {},
 	"OM5": {},
 	"OM6": {},
+	"OM7": {}, // as of v2.4
 	"ORC": {},
 	"PCR": {},
 	"PD1": {},
@@ -804,6 +1294,8 @@ var SegmentTypes = map[string]struct{}{
 	"RDF": {},
 	"RDT": {},
 	"RF1": {},
+	"RGS": {},
+	"ROL": {},
 	"RQ1": {},
 	"RQD": {},
 	"RXA": {},
@@ -0,0 +1,55 @@
+package faraday
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlTable(t *testing.T) {
+	require.Equal(t, "ADT/ACK - Register a patient", EventType.Describe("A04"))
+	require.Equal(t, "", EventType.Describe("A99"))
+
+	desc, ok := TableMap["0085"].Lookup("F")
+	require.True(t, ok)
+	require.Equal(t, "Final results; can only be changed with a corrected result", desc)
+	_, ok = TableMap["0085"].Lookup("Q")
+	require.False(t, ok)
+
+	for id, table := range TableMap {
+		if id == "ISO3166" {
+			continue
+		}
+		for code, desc := range *table {
+			require.NotEmpty(t, desc, "table %s, code %s", id, code)
+		}
+	}
+}
+
+func TestValidate_Tables(t *testing.T) {
+	msg := ORM_O01{
+		MSH: MSH{
+			FieldSeparator:     "|",
+			EncodingCharacters: "^~\\&",
+			MessageType:        CM_MSG{Type: "ORM", Event: "O01"},
+			MessageControlId:   "1",
+			ProcessingId:       PT{ProcessingId: "P"},
+			VersionId:          "2.3",
+		},
+		Patient: PatientGroup{PID: PID{
+			InternalPatientId: []CX{{IdNumber: "123"}},
+			PatientName:       []XPN{{FamilyName: "DOE"}},
+			Sex:               "X",
+		}},
+		Order: []OrderGroup{{ORC: ORC{OrderControl: "ZZ", OrderStatus: "CM"}}},
+	}
+
+	var got []string
+	for _, issue := range Validate(msg).Issues {
+		got = append(got, issue.String())
+	}
+	require.Equal(t, []string{
+		"PID[1]-8: warning: value 'X' not found in table 0001",
+		"ORC[1]-1: warning: value 'ZZ' not found in table 0119",
+	}, got)
+}
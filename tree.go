@@ -0,0 +1,159 @@
+package faraday
+
+import (
+	"bytes"
+)
+
+/*
+Message is a schema-less representation of an HL7 message, for when there is
+no struct describing it. Every segment, field, repetition, component and
+subcomponent is kept in the order it was received. Values are unescaped
+unless the Decoder was told to keep escape sequences.
+
+For MSH, Fields[0] holds MSH-1 (the field separator) and Fields[1] holds MSH-2
+(the encoding characters) as-is.
+*/
+type Message struct {
+	Segments []Segment
+}
+
+type Segment struct {
+	Name   string
+	Fields []Field // Fields[0] is SEG-1
+}
+
+type Field struct {
+	Repetitions []Repetition
+}
+
+type Repetition struct {
+	Components []Component
+}
+
+type Component struct {
+	Subcomponents []Subcomponent
+}
+
+type Subcomponent string
+
+// All returns every segment with the given name, in message order.
+func (m Message) All(name string) []Segment {
+	var segments []Segment
+	for _, seg := range m.Segments {
+		if seg.Name == name {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+/*
+Map converts the message into nested maps and slices, e.g. for scripting or
+JSON output. The map is keyed by segment name, each holding a list of the
+segments with that name, and a segment is a list of its fields (index 0 holds
+SEG-1). Values which consist of a single part are collapsed into that part,
+so a field with one repetition becomes that repetition, a component with one
+subcomponent becomes a string, etc:
+
+	PID|1||123^^^MRN~456^^^ACCT||DOE^JANE
+
+	{"PID": [["1", "", [["123", "", "", "MRN"], ["456", "", "", "ACCT"]], "", ["DOE", "JANE"]]]}
+*/
+func (m Message) Map() map[string]any {
+	out := make(map[string]any)
+	for _, seg := range m.Segments {
+		fields := make([]any, len(seg.Fields))
+		for i, field := range seg.Fields {
+			fields[i] = field.collapse()
+		}
+		list, _ := out[seg.Name].([]any)
+		out[seg.Name] = append(list, fields)
+	}
+	return out
+}
+
+func (f Field) collapse() any {
+	reps := make([]any, len(f.Repetitions))
+	for i, rep := range f.Repetitions {
+		reps[i] = rep.collapse()
+	}
+	return collapse(reps)
+}
+
+func (r Repetition) collapse() any {
+	comps := make([]any, len(r.Components))
+	for i, comp := range r.Components {
+		comps[i] = comp.collapse()
+	}
+	return collapse(comps)
+}
+
+func (c Component) collapse() any {
+	subs := make([]any, len(c.Subcomponents))
+	for i, sub := range c.Subcomponents {
+		subs[i] = string(sub)
+	}
+	return collapse(subs)
+}
+
+func collapse(parts []any) any {
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return parts
+	}
+}
+
+// parseSegmentTree splits a raw segment into a Segment node.
+func parseSegmentTree(raw []byte, delims delimiters, keepEscapes bool) Segment {
+	seg := Segment{Name: string(raw[:3])}
+	data := segmentFieldsData(raw)
+	if isHeaderSegment(seg.Name) {
+		seg.Fields = append(seg.Fields, newLeafField(raw[3:4]))
+		data = nil
+		if len(raw) >= 8 {
+			encChars := encodingCharacters(raw[3:])
+			seg.Fields = append(seg.Fields, newLeafField(encChars))
+			if len(raw) > 4+len(encChars) {
+				data = raw[5+len(encChars):]
+			}
+		}
+		if data == nil {
+			return seg
+		}
+	}
+	for rawField := range bytes.SplitSeq(data, []byte{delims.field}) {
+		var field Field
+		for rawRep := range bytes.SplitSeq(rawField, []byte{delims.repeat}) {
+			var rep Repetition
+			for rawComp := range bytes.SplitSeq(rawRep, []byte{delims.component}) {
+				var comp Component
+				for rawSub := range bytes.SplitSeq(rawComp, []byte{delims.subcomponent}) {
+					if !keepEscapes {
+						rawSub = delims.unescapeText(rawSub)
+					}
+					comp.Subcomponents = append(comp.Subcomponents, Subcomponent(rawSub))
+				}
+				rep.Components = append(rep.Components, comp)
+			}
+			field.Repetitions = append(field.Repetitions, rep)
+		}
+		seg.Fields = append(seg.Fields, field)
+	}
+	return seg
+}
+
+func newLeafField(raw []byte) Field {
+	return Field{Repetitions: []Repetition{{Components: []Component{{Subcomponents: []Subcomponent{Subcomponent(raw)}}}}}}
+}
+
+// value returns the text of a leaf field, such as MSH-1 and MSH-2.
+func (f Field) value() string {
+	if len(f.Repetitions) == 0 || len(f.Repetitions[0].Components) == 0 || len(f.Repetitions[0].Components[0].Subcomponents) == 0 {
+		return ""
+	}
+	return string(f.Repetitions[0].Components[0].Subcomponents[0])
+}
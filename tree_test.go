@@ -0,0 +1,71 @@
+package faraday
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Message(t *testing.T) {
+	raw := "MSH|^~\\&|VendorApp|VendorFac|||20250724000001||ADT^A08|MSG1|P|2.3\r" +
+		"PID|1||123^^^MRN~456^^^ACCT&1.2.3||DOE^JANE\r" +
+		"ZPI|custom|A \\T\\ B||\r" +
+		"NTE|1\r"
+
+	var msg Message
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(raw))).Decode(&msg))
+	require.Len(t, msg.Segments, 4)
+
+	msh := msg.Segments[0]
+	require.Equal(t, "MSH", msh.Name)
+	require.Equal(t, "|", msh.Fields[0].value())
+	require.Equal(t, "^~\\&", msh.Fields[1].value())
+	require.Equal(t, "VendorApp", msh.Fields[2].value())
+	require.Equal(t,
+		Field{Repetitions: []Repetition{{Components: []Component{
+			{Subcomponents: []Subcomponent{"ADT"}},
+			{Subcomponents: []Subcomponent{"A08"}},
+		}}}},
+		msh.Fields[8],
+	)
+
+	pid := msg.All("PID")
+	require.Len(t, pid, 1)
+	require.Len(t, pid[0].Fields, 5)
+	require.Len(t, pid[0].Fields[2].Repetitions, 2)
+	require.Equal(t,
+		Component{Subcomponents: []Subcomponent{"ACCT", "1.2.3"}},
+		pid[0].Fields[2].Repetitions[1].Components[3],
+	)
+
+	zpi := msg.All("ZPI")[0]
+	require.Len(t, zpi.Fields, 4)
+	require.Equal(t, "A & B", zpi.Fields[1].value())
+
+	out, err := Marshal(msg)
+	require.NoError(t, err)
+	require.Equal(t, raw, string(out))
+}
+
+func TestDecoder_Map(t *testing.T) {
+	raw := "MSH|^~\\&|VendorApp|VendorFac|||20250724000001||ADT^A08|MSG1|P|2.3\r" +
+		"PID|1||123^^^MRN~456^^^ACCT||DOE^JANE\r" +
+		"OBX|1|ST|A||first\r" +
+		"OBX|2|ST|B||second\r"
+
+	var m map[string]any
+	require.NoError(t, NewDecoder(bytes.NewReader([]byte(raw))).Decode(&m))
+
+	require.Equal(t,
+		[]any{[]any{"1", "", []any{[]any{"123", "", "", "MRN"}, []any{"456", "", "", "ACCT"}}, "", []any{"DOE", "JANE"}}},
+		m["PID"],
+	)
+	require.Len(t, m["OBX"], 2)
+	require.Equal(t, "second", m["OBX"].([]any)[1].([]any)[4])
+
+	b, err := json.Marshal(m["MSH"])
+	require.NoError(t, err)
+	require.Equal(t, `[["|","^~\\\u0026","VendorApp","VendorFac","","","20250724000001","",["ADT","A08"],"MSG1","P","2.3"]]`, string(b))
+}
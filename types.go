@@ -1,9 +1,8 @@
 package faraday
 
 import (
+	"fmt"
 	"strconv"
-
-	"github.com/s-hammon/p"
 )
 
 // these are the basic HL7 "types"
@@ -29,20 +28,18 @@ type FT string
 	NUMERICAL
 */
 
-// Numeric
-// TODO: implement ToNumeric
-// parse +/-123.792
+// Numeric, e.g. +/-123.792
 type NM string
 
 // Sequence ID (integer)
 type SI string
 
-func (s SI) Int() int {
+func (s SI) Int() (int, error) {
 	i, err := strconv.Atoi(string(s))
-	if err != nil {
-		panic(p.Format("non-numeric value for SI: %v", s))
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("non-numeric value for SI: '%s'", s)
 	}
-	return i
+	return i, nil
 }
 
 // Composite quantity
@@ -93,8 +90,8 @@ const (
 type SN struct {
 	Comparator comparator
 	FirstNum   NM
+	Separator  separator
 	SecondNum  NM
-	Separator  ST
 }
 
 /*
@@ -158,16 +155,21 @@ type DT string
 // Time HH[MM[SS[.S[S[S[S]]]]]][+/-ZZZZ]
 type TM string
 
-// Timestamp YYYY[MM[dd]]HH[MM[SS[.S[S[S[S]]]]]][+/-ZZZZ]
+// Timestamp YYYY[MM[dd]]HH[MM[SS[.S[S[S[S]]]]]][+/-ZZZZ], optionally followed
+// by the degree of precision as a second component. As of v2.6 TS fields hold
+// a DTM, which has no second component (see Version).
 type TS string
 
-// TODO: implement ToDatetime
+// Date/Time YYYY[MM[DD[HH[MM[SS[.S[S[S[S]]]]]]]]][+/-ZZZZ] (v2.5), which
+// replaces TS in later versions
+type DTM string
 
 /*
 	CODE VALUES
 */
 
-// Coded Element
+// Coded Element. As of v2.6 CE fields hold a CWE or CNE, whose additional
+// components it takes (see Version).
 type CE struct {
 	Identifier            ST
 	Text                  ST
@@ -175,6 +177,37 @@ type CE struct {
 	AlternateIdentifier   ST
 	AlternateText         ST
 	AlternateCodingSystem ST
+
+	// components added in later versions (see Version)
+	CodingSystemVersionId          ST
+	AlternateCodingSystemVersionId ST
+	OriginalText                   ST
+}
+
+// Coded with Exceptions (v2.5)
+type CWE struct {
+	Identifier                     ST
+	Text                           ST
+	CodingSystem                   ID // HL7 0396
+	AlternateIdentifier            ST
+	AlternateText                  ST
+	AlternateCodingSystem          ID // HL7 0396
+	CodingSystemVersionId          ST
+	AlternateCodingSystemVersionId ST
+	OriginalText                   ST
+}
+
+// Coded with No Exceptions (v2.5)
+type CNE struct {
+	Identifier                     ST
+	Text                           ST
+	CodingSystem                   ID // HL7 0396
+	AlternateIdentifier            ST
+	AlternateText                  ST
+	AlternateCodingSystem          ID // HL7 0396
+	CodingSystemVersionId          ST
+	AlternateCodingSystemVersionId ST
+	OriginalText                   ST
 }
 
 // Coded Element with formatted values
@@ -216,6 +249,12 @@ type CX struct {
 	AssigningAuthority   HD
 	IdentifierTypeCode   IS
 	AssigningFacility    HD
+
+	// components added in later versions (see Version)
+	EffectiveDate               DT
+	ExpirationDate              DT
+	AssigningJurisdiction       CWE
+	AssigningAgencyOrDepartment CWE
 }
 
 // Extended Composite ID number and name
@@ -234,6 +273,17 @@ type XCN struct {
 	CheckDigitSchemeCode ID // HL7 0061
 	IdentifierTypeCode   IS
 	AssigningFacility    HD
+
+	// components added in later versions (see Version)
+	NameRepresentationCode      ID // HL7 4000
+	NameContext                 CE
+	NameValidityRange           DR
+	NameAssemblyOrder           ID // HL7 0444
+	EffectiveDate               TS
+	ExpirationDate              TS
+	ProfessionalSuffix          ST
+	AssigningJurisdiction       CWE
+	AssigningAgencyOrDepartment CWE
 }
 
 /*
@@ -339,6 +389,111 @@ type CM_OBS struct {
 	Floor               IS
 }
 
+// Error Location & Description (ERR.1)
+type CM_ELD struct {
+	SegmentId            ST
+	Sequence             NM
+	FieldPosition        NM
+	CodeIdentifyingError CE // HL7 0357
+}
+
+// Deliver-to Location (RXO.8, RXE.8)
+type CM_LA1 struct {
+	PointOfCare         IS
+	Room                IS
+	Bed                 IS
+	Facility            HD
+	LocationStatus      IS
+	PatientLocationType IS
+	Building            IS
+	Floor               IS
+	Address             AD
+}
+
+// Activation/Inactivation Date (STF.12, STF.13)
+type CM_DIN struct {
+	Date            TS
+	InstitutionName CE
+}
+
+// Practitioner Specialty (PRA.5)
+type CM_SPD struct {
+	SpecialtyName       ST
+	GoverningBoard      ST
+	EligibleOrCertified ID
+	CertificationDate   DT
+}
+
+// Practitioner ID Numbers (PRA.6)
+type CM_PLN struct {
+	IdNumber            ST
+	IdNumberType        IS // HL7 0338
+	StateQualifyingInfo ST
+	ExpirationDate      DT
+}
+
+// Privileges (PRA.7)
+type CM_PIP struct {
+	Privilege      CE
+	PrivilegeClass CE
+	ExpirationDate DT
+	ActivationDate DT
+	Facility       EI
+}
+
+// Numeric Range (OM2.6 - OM2.9)
+type CM_RNG struct {
+	Low  NM
+	High NM
+}
+
+// Reference Range (OM2.6)
+type CM_RFR struct {
+	NumericRange        CM_RNG
+	AdministrativeSex   IS // HL7 0001
+	AgeRange            CM_RNG
+	GestationalAgeRange CM_RNG
+	Species             ST
+	RaceSubspecies      ST
+	Conditions          TX
+}
+
+// Absolute Range (OM2.8)
+type CM_ABS struct {
+	Range            CM_RNG
+	NumericChange    NM
+	PercentPerChange NM
+	Days             NM
+}
+
+// Delta Check Criteria (OM2.9)
+type CM_DLT struct {
+	Range             CM_RNG
+	NumericThreshold  NM
+	ChangeComputation ST
+	LengthOfTimeDays  NM
+}
+
+// Dispense-to Location (RXD.13, RXG.11, RXA.11)
+type CM_LA2 struct {
+	PointOfCare                IS
+	Room                       IS
+	Bed                        IS
+	Facility                   HD
+	LocationStatus             IS
+	PatientLocationType        IS
+	Building                   IS
+	Floor                      IS
+	StreetAddress              ST
+	OtherDesignation           ST
+	City                       ST
+	State                      ST
+	Zip                        ST
+	Country                    ID // ISO 3166
+	AddressType                ID // HL7 0190
+	OtherGeographicDesignation ST
+}
+
 /*
 	DEMOGRAPHICS
 */
@@ -380,6 +535,21 @@ type XAD struct {
 	OtherGeographicDesignation ST
 	CountyCode                 IS
 	CensusTract                IS
+
+	// components added in later versions (see Version)
+	AddressRepresentationCode ID // HL7 4000
+	AddressValidityRange      DR
+	EffectiveDate             TS
+	ExpirationDate            TS
+	ExpirationReason          CWE // HL7 0616
+	TemporaryIndicator        ID  // HL7 0136
+	BadAddressIndicator       ID  // HL7 0136
+	AddressUsage              ID  // HL7 0617
+	Addressee                 ST
+	Comment                   ST
+	PreferenceOrder           NM
+	ProtectionCode            CWE // HL7 0618
+	AddressIdentifier         EI
 }
 
 // Extended Person Name
@@ -392,6 +562,14 @@ type XPN struct {
 	Degree                 ST
 	NameTypeCode           ID // HL7 0200
 	NameRepresentationCode ID // HL7 4000
+
+	// components added in later versions (see Version)
+	NameContext        CE
+	NameValidityRange  DR
+	NameAssemblyOrder  ID // HL7 0444
+	EffectiveDate      TS
+	ExpirationDate     TS
+	ProfessionalSuffix ST
 }
 
 // Extended Composite Name and ID number for organizations
@@ -404,6 +582,10 @@ type XON struct {
 	AssigningAuthority   HD
 	IdentifierTypeCode   IS
 	AssigningFacility    HD
+
+	// components added in later versions (see Version)
+	NameRepresentationCode ID // HL7 0465
+	OrganizationIdentifier ST
 }
 
 // Extended Telecommunication Number
@@ -417,6 +599,17 @@ type XTN struct {
 	PhoneNumber              NM
 	Extension                NM
 	AnyText                  ST
+
+	// components added in later versions (see Version)
+	ExtensionPrefix                   ST
+	SpeedDialCode                     ST
+	UnformattedTelephoneNumber        ST
+	EffectiveStartDate                TS
+	ExpirationDate                    TS
+	ExpirationReason                  CWE // HL7 0868
+	ProtectionCode                    CWE // HL7 0618
+	SharedTelecommunicationIdentifier EI
+	PreferenceOrder                   NM
 }
 
 /*
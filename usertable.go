@@ -0,0 +1,276 @@
+package faraday
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Tables holds the code tables fields are validated against (see the tbl
+option of hl7 tags), layered over the HL7 and ISO tables of TableMap. The
+user-defined tables of the standard, e.g. 0023 (admit source) or 0010
+(physician ID), are left for each site to configure, and a site may also
+replace the built-in tables:
+
+	tables := faraday.NewTables()
+	if err := tables.LoadFile("tables/0023.csv"); err != nil { ... }
+
+CSV files hold one table each, named after the file, with a header naming
+the code, description, effective and deprecated columns (only code is
+required):
+
+	code,description,effective,deprecated
+	1,Physician referral,,
+	2,Clinic referral,,
+	7,Emergency room,false,
+
+JSON files hold any number of tables:
+
+	[{"id": "0023", "name": "Admit source", "entries": [
+		{"code": "1", "description": "Physician referral"},
+		{"code": "7", "description": "Emergency room", "effective": false}
+	]}]
+
+Codes are effective unless stated otherwise. Values which are not found in
+their table, no longer effective or deprecated are reported as warnings by
+Validate.
+*/
+type Tables struct {
+	mu     sync.RWMutex
+	tables map[string]*Table
+}
+
+// DefaultTables are the tables used by Validate and strict Decoders, unless
+// given others with ValidateWith or Decoder.UseTables.
+var DefaultTables = NewTables()
+
+// NewTables returns a set of tables holding the built-in ones only.
+func NewTables() *Tables {
+	return &Tables{tables: map[string]*Table{}}
+}
+
+// Table is a code table, e.g. HL7 table 0004.
+type Table struct {
+	Id      string       `json:"id"`
+	Name    string       `json:"name,omitempty"`
+	Entries []TableEntry `json:"entries"`
+
+	codes map[ID]int // index of the entry of each code
+}
+
+type TableEntry struct {
+	Code        ID     `json:"code"`
+	Description string `json:"description,omitempty"`
+	Effective   bool   `json:"effective"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+}
+
+// UnmarshalJSON makes entries effective unless stated otherwise.
+func (e *TableEntry) UnmarshalJSON(b []byte) error {
+	type entry TableEntry
+	v := entry{Effective: true}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*e = TableEntry(v)
+	return nil
+}
+
+// NewTable returns a table with the given entries. A code listed twice takes
+// its last entry.
+func NewTable(id, name string, entries ...TableEntry) *Table {
+	t := &Table{Id: id, Name: name}
+	for _, entry := range entries {
+		t.add(entry)
+	}
+	return t
+}
+
+func (t *Table) add(entry TableEntry) {
+	if t.codes == nil {
+		t.codes = make(map[ID]int, len(t.Entries))
+	}
+	if i, ok := t.codes[entry.Code]; ok {
+		t.Entries[i] = entry
+		return
+	}
+	t.codes[entry.Code] = len(t.Entries)
+	t.Entries = append(t.Entries, entry)
+}
+
+// Lookup returns the entry of a code.
+func (t *Table) Lookup(code ID) (TableEntry, bool) {
+	i, ok := t.codes[code]
+	if !ok {
+		return TableEntry{}, false
+	}
+	return t.Entries[i], true
+}
+
+// Valid reports whether code is an effective code of the table.
+func (t *Table) Valid(code ID) bool {
+	entry, ok := t.Lookup(code)
+	return ok && entry.Effective
+}
+
+// builtinTables caches the built-in tables.
+var builtinTables sync.Map // map[*ControlTable]*Table
+
+func builtinTable(version, id string) (*Table, bool) {
+	ct, ok := versionTable(version, id)
+	if !ok {
+		return nil, false
+	}
+	if t, ok := builtinTables.Load(ct); ok {
+		return t.(*Table), true
+	}
+	codes := make([]ID, 0, len(*ct))
+	for code := range *ct {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	t := &Table{Id: id}
+	for _, code := range codes {
+		t.add(TableEntry{Code: code, Description: (*ct)[code], Effective: true})
+	}
+	builtinTables.Store(ct, t)
+	return t, true
+}
+
+// Lookup returns the table with the given id, taking a configured table over
+// the built-in one (of v2.3).
+func (ts *Tables) Lookup(id string) (*Table, bool) {
+	return ts.LookupVersion(id, "")
+}
+
+// LookupVersion returns the table with the given id, taking a configured
+// table over the built-in one of version (see Version).
+func (ts *Tables) LookupVersion(id, version string) (*Table, bool) {
+	ts.mu.RLock()
+	t, ok := ts.tables[id]
+	ts.mu.RUnlock()
+	if ok {
+		return t, true
+	}
+	return builtinTable(version, id)
+}
+
+// Add adds a table, replacing any table with the same id.
+func (ts *Tables) Add(t *Table) {
+	if t.codes == nil {
+		t = NewTable(t.Id, t.Name, t.Entries...)
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.tables[t.Id] = t
+}
+
+// LoadFile adds the tables of a .csv or .json file. A CSV file holds the
+// table named after the file, e.g. 0023.csv holds table 0023.
+func (ts *Tables) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("LoadFile: %w", err)
+	}
+	defer f.Close()
+
+	ext := filepath.Ext(path)
+	switch strings.ToLower(ext) {
+	case ".csv":
+		err = ts.LoadCSV(strings.TrimSuffix(filepath.Base(path), ext), f)
+	case ".json":
+		err = ts.LoadJSON(f)
+	default:
+		err = fmt.Errorf("unsupported file type '%s'", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("LoadFile %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadCSV adds table id from CSV data with a header row naming the code,
+// description, effective and deprecated columns.
+func (ts *Tables) LoadCSV(id string, r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("table %s: reading header: %w", id, err)
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["code"]; !ok {
+		return fmt.Errorf("table %s: header has no code column", id)
+	}
+	column := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	flag := func(record []string, name string, def bool) (bool, error) {
+		v := column(record, name)
+		if v == "" {
+			return def, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s value '%s'", name, v)
+		}
+		return b, nil
+	}
+
+	table := NewTable(id, "")
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("table %s: %w", id, err)
+		}
+		entry := TableEntry{Code: ID(column(record, "code")), Description: column(record, "description")}
+		if entry.Code == "" {
+			continue
+		}
+		if entry.Effective, err = flag(record, "effective", true); err != nil {
+			return fmt.Errorf("table %s, code %s: %w", id, entry.Code, err)
+		}
+		if entry.Deprecated, err = flag(record, "deprecated", false); err != nil {
+			return fmt.Errorf("table %s, code %s: %w", id, entry.Code, err)
+		}
+		table.add(entry)
+	}
+	ts.Add(table)
+	return nil
+}
+
+// LoadJSON adds the tables of JSON data holding an array of tables.
+func (ts *Tables) LoadJSON(r io.Reader) error {
+	var tables []*Table
+	if err := json.NewDecoder(r).Decode(&tables); err != nil {
+		return fmt.Errorf("decode tables: %w", err)
+	}
+	for _, t := range tables {
+		if t.Id == "" {
+			return fmt.Errorf("table without id")
+		}
+	}
+	for _, t := range tables {
+		ts.Add(NewTable(t.Id, t.Name, t.Entries...))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package faraday
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTables(t *testing.T) {
+	tables := NewTables()
+
+	table, ok := tables.Lookup("0008")
+	require.True(t, ok)
+	entry, ok := table.Lookup("AA")
+	require.True(t, ok)
+	require.True(t, entry.Effective)
+	require.Contains(t, entry.Description, "Application Accept")
+	_, ok = tables.Lookup("0023")
+	require.False(t, ok)
+
+	require.NoError(t, tables.LoadCSV("0004", strings.NewReader(
+		"code, description, effective, deprecated\n"+
+			"I,Inpatient,,\n"+
+			"O,Outpatient\n"+
+			"X,Observation,false,\n"+
+			"R,Recurring,,true\n")))
+	table, ok = tables.Lookup("0004")
+	require.True(t, ok)
+	require.Equal(t, []TableEntry{
+		{Code: "I", Description: "Inpatient", Effective: true},
+		{Code: "O", Description: "Outpatient", Effective: true},
+		{Code: "X", Description: "Observation"},
+		{Code: "R", Description: "Recurring", Effective: true, Deprecated: true},
+	}, table.Entries)
+	require.True(t, table.Valid("I"))
+	require.False(t, table.Valid("X"))
+	require.False(t, table.Valid("Z"))
+
+	require.Error(t, tables.LoadCSV("0004", strings.NewReader("description\nInpatient\n")))
+	require.Error(t, tables.LoadCSV("0004", strings.NewReader("code,effective\nI,maybe\n")))
+
+	// configured tables take precedence over the built-in ones
+	require.NoError(t, tables.LoadJSON(strings.NewReader(`[
+		{"id": "0023", "name": "Admit source", "entries": [
+			{"code": "1", "description": "Physician referral"},
+			{"code": "7", "description": "Emergency room", "effective": false}
+		]},
+		{"id": "0008", "entries": [{"code": "AA"}]}
+	]`)))
+	table, ok = tables.Lookup("0023")
+	require.True(t, ok)
+	require.Equal(t, "Admit source", table.Name)
+	require.True(t, table.Valid("1"))
+	require.False(t, table.Valid("7"))
+	table, _ = tables.Lookup("0008")
+	require.Len(t, table.Entries, 1)
+	require.Error(t, tables.LoadJSON(strings.NewReader(`[{"entries": []}]`)))
+}
+
+func TestTables_LoadFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0062.csv"), []byte("code,description\n01,Patient request\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "site.json"), []byte(`[{"id": "0188", "entries": [{"code": "JDOE"}]}]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "site.txt"), nil, 0o644))
+
+	tables := NewTables()
+	require.NoError(t, tables.LoadFile(filepath.Join(dir, "0062.csv")))
+	require.NoError(t, tables.LoadFile(filepath.Join(dir, "site.json")))
+	require.Error(t, tables.LoadFile(filepath.Join(dir, "site.txt")))
+	require.Error(t, tables.LoadFile(filepath.Join(dir, "missing.csv")))
+
+	table, ok := tables.Lookup("0062")
+	require.True(t, ok)
+	require.True(t, table.Valid("01"))
+	table, ok = tables.Lookup("0188")
+	require.True(t, ok)
+	require.True(t, table.Valid("JDOE"))
+}
+
+func TestValidateWith(t *testing.T) {
+	tables := NewTables()
+	require.NoError(t, tables.LoadCSV("0004", strings.NewReader("code,effective,deprecated\nI\nX,false\nR,,true\n")))
+
+	msg := ADT_A01{
+		MSH: MSH{
+			FieldSeparator:     "|",
+			EncodingCharacters: "^~\\&",
+			MessageType:        CM_MSG{Type: "ADT", Event: "A01"},
+			MessageControlId:   "1",
+			ProcessingId:       PT{ProcessingId: "P"},
+			VersionId:          "2.3",
+		},
+		EVN: EVN{RecordedDateTime: "20250724", EventReasonCode: "01"},
+		PID: PID{InternalPatientId: []CX{{IdNumber: "123"}}, PatientName: []XPN{{FamilyName: "DOE"}}},
+		PV1: PV1{PatientClass: "Q"},
+	}
+	warnings := func(report ValidationReport) []string {
+		var got []string
+		for _, issue := range report.Issues {
+			got = append(got, issue.String())
+		}
+		return got
+	}
+
+	require.Equal(t, []string{
+		"EVN[1]-4: warning: table 0062 is not configured",
+		"PV1[1]-2: warning: value 'Q' not found in table 0004",
+	}, warnings(Validate(msg)))
+
+	// the site's table replaces the built-in one
+	for code, want := range map[IS]string{
+		"I": "",
+		"E": "PV1[1]-2: warning: value 'E' not found in table 0004",
+		"Q": "PV1[1]-2: warning: value 'Q' not found in table 0004",
+		"X": "PV1[1]-2: warning: value 'X' is not effective in table 0004",
+		"R": "PV1[1]-2: warning: value 'R' is deprecated in table 0004",
+	} {
+		msg.PV1.PatientClass = code
+		got := warnings(ValidateWith(msg, tables))
+		require.Equal(t, "EVN[1]-4: warning: table 0062 is not configured", got[0])
+		if want == "" {
+			require.Len(t, got, 1)
+		} else {
+			require.Equal(t, []string{got[0], want}, got)
+		}
+	}
+}
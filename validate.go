@@ -0,0 +1,303 @@
+package faraday
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/s-hammon/p"
+)
+
+type Severity uint8
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", uint8(s))
+	}
+}
+
+// Location points at a segment, or a value within one, e.g. PID[1]-3[2].4
+// for the 4th component of the 2nd repetition of PID-3 in the first PID.
+// Zero values are left out.
+type Location struct {
+	Segment      string
+	Occurrence   int
+	Field        int
+	Repetition   int
+	Component    int
+	Subcomponent int
+}
+
+func (loc Location) String() string {
+	var sb strings.Builder
+	sb.WriteString(loc.Segment)
+	if loc.Occurrence > 0 {
+		fmt.Fprintf(&sb, "[%d]", loc.Occurrence)
+	}
+	if loc.Field > 0 {
+		fmt.Fprintf(&sb, "-%d", loc.Field)
+	}
+	if loc.Repetition > 0 {
+		fmt.Fprintf(&sb, "[%d]", loc.Repetition)
+	}
+	if loc.Component > 0 {
+		fmt.Fprintf(&sb, ".%d", loc.Component)
+	}
+	if loc.Subcomponent > 0 {
+		fmt.Fprintf(&sb, ".%d", loc.Subcomponent)
+	}
+	return sb.String()
+}
+
+type ValidationIssue struct {
+	Location Location
+	Severity Severity
+	Message  string
+}
+
+func (issue ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s: %s", issue.Location, issue.Severity, issue.Message)
+}
+
+// ValidationReport holds every problem found in a message.
+type ValidationReport struct {
+	Issues []ValidationIssue
+}
+
+// Valid reports whether the report holds no errors (warnings are allowed).
+func (r ValidationReport) Valid() bool {
+	return len(r.Errors()) == 0
+}
+
+// Errors returns the issues of error severity.
+func (r ValidationReport) Errors() []ValidationIssue {
+	var errs []ValidationIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+func (r *ValidationReport) add(loc Location, severity Severity, format string, args ...any) {
+	r.Issues = append(r.Issues, ValidationIssue{
+		Location: loc,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidationError is returned by a strict Decoder when a message's
+// validation report contains errors.
+type ValidationError struct {
+	Report ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	errs := e.Report.Errors()
+	msgs := make([]string, len(errs))
+	for i, issue := range errs {
+		msgs[i] = issue.String()
+	}
+	return fmt.Sprintf("invalid message (%d errors): %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// Validate checks a decoded message struct (or a pointer to one) against the
+// hl7 tags of its segments and fields: required segments, groups and fields
+// (opt=R), repetition limits (rep=) and table membership (tbl=) in
+// DefaultTables. Values not found in their table are reported as warnings,
+// everything else as errors.
+func Validate(msg any) ValidationReport {
+	return ValidateWith(msg, DefaultTables)
+}
+
+// ValidateWith is like Validate, checking coded values against tables.
+func ValidateWith(msg any, tables *Tables) ValidationReport {
+	var report ValidationReport
+	v := reflect.ValueOf(msg)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			report.add(Location{}, SeverityError, "no message")
+			return report
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		report.add(Location{}, SeverityError, "cannot validate %s, expected a struct", v.Type())
+		return report
+	}
+	return validate(v, defaultSegments, tables)
+}
+
+// validate validates a message struct whose segments are known to r.
+func validate(v reflect.Value, r *segmentRegistry, tables *Tables) ValidationReport {
+	var report ValidationReport
+	vd := validator{report: &report, occurrences: map[string]int{}, tables: tables}
+	vd.validateGroup(v, r.schemaOf(v.Type()))
+	return report
+}
+
+type validator struct {
+	report      *ValidationReport
+	occurrences map[string]int
+	tables      *Tables
+	version     string // MSH-12 of the message
+}
+
+func (vd *validator) validateGroup(v reflect.Value, schema *structSchema) {
+	for _, member := range schema.members {
+		field := v.Field(member.index)
+		if member.repeats {
+			if field.Len() == 0 && member.optionality == Required {
+				vd.missing(member, schema)
+			}
+			for i := range field.Len() {
+				vd.validateMember(member, field.Index(i))
+			}
+			continue
+		}
+		if field.IsZero() {
+			if member.optionality == Required {
+				vd.missing(member, schema)
+			}
+			continue
+		}
+		vd.validateMember(member, field)
+	}
+}
+
+func (vd *validator) missing(member schemaMember, parent *structSchema) {
+	where := p.Coalesce(parent.typ.Name(), "message")
+	if member.group {
+		vd.report.add(Location{}, SeverityError, "required group %s is missing from %s", member.typ.Name(), where)
+		return
+	}
+	vd.report.add(Location{Segment: member.name}, SeverityError, "required segment %s is missing from %s", member.name, where)
+}
+
+func (vd *validator) validateMember(member schemaMember, v reflect.Value) {
+	if member.group {
+		vd.validateGroup(v, member.schema)
+		return
+	}
+	if member.name == "MSH" {
+		if version := v.FieldByName("VersionId"); version.IsValid() && version.Kind() == reflect.String {
+			vd.version = version.String()
+		}
+	}
+	vd.occurrences[member.name]++
+	vd.validateSegment(Location{Segment: member.name, Occurrence: vd.occurrences[member.name]}, v)
+}
+
+func (vd *validator) validateSegment(loc Location, seg reflect.Value) {
+	report := vd.report
+	for i := range seg.NumField() {
+		sf := seg.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		spec := NewFieldSpec(uint8(i+1), seg.Field(i)).ParseTag(sf.Tag.Get("hl7"))
+		floc := loc
+		floc.Field = int(spec.Position)
+
+		if spec.Val.IsZero() || (spec.Val.Kind() == reflect.Slice && spec.Val.Len() == 0) {
+			if spec.Optionality == Required {
+				report.add(floc, SeverityError, "required field %s is missing", sf.Name)
+			}
+			continue
+		}
+
+		if spec.Val.Kind() != reflect.Slice {
+			vd.validateTableValue(floc, spec, spec.Val)
+			continue
+		}
+		n := spec.Val.Len()
+		if !spec.Repeats && n > 1 {
+			report.add(floc, SeverityError, "field %s does not repeat, got %d repetitions", sf.Name, n)
+		} else if spec.RepeatCount > 0 && n > int(spec.RepeatCount) {
+			report.add(floc, SeverityError, "field %s repeats at most %d times, got %d", sf.Name, spec.RepeatCount, n)
+		}
+		for j := range n {
+			rloc := floc
+			rloc.Repetition = j + 1
+			vd.validateTableValue(rloc, spec, spec.Val.Index(j))
+		}
+	}
+}
+
+// validateTableValue checks a coded value (or the first component of a coded
+// composite such as CE) against the field's table, if it has one.
+func (vd *validator) validateTableValue(loc Location, spec *FieldSpec, v reflect.Value) {
+	if spec.TableId == "" {
+		return
+	}
+	if v.Kind() == reflect.Struct && v.NumField() > 0 {
+		v = v.Field(0)
+		loc.Component = 1
+	}
+	if v.Kind() != reflect.String || v.Len() == 0 {
+		return
+	}
+
+	table, ok := vd.tables.LookupVersion(spec.TableId, vd.version)
+	if !ok {
+		vd.report.add(loc, SeverityWarning, "table %s is not configured", spec.TableId)
+		return
+	}
+	code := ID(v.String())
+	entry, ok := table.Lookup(code)
+	switch {
+	case !ok:
+		vd.report.add(loc, SeverityWarning, "value '%s' not found in table %s", code, spec.TableId)
+	case !entry.Effective:
+		vd.report.add(loc, SeverityWarning, "value '%s' is not effective in table %s", code, spec.TableId)
+	case entry.Deprecated:
+		vd.report.add(loc, SeverityWarning, "value '%s' is deprecated in table %s", code, spec.TableId)
+	}
+}
+
+// validateRawSegment checks the component and subcomponent counts of every
+// field repetition of a raw segment against the segment struct type in
+// version, which cannot be seen once decoded.
+func validateRawSegment(report *ValidationReport, loc Location, typ reflect.Type, raw []byte, delims delimiters, version string) {
+	rawFields := bytes.Split(raw, []byte{delims.field})
+	for i := range min(typ.NumField(), len(rawFields)) {
+		sf := typ.Field(i)
+		if !sf.IsExported() || len(rawFields[i]) == 0 {
+			continue
+		}
+		elemType := sf.Type
+		if elemType.Kind() == reflect.Slice {
+			elemType = elemType.Elem()
+		}
+		for j, rep := range bytes.Split(rawFields[i], []byte{delims.repeat}) {
+			spec := NewFieldSpec(uint8(i+1), reflect.New(elemType).Elem())
+			spec.validate(rep, delims.toSlice())
+			// values of other string types, e.g. FT in OBX-5, may hold any
+			// data type
+			_, defined := definedComponentCount(version, elemType)
+			if spec.validationErr == nil && (elemType.Kind() == reflect.Struct || defined) {
+				if n := componentCount(version, elemType); bytes.Count(rep, []byte{delims.component}) >= n {
+					spec.validationErr = fmt.Errorf("expected max %d components for field number %d", n, i+1)
+				}
+			}
+			if spec.validationErr != nil {
+				rloc := loc
+				rloc.Field, rloc.Repetition = i+1, j+1
+				report.add(rloc, SeverityError, "%s", spec.validationErr)
+			}
+		}
+	}
+}
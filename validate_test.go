@@ -0,0 +1,130 @@
+package faraday
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationString(t *testing.T) {
+	require.Equal(t, "PID", Location{Segment: "PID"}.String())
+	require.Equal(t, "PID[1]-3[2].4", Location{Segment: "PID", Occurrence: 1, Field: 3, Repetition: 2, Component: 4}.String())
+	require.Equal(t, "OBX[3]-5.1.2", Location{Segment: "OBX", Occurrence: 3, Field: 5, Component: 1, Subcomponent: 2}.String())
+}
+
+func TestValidate(t *testing.T) {
+	msg := ORU_R01{
+		MSH: MSH{
+			FieldSeparator:     "|",
+			EncodingCharacters: "^~\\&",
+			MessageControlId:   "1",
+			ProcessingId:       PT{ProcessingId: "P"},
+			VersionId:          "9.9",
+			CharacterSet:       []ID{"ASCII", "8859/1", "8859/2", "8859/3"},
+		},
+		Results: []ResultGroup{{
+			Patient: ObsPatientGroup{
+				PID: PID{PatientName: []XPN{{FamilyName: "DOE"}}},
+			},
+			Order: []ObsOrderGroup{{
+				OBR: OBR{UniversalServiceID: CE{Identifier: "CBC"}},
+				Results: []ObservationGroup{
+					{OBX: OBX{ObservationIdentifier: CE{Identifier: "WBC"}, ResultStatus: "F"}},
+					{OBX: OBX{ObservationIdentifier: CE{Identifier: "HGB"}}},
+				},
+			}},
+		}},
+	}
+
+	report := Validate(&msg)
+	require.False(t, report.Valid())
+
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	require.Equal(t, []string{
+		"MSH[1]-9: error: required field MessageType is missing",
+		"MSH[1]-12: warning: value '9.9' not found in table 0104",
+		"MSH[1]-18: error: field CharacterSet repeats at most 3 times, got 4",
+		"PID[1]-3: error: required field InternalPatientId is missing",
+		"OBX[2]-11: error: required field ResultStatus is missing",
+	}, got)
+	require.Len(t, report.Errors(), 4)
+}
+
+func TestValidate_MissingSegments(t *testing.T) {
+	report := Validate(ADT_A01{})
+	require.Equal(t,
+		[]ValidationIssue{
+			{Location: Location{Segment: "MSH"}, Severity: SeverityError, Message: "required segment MSH is missing from ADT_A01"},
+			{Location: Location{Segment: "EVN"}, Severity: SeverityError, Message: "required segment EVN is missing from ADT_A01"},
+			{Location: Location{Segment: "PID"}, Severity: SeverityError, Message: "required segment PID is missing from ADT_A01"},
+			{Location: Location{Segment: "PV1"}, Severity: SeverityError, Message: "required segment PV1 is missing from ADT_A01"},
+		},
+		report.Issues,
+	)
+
+	report = Validate(ORU_R01{MSH: MSH{
+		FieldSeparator:     "|",
+		EncodingCharacters: "^~\\&",
+		MessageType:        CM_MSG{Type: "ORU", Event: "R01"},
+		MessageControlId:   "1",
+		ProcessingId:       PT{ProcessingId: "P"},
+		VersionId:          "2.3",
+	}})
+	require.Equal(t,
+		[]ValidationIssue{{Severity: SeverityError, Message: "required group ResultGroup is missing from ORU_R01"}},
+		report.Issues,
+	)
+}
+
+func TestDecoder_Strict(t *testing.T) {
+	raw := []byte("MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|1|P|2.3\r" +
+		"PID|1||123~456^^^MRN^MR^Hosp^extra\r" +
+		"OBR|1|||CBC\r" +
+		"OBX|1|NM|WBC||5.4\r")
+
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Strict()
+	err := dec.Decode(&msg)
+
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Equal(t,
+		[]ValidationIssue{
+			{Location: Location{Segment: "PID", Occurrence: 1, Field: 3, Repetition: 2}, Severity: SeverityError, Message: "expected max 6 components for field number 3"},
+			{Location: Location{Segment: "PID", Occurrence: 1, Field: 5}, Severity: SeverityError, Message: "required field PatientName is missing"},
+			{Location: Location{Segment: "OBX", Occurrence: 1, Field: 11}, Severity: SeverityError, Message: "required field ResultStatus is missing"},
+		},
+		verr.Report.Errors(),
+	)
+}
+
+func TestValidate_RepetitionOverflow(t *testing.T) {
+	raw := []byte("MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORM^O01|1|P|2.3\r" +
+		"PID|1||123||DOE^JANE\r" +
+		"ORC|NW|125||||||||||||(555)111-1111~(555)222-2222~(555)333-3333\r")
+
+	var msg ORM_O01
+	require.NoError(t, NewDecoder(bytes.NewReader(raw)).Decode(&msg))
+
+	report := Validate(&msg)
+	require.Equal(t,
+		[]ValidationIssue{
+			{Location: Location{Segment: "ORC", Occurrence: 1, Field: 14}, Severity: SeverityError, Message: "field CallbackPhoneNumber repeats at most 2 times, got 3"},
+		},
+		report.Errors(),
+	)
+
+	dec := NewDecoder(bytes.NewReader(raw))
+	dec.Strict()
+	var verr *ValidationError
+	require.True(t, errors.As(dec.Decode(&msg), &verr))
+	require.Equal(t, report.Errors(), verr.Report.Errors())
+}
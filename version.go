@@ -0,0 +1,177 @@
+package faraday
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+Definitions change between HL7 versions: later versions add components to
+data types such as XPN, XAD and XCN, replace CE by CWE (or CNE) and TS by
+DTM, and add codes to tables. The data types of this package are those of
+v2.3, extended with the components added up to v2.8 so that messages of any
+of these versions decode into them, and the version in MSH-12 decides what is
+valid:
+
+  - a strict Decoder rejects components a data type does not have in the
+    version of the message, e.g. a 9th XPN component in v2.3, a 7th CE
+    component before v2.6 (where it is a CWE), or a second TS component as of
+    v2.6 (where it is a DTM);
+  - coded values are checked against the tables of the version (see
+    Tables.LookupVersion);
+  - Convert moves a message from one version to another.
+
+Versions without definitions of their own take those of the closest earlier
+version, e.g. 2.5.1 those of 2.5 and 2.8 those of 2.7, and messages without a
+version are taken as v2.3.
+
+The segments of this package are those of v2.3: fields added to a segment in
+later versions are not part of it, and fields whose type later versions
+changed keep their v2.3 type, e.g. CE for a field which is a CWE in v2.6,
+since it holds the components of both. Structures which differ between
+versions are registered per version with RegisterMessage; their segment
+fields may be of custom types, tagged with the standard segment name, and
+use CWE, CNE and DTM:
+
+	type PID_V27 struct {
+		SetId             SI
+		PatientIdentifier []CX `hl7:"opt=R,rep=Y"`
+		...
+		Race              []CWE `hl7:"rep=Y"`
+	}
+
+	type ADT_A01_V27 struct {
+		MSH MSH     `hl7:"opt=R"`
+		EVN EVN     `hl7:"opt=R"`
+		PID PID_V27 `hl7:"PID,opt=R"`
+		...
+	}
+
+	faraday.RegisterMessage[ADT_A01_V27]("ADT", "A01", "2.7")
+*/
+
+// versionDefinitions holds what a version changes from the previous one.
+type versionDefinitions struct {
+	version    string
+	components map[string]int           // number of components, by data type
+	tables     map[string]*ControlTable // by table id
+}
+
+// definitions lists the versions with definitions of their own, oldest
+// first. The tables of the first one are those of TableMap.
+var definitions = []versionDefinitions{
+	{
+		version: "2.3",
+		components: map[string]int{
+			"CE": 6, "CX": 6, "TS": 2, "XAD": 10, "XCN": 14, "XON": 8, "XPN": 8, "XTN": 9,
+		},
+	},
+	{
+		version:    "2.3.1",
+		components: map[string]int{"XAD": 11, "XCN": 15, "XON": 9, "XPN": 9},
+	},
+	{
+		version:    "2.4",
+		components: map[string]int{"CX": 8, "XAD": 12, "XCN": 18, "XPN": 11},
+		tables:     map[string]*ControlTable{"0003": &EventTypeV24},
+	},
+	{
+		version:    "2.5",
+		components: map[string]int{"CX": 10, "XAD": 14, "XCN": 23, "XON": 10, "XPN": 14, "XTN": 12},
+		tables: map[string]*ControlTable{
+			"0001": &AdministrativeSexV25,
+			"0004": &PatientClassesV25,
+			"0085": &ObservationResultStatusesV25,
+			"0125": &ValueTypesV25,
+		},
+	},
+	{
+		// CE is replaced by CWE and CNE, TS by DTM
+		version:    "2.6",
+		components: map[string]int{"CE": 9, "TS": 1},
+	},
+	{
+		version:    "2.7",
+		components: map[string]int{"XAD": 23, "XTN": 18},
+	},
+}
+
+// definitionsOf returns the index in definitions of the newest version no
+// later than version.
+func definitionsOf(version string) int {
+	i := 0
+	for j, defs := range definitions {
+		if compareVersions(defs.version, version) <= 0 {
+			i = j
+		}
+	}
+	return i
+}
+
+// compareVersions compares two versions such as 2.3 and 2.5.1 part by part.
+// Versions which are not numbers, e.g. 2.0D, compare by their leading digits.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x = leadingNumber(as[i])
+		}
+		if i < len(bs) {
+			y = leadingNumber(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+func leadingNumber(s string) int {
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		s = s[:end]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// componentCount returns the number of components data type t has in a
+// version.
+func componentCount(version string, t reflect.Type) int {
+	if n, ok := definedComponentCount(version, t); ok {
+		return n
+	}
+	if t.Kind() != reflect.Struct {
+		return 1
+	}
+	return t.NumField()
+}
+
+// definedComponentCount returns the number of components of data type t in a
+// version, if the versions define it.
+func definedComponentCount(version string, t reflect.Type) (int, bool) {
+	for i := definitionsOf(version); i >= 0; i-- {
+		if n, ok := definitions[i].components[t.Name()]; ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// versionTable returns the built-in table id of a version.
+func versionTable(version, id string) (*ControlTable, bool) {
+	for i := definitionsOf(version); i >= 0; i-- {
+		if table, ok := definitions[i].tables[id]; ok {
+			return table, true
+		}
+	}
+	table, ok := TableMap[id]
+	return table, ok && table != nil
+}
+
+// Version returns the version (MSH-12) of the last message read.
+func (dec *Decoder) Version() string {
+	return dec.version
+}
@@ -0,0 +1,212 @@
+package faraday
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	require.Zero(t, compareVersions("2.3", "2.3"))
+	require.Negative(t, compareVersions("2.3", "2.3.1"))
+	require.Negative(t, compareVersions("2.4", "2.10"))
+	require.Positive(t, compareVersions("2.5.1", "2.5"))
+	require.Positive(t, compareVersions("2.1", "2.0D"))
+
+	require.Equal(t, "2.3", definitions[definitionsOf("")].version)
+	require.Equal(t, "2.3", definitions[definitionsOf("2.2")].version)
+	require.Equal(t, "2.5", definitions[definitionsOf("2.5.1")].version)
+	require.Equal(t, "2.4", definitions[definitionsOf("2.4")].version)
+	require.Equal(t, "2.6", definitions[definitionsOf("2.6")].version)
+	require.Equal(t, "2.7", definitions[definitionsOf("2.8")].version)
+}
+
+func TestComponentCount(t *testing.T) {
+	for _, tt := range []struct {
+		typ      reflect.Type
+		versions map[string]int
+	}{
+		{reflect.TypeFor[CE](), map[string]int{"2.3": 6, "2.5.1": 6, "2.6": 9, "2.8": 9}},
+		{reflect.TypeFor[TS](), map[string]int{"2.3": 2, "2.5.1": 2, "2.6": 1, "2.8": 1}},
+		{reflect.TypeFor[CX](), map[string]int{"2.3": 6, "2.4": 8, "2.5.1": 10, "2.8": 10}},
+		{reflect.TypeFor[XAD](), map[string]int{"2.3": 10, "2.5.1": 14, "2.7": 23, "2.8": 23}},
+		{reflect.TypeFor[XTN](), map[string]int{"2.3": 9, "2.5.1": 12, "2.7": 18}},
+		{reflect.TypeFor[HD](), map[string]int{"2.3": 3, "2.8": 3}},
+		{reflect.TypeFor[ST](), map[string]int{"2.3": 1}},
+	} {
+		for version, want := range tt.versions {
+			require.Equal(t, want, componentCount(version, tt.typ), "%s in v%s", tt.typ.Name(), version)
+		}
+	}
+}
+
+func TestDecoder_VersionTypes(t *testing.T) {
+	raw := "MSH|^~\\&|LIS|Lab|EHR|Hosp|20250724121200||ORU^R01|1|P|%s\r" +
+		"PID|1||123^^^MRN^MR^^20200101^20301231||DOE^JANE\r" +
+		"OBR|1|||CBC^Blood count^L^^^^1.0^^CBC w/o diff|||20250724121200^S\r" +
+		"OBX|1|NM|WBC||5.4||||||F\r"
+	decode := func(version string) []string {
+		dec := NewDecoder(strings.NewReader(strings.ReplaceAll(raw, "%s", version)))
+		dec.Strict()
+		var msg ORU_R01
+		var verr *ValidationError
+		if err := dec.Decode(&msg); !errors.As(err, &verr) {
+			require.NoError(t, err)
+			return nil
+		}
+		var issues []string
+		for _, issue := range verr.Report.Errors() {
+			issues = append(issues, issue.String())
+		}
+		return issues
+	}
+
+	require.Equal(t, []string{
+		"PID[1]-3[1]: error: expected max 6 components for field number 3",
+		"OBR[1]-4[1]: error: expected max 6 components for field number 4",
+	}, decode("2.3"))
+	require.Equal(t, []string{
+		"OBR[1]-4[1]: error: expected max 6 components for field number 4",
+	}, decode("2.5.1"))
+	// CE is a CWE as of v2.6, and TS a DTM
+	require.Equal(t, []string{
+		"OBR[1]-7[1]: error: expected max 1 components for field number 7",
+	}, decode("2.6"))
+
+	var msg ORU_R01
+	require.NoError(t, NewDecoder(strings.NewReader(strings.ReplaceAll(raw, "%s", "2.6"))).Decode(&msg))
+	require.Equal(t, CE{Identifier: "CBC", Text: "Blood count", CodingSystem: "L", CodingSystemVersionId: "1.0", OriginalText: "CBC w/o diff"}, msg.Results[0].Order[0].OBR.UniversalServiceID)
+	require.Equal(t, CX{IdNumber: "123", AssigningAuthority: HD{NamespaceId: "MRN"}, IdentifierTypeCode: "MR", EffectiveDate: "20200101", ExpirationDate: "20301231"}, msg.Results[0].Patient.PID.InternalPatientId[0])
+}
+
+func TestRegisterMessage_VersionStructure(t *testing.T) {
+	type PID_V27 struct {
+		SetId              SI
+		PatientId          CX
+		PatientIdentifier  []CX  `hl7:"opt=R,rep=Y"`
+		AlternatePatientId []CX  `hl7:"rep=Y"`
+		PatientName        []XPN `hl7:"opt=R,rep=Y"`
+		MotherMaidenName   []XPN `hl7:"rep=Y"`
+		DateTimeOfBirth    DTM
+		AdministrativeSex  CWE
+		PatientAlias       []XPN `hl7:"rep=Y"`
+		Race               []CWE `hl7:"rep=Y"`
+	}
+	type ADT_A01_V27 struct {
+		MSH MSH     `hl7:"opt=R"`
+		EVN EVN     `hl7:"opt=R"`
+		PID PID_V27 `hl7:"PID,opt=R"`
+	}
+	require.NoError(t, RegisterMessage[ADT_A01_V27]("ADT", "A01", "2.7"))
+	t.Cleanup(func() {
+		messageRegistry.Lock()
+		delete(messageRegistry.types, messageKey{"ADT", "A01", "2.7"})
+		messageRegistry.Unlock()
+	})
+
+	raw := "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A01|MSG1|P|2.7\r" +
+		"EVN||20250724000000\r" +
+		"PID|1||123^^^MRN||DOE^JANE||19800101|F^Female^HL70001||2106-3^White^CDCREC^^^^1.0^^Caucasian\r"
+	v, err := NewDecoder(strings.NewReader(raw)).DecodeAny()
+	require.NoError(t, err)
+	msg, ok := v.(*ADT_A01_V27)
+	require.True(t, ok, "%T", v)
+	require.Equal(t, DTM("19800101"), msg.PID.DateTimeOfBirth)
+	require.Equal(t, []CWE{{Identifier: "2106-3", Text: "White", CodingSystem: "CDCREC", CodingSystemVersionId: "1.0", OriginalText: "Caucasian"}}, msg.PID.Race)
+}
+
+func TestDecoder_Version(t *testing.T) {
+	raw := "MSH|^~\\&|ADT|Hosp|EHR|Hosp|20250724000000||ADT^A01|MSG1|P|%s\r" +
+		"EVN|A01|20250724000000\r" +
+		"PID|1||123^^^MRN||DOE^JANE^^^^^L^A^^^G|||N\r" +
+		"PV1|1|C\r"
+
+	dec := NewDecoder(strings.NewReader(strings.ReplaceAll(raw, "%s", "2.5.1")))
+	dec.Strict()
+	var msg ADT_A01
+	require.NoError(t, dec.Decode(&msg))
+	require.Equal(t, "2.5.1", dec.Version())
+	require.Equal(t, XPN{FamilyName: "DOE", GivenName: "JANE", NameTypeCode: "L", NameRepresentationCode: "A", NameAssemblyOrder: "G"}, msg.PID.PatientName[0])
+
+	var issues []string
+	for _, issue := range Validate(msg).Issues {
+		issues = append(issues, issue.String())
+	}
+	require.Empty(t, issues)
+
+	// the same message is not valid in v2.3
+	dec = NewDecoder(strings.NewReader(strings.ReplaceAll(raw, "%s", "2.3")))
+	dec.Strict()
+	err := dec.Decode(&msg)
+	var verr *ValidationError
+	require.True(t, errors.As(err, &verr))
+	issues = nil
+	for _, issue := range verr.Report.Issues {
+		issues = append(issues, issue.String())
+	}
+	require.Equal(t, []string{
+		"PID[1]-5[1]: error: expected max 8 components for field number 5",
+		"PID[1]-8: warning: value 'N' not found in table 0001",
+		"PV1[1]-2: warning: value 'C' not found in table 0004",
+	}, issues)
+}
+
+func TestLookupMessage_Version(t *testing.T) {
+	type adt25 struct {
+		MSH MSH `hl7:"opt=R"`
+	}
+	require.NoError(t, RegisterMessage[adt25]("ADT", "A01", "2.5"))
+	t.Cleanup(func() {
+		messageRegistry.Lock()
+		delete(messageRegistry.types, messageKey{"ADT", "A01", "2.5"})
+		messageRegistry.Unlock()
+	})
+
+	for version, want := range map[string]reflect.Type{
+		"2.3":   reflect.TypeFor[ADT_A01](),
+		"2.4":   reflect.TypeFor[ADT_A01](),
+		"2.5":   reflect.TypeFor[adt25](),
+		"2.5.1": reflect.TypeFor[adt25](),
+		"2.8":   reflect.TypeFor[adt25](),
+	} {
+		got, ok := LookupMessage("ADT", "A01", version)
+		require.True(t, ok)
+		require.Equal(t, want, got, version)
+	}
+	got, _ := LookupMessage("ADT", "A04", "2.5")
+	require.Equal(t, reflect.TypeFor[ADT_A01](), got)
+}
+
+func TestDTM(t *testing.T) {
+	tm, prec, err := DTM("20250724121200.5-0500").Time()
+	require.NoError(t, err)
+	require.Equal(t, PrecisionTenthSecond, prec)
+	require.Equal(t, time.Date(2025, 7, 24, 17, 12, 0, 5e8, time.UTC), tm.UTC())
+
+	_, _, err = DTM("2025-07-24").Time()
+	require.Error(t, err)
+
+	require.Equal(t, DTM("20250724"), NewDTM(tm, PrecisionDay))
+}
+
+func TestXON(t *testing.T) {
+	for version, want := range map[string]int{"2.3": 8, "2.3.1": 9, "2.4": 9, "2.5.1": 10} {
+		require.Equal(t, want, componentCount(version, reflect.TypeFor[XON]()), version)
+	}
+
+	var xon XON
+	spec := NewFieldSpec(3, reflect.ValueOf(&xon).Elem())
+	require.NoError(t, spec.parse([]byte("Acme Hospital^L^^^^NPI&2.16.840.1.113883.4.6&ISO^NPI^^A^1234567890"), newDelimiters('|', defaultDelims), false))
+	require.Equal(t, XON{
+		OrganizationName:       "Acme Hospital",
+		TypeCode:               "L",
+		AssigningAuthority:     HD{NamespaceId: "NPI", UniversalId: "2.16.840.1.113883.4.6", UniversalIdType: "ISO"},
+		IdentifierTypeCode:     "NPI",
+		NameRepresentationCode: "A",
+		OrganizationIdentifier: "1234567890",
+	}, xon)
+}